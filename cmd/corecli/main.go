@@ -5,7 +5,12 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"taskpp/core/bind"
 )
@@ -14,6 +19,10 @@ type cmdConfig struct {
 	DBPath string
 	Pass   string
 	Init   bool
+	TZ     string
+	Device string
+	Folder string
+	Server string
 }
 
 func main() {
@@ -27,6 +36,10 @@ func main() {
 	rootFlags.StringVar(&cfg.DBPath, "db", "taskpp.db", "path to sqlite db")
 	rootFlags.StringVar(&cfg.Pass, "pass", "", "passphrase (auto-unlock)")
 	rootFlags.BoolVar(&cfg.Init, "init", false, "initialize keys if needed")
+	rootFlags.StringVar(&cfg.TZ, "tz", "", "device time zone (IANA), defaults to the system zone")
+	rootFlags.StringVar(&cfg.Folder, "folder", "", "shared folder to sync through")
+	rootFlags.StringVar(&cfg.Device, "device", "", "device name for the registry, defaults to the host name")
+	rootFlags.StringVar(&cfg.Server, "server", "", "sync server URL for account commands")
 	_ = rootFlags.Parse(os.Args[1:])
 
 	args := rootFlags.Args()
@@ -61,22 +74,82 @@ func main() {
 		cmdAdd(core, args[1:])
 	case "list":
 		cmdList(core, args[1:])
+	case "tree":
+		cmdTree(core, args[1:])
+	case "board":
+		cmdBoard(core, args[1:])
+	case "move":
+		cmdMove(core, args[1:])
+	case "calendar":
+		cmdCalendar(core, args[1:])
+	case "reschedule":
+		cmdReschedule(core, args[1:])
 	case "update":
 		cmdUpdate(core, args[1:])
 	case "done":
 		cmdDone(core, args[1:])
 	case "due":
 		cmdDue(core, args[1:])
+	case "snooze":
+		cmdSnooze(core, args[1:])
 	case "reorder":
 		cmdReorder(core, args[1:])
+	case "place":
+		cmdPlace(core, args[1:])
+	case "undo":
+		printJSON(core.Undo())
+	case "history":
+		cmdHistory(core, args[1:])
+	case "redo":
+		printJSON(core.Redo())
 	case "export":
 		cmdExport(core, args[1:])
 	case "import":
 		cmdImport(core, args[1:])
 	case "decrypt-event":
 		cmdDecryptEvent(core, args[1:])
+	case "sync":
+		printJSON(core.Sync())
+	case "compact":
+		printJSON(core.Compact())
+	case "snapshot":
+		cmdSnapshot(core, args[1:])
+	case "cursor":
+		printJSON(core.SyncCursor())
+	case "ack":
+		cmdAck(core, args[1:])
+	case "device":
+		cmdDevice(core, args[1:])
+	case "lan":
+		cmdLAN(core, args[1:])
+	case "pair":
+		cmdPair(core, args[1:])
+	case "account":
+		cmdAccount(core, args[1:])
 	case "delete":
 		cmdDelete(core, args[1:])
+	case "project":
+		cmdProject(core, args[1:])
+	case "tag":
+		cmdTag(core, args[1:])
+	case "reminders":
+		cmdReminders(core, args[1:])
+	case "depend":
+		cmdDepend(core, args[1:])
+	case "checklist":
+		cmdChecklist(core, args[1:])
+	case "comment":
+		cmdComment(core, args[1:])
+	case "attach":
+		cmdAttach(core, args[1:])
+	case "link":
+		cmdLink(core, args[1:])
+	case "time":
+		cmdTime(core, args[1:])
+	case "workflow":
+		cmdWorkflow(core, args[1:])
+	case "report":
+		cmdReport(core, args[1:])
 	default:
 		printUsage()
 		os.Exit(2)
@@ -87,6 +160,13 @@ func newCore(cfg cmdConfig) *bind.Core {
 	config := bind.Config{
 		StorageDriver: "sqlite",
 		StoragePath:   "file:" + cfg.DBPath,
+		TimeZone:      cfg.TZ,
+		DeviceName:    cfg.Device,
+		ServerURL:     cfg.Server,
+	}
+	if cfg.Folder != "" {
+		config.SyncTransport = "folder"
+		config.SyncFolder = cfg.Folder
 	}
 	data, _ := json.Marshal(config)
 	return bind.NewCore(string(data))
@@ -97,7 +177,17 @@ func cmdAdd(core *bind.Core, args []string) {
 	title := fs.String("title", "", "task title")
 	desc := fs.String("desc", "", "task description")
 	priority := fs.String("priority", "", "low|med|high")
-	due := fs.String("due", "", "YYYY-MM-DD")
+	due := fs.String("due", "", "YYYY-MM-DD|today|tomorrow")
+	start := fs.String("start", "", "defer until YYYY-MM-DD|tomorrow|next_week")
+	dueTime := fs.String("time", "", "HH:MM")
+	zone := fs.String("zone", "", "IANA time zone for -time (default: floating)")
+	project := fs.String("project", "", "project id")
+	parent := fs.String("parent", "", "parent task id")
+	order := fs.Int64("order", 0, "order among siblings")
+	tags := fs.String("tags", "", "comma-separated tags")
+	repeat := fs.String("repeat", "", "daily|eod|RRULE:...")
+	blockedBy := fs.String("blocked-by", "", "comma-separated ids of tasks that must finish first")
+	estimate := fs.Int64("estimate", 0, "estimated effort in minutes")
 	_ = fs.Parse(args)
 
 	if strings.TrimSpace(*title) == "" {
@@ -109,6 +199,17 @@ func cmdAdd(core *bind.Core, args []string) {
 		Description: *desc,
 		Priority:    *priority,
 		DueDate:     *due,
+		StartDate:   *start,
+		DueTime:     *dueTime,
+		TimeZone:    *zone,
+		ProjectID:   *project,
+		ParentID:    *parent,
+		Order:       *order,
+		Tags:        splitList(*tags),
+		Recurrence:  *repeat,
+		BlockedBy:   splitList(*blockedBy),
+
+		EstimateMinutes: *estimate,
 	}
 	payload, _ := json.Marshal(dto)
 	result := core.CreateTask(string(payload))
@@ -141,7 +242,16 @@ func cmdList(core *bind.Core, args []string) {
 	fs := flag.NewFlagSet("list", flag.ExitOnError)
 	status := fs.String("status", "", "active|done")
 	archived := fs.String("archived", "", "true|false")
-	due := fs.String("due", "", "YYYY-MM-DD")
+	due := fs.String("due", "", "YYYY-MM-DD|today|tomorrow")
+	dueFrom := fs.String("due-from", "", "YYYY-MM-DD|today")
+	dueTo := fs.String("due-to", "", "YYYY-MM-DD|today")
+	project := fs.String("project", "", "project id, or \"none\" for tasks without a project")
+	tagsAny := fs.String("tags-any", "", "comma-separated tags, match any")
+	tagsAll := fs.String("tags-all", "", "comma-separated tags, match all")
+	tagsNone := fs.String("tags-none", "", "comma-separated tags, match none")
+	deferred := fs.Bool("deferred", false, "include tasks whose start date is still ahead")
+	blocked := fs.String("blocked", "", "true|false")
+	state := fs.String("state", "", "workflow state id")
 	_ = fs.Parse(args)
 
 	var archivedPtr *bool
@@ -149,17 +259,109 @@ func cmdList(core *bind.Core, args []string) {
 		value := *archived == "true"
 		archivedPtr = &value
 	}
+	var blockedPtr *bool
+	if *blocked != "" {
+		value := *blocked == "true"
+		blockedPtr = &value
+	}
+	var projectPtr *string
+	if *project != "" {
+		value := *project
+		if value == "none" {
+			value = ""
+		}
+		projectPtr = &value
+	}
 
 	filter := bind.TaskFilterDTO{
-		Status:   *status,
-		Archived: archivedPtr,
-		DueDate:  *due,
+		Status:          *status,
+		Archived:        archivedPtr,
+		DueDate:         *due,
+		DueFrom:         *dueFrom,
+		DueTo:           *dueTo,
+		ProjectID:       projectPtr,
+		TagsAny:         splitList(*tagsAny),
+		TagsAll:         splitList(*tagsAll),
+		TagsNone:        splitList(*tagsNone),
+		IncludeDeferred: *deferred,
+		Blocked:         blockedPtr,
+		State:           *state,
 	}
 	payload, _ := json.Marshal(filter)
 	result := core.ListTasks(string(payload))
 	printJSON(result)
 }
 
+func cmdTree(core *bind.Core, args []string) {
+	fs := flag.NewFlagSet("tree", flag.ExitOnError)
+	status := fs.String("status", "", "active|done")
+	project := fs.String("project", "", "project id")
+	_ = fs.Parse(args)
+
+	filter := bind.TaskFilterDTO{Status: *status}
+	if *project != "" {
+		filter.ProjectID = project
+	}
+	payload, _ := json.Marshal(filter)
+	result := core.ListTaskTree(string(payload))
+	printJSON(result)
+}
+
+func cmdBoard(core *bind.Core, args []string) {
+	fs := flag.NewFlagSet("board", flag.ExitOnError)
+	by := fs.String("by", "status", "status|priority|project|tag|due")
+	status := fs.String("status", "", "active|done")
+	project := fs.String("project", "", "project id")
+	_ = fs.Parse(args)
+
+	filter := bind.TaskFilterDTO{Status: *status}
+	if *project != "" {
+		filter.ProjectID = project
+	}
+	payload, _ := json.Marshal(filter)
+	printJSON(core.ListTasksGrouped(string(payload), *by))
+}
+
+func cmdMove(core *bind.Core, args []string) {
+	if len(args) < 2 {
+		fatal("usage: move <task-id> <group:key> [position]")
+	}
+	var position int64
+	if len(args) > 2 {
+		parsed, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			fatal("position must be a number")
+		}
+		position = parsed
+	}
+	printJSON(core.MoveTask(args[0], args[1], position))
+}
+
+func cmdCalendar(core *bind.Core, args []string) {
+	fs := flag.NewFlagSet("calendar", flag.ExitOnError)
+	from := fs.String("from", "today", "first day YYYY-MM-DD")
+	to := fs.String("to", "", "last day YYYY-MM-DD (default: a week from -from)")
+	zone := fs.String("zone", "", "IANA time zone")
+	_ = fs.Parse(args)
+
+	end := *to
+	if end == "" {
+		end = "next_week"
+	}
+	printJSON(core.CalendarRange(*from, end, *zone))
+}
+
+func cmdReschedule(core *bind.Core, args []string) {
+	if len(args) < 2 {
+		fatal("usage: reschedule <task-id|task-id@date> <date> [HH:MM|none]")
+	}
+	dueTime := ""
+	if len(args) > 2 {
+		dueTime = args[2]
+	}
+	printJSON(core.MoveToDate(args[0], args[1], dueTime))
+}
+
 func cmdUpdate(core *bind.Core, args []string) {
 	fs := flag.NewFlagSet("update", flag.ExitOnError)
 	id := fs.String("id", "", "task id")
@@ -168,7 +370,13 @@ func cmdUpdate(core *bind.Core, args []string) {
 	status := fs.String("status", "", "active|done")
 	priority := fs.String("priority", "", "low|med|high")
 	due := fs.String("due", "", "YYYY-MM-DD")
+	start := fs.String("start", "", "defer until YYYY-MM-DD, or \"none\" to clear")
 	archived := fs.String("archived", "", "true|false")
+	project := fs.String("project", "", "project id, or \"none\" to clear")
+	parent := fs.String("parent", "", "parent task id, or \"none\" to make it a root")
+	repeat := fs.String("repeat", "", "daily|eod|RRULE:..., or \"none\" to stop repeating")
+	estimate := fs.Int64("estimate", -1, "estimated effort in minutes, 0 to clear")
+	state := fs.String("state", "", "workflow state id")
 	_ = fs.Parse(args)
 
 	if strings.TrimSpace(*id) == "" {
@@ -188,15 +396,39 @@ func cmdUpdate(core *bind.Core, args []string) {
 	if *status != "" {
 		dto.Status = *status
 	}
+	if *state != "" {
+		dto.State = *state
+	}
 	if *priority != "" {
 		dto.Priority = *priority
 	}
 	if *due != "" {
 		dto.DueDate = *due
 	}
+	if *start == "none" {
+		dto.StartDate = ""
+	} else if *start != "" {
+		dto.StartDate = *start
+	}
 	if *archived != "" {
 		dto.Archived = *archived == "true"
 	}
+	if *project == "none" {
+		dto.ProjectID = ""
+	} else if *project != "" {
+		dto.ProjectID = *project
+	}
+	if *parent == "none" {
+		dto.ParentID = ""
+	} else if *parent != "" {
+		dto.ParentID = *parent
+	}
+	if *repeat != "" {
+		dto.Recurrence = *repeat
+	}
+	if *estimate >= 0 {
+		dto.EstimateMinutes = *estimate
+	}
 	payload, _ := json.Marshal(dto)
 	result := core.UpdateTask(string(payload))
 	printJSON(result)
@@ -212,10 +444,28 @@ func cmdDone(core *bind.Core, args []string) {
 
 func cmdDue(core *bind.Core, args []string) {
 	if len(args) < 2 {
-		fatal("usage: due <task-id> <YYYY-MM-DD>")
+		fatal("usage: due <task-id> <YYYY-MM-DD> [HH:MM [zone]]")
 	}
-	result := core.SetDueDate(args[0], args[1])
-	printJSON(result)
+	if len(args) == 2 {
+		printJSON(core.SetDueDate(args[0], args[1]))
+		return
+	}
+	zone := ""
+	if len(args) >= 4 {
+		zone = args[3]
+	}
+	printJSON(core.SetDueDateTime(args[0], args[1], args[2], zone))
+}
+
+func cmdSnooze(core *bind.Core, args []string) {
+	if len(args) < 1 {
+		fatal("usage: snooze <task-id> [YYYY-MM-DD|tomorrow|next_week]")
+	}
+	until := "tomorrow"
+	if len(args) >= 2 {
+		until = args[1]
+	}
+	printJSON(core.SnoozeTask(args[0], until))
 }
 
 func cmdReorder(core *bind.Core, args []string) {
@@ -252,6 +502,29 @@ func cmdReorder(core *bind.Core, args []string) {
 	printJSON(result)
 }
 
+func cmdPlace(core *bind.Core, args []string) {
+	fs := flag.NewFlagSet("place", flag.ExitOnError)
+	after := fs.String("after", "", "task id to place below (empty for the top)")
+	before := fs.String("before", "", "task id to place above (empty for the bottom)")
+	_ = fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fatal("usage: place [-after <id>] [-before <id>] <task-id>")
+	}
+	printJSON(core.MoveTaskBetween(fs.Arg(0), *after, *before))
+}
+
+func cmdHistory(core *bind.Core, args []string) {
+	if len(args) == 3 && args[0] == "restore" {
+		printJSON(core.RestoreTaskVersion(args[1], args[2]))
+		return
+	}
+	if len(args) != 1 {
+		fatal("usage: history <task-id> | history restore <task-id> <event-id>")
+	}
+	printJSON(core.TaskHistory(args[0]))
+}
+
 func cmdExport(core *bind.Core, args []string) {
 	fs := flag.NewFlagSet("export", flag.ExitOnError)
 	since := fs.Int64("since", 0, "last seq")
@@ -282,6 +555,162 @@ func cmdDecryptEvent(core *bind.Core, args []string) {
 	printJSON(result)
 }
 
+func cmdSnapshot(core *bind.Core, args []string) {
+	if len(args) == 1 && args[0] == "export" {
+		printJSON(core.ExportSnapshot())
+		return
+	}
+	if len(args) != 2 || args[0] != "import" {
+		fatal("usage: snapshot export | snapshot import <file>")
+	}
+	data, err := os.ReadFile(args[1])
+	if err != nil {
+		fatal(fmt.Sprintf("read snapshot: %v", err))
+	}
+	printJSON(core.ImportSnapshot(string(data)))
+}
+
+func cmdAck(core *bind.Core, args []string) {
+	if len(args) == 0 {
+		var state bind.SyncStateDTO
+		if err := json.Unmarshal([]byte(core.GetSyncState()), &state); err != nil {
+			fatal(fmt.Sprintf("decode sync state: %v", err))
+		}
+		printJSON(core.AckEvents(state.DeviceID, core.SyncCursor()))
+		return
+	}
+	if len(args) != 2 {
+		fatal("usage: ack [<device-id> <cursor-json>]")
+	}
+	printJSON(core.AckEvents(args[0], args[1]))
+}
+
+func cmdDevice(core *bind.Core, args []string) {
+	if len(args) < 1 {
+		fatal("usage: device list|rename|revoke [args]")
+	}
+	switch args[0] {
+	case "list":
+		printJSON(core.ListDevices())
+	case "rename":
+		if len(args) < 3 {
+			fatal("usage: device rename <device-id> <name>")
+		}
+		printJSON(core.RenameDevice(args[1], strings.Join(args[2:], " ")))
+	case "revoke":
+		if len(args) != 2 {
+			fatal("usage: device revoke <device-id>")
+		}
+		printJSON(core.RevokeDevice(args[1]))
+	default:
+		fatal("usage: device list|rename|revoke [args]")
+	}
+}
+
+func cmdLAN(core *bind.Core, args []string) {
+	if len(args) < 1 {
+		fatal("usage: lan serve|discover|sync [args]")
+	}
+	switch args[0] {
+	case "serve":
+		fs := flag.NewFlagSet("lan serve", flag.ExitOnError)
+		listen := fs.String("listen", ":0", "address to accept sessions on")
+		_ = fs.Parse(args[1:])
+		status := core.StartLANSync(*listen)
+		var started bind.LANStatusDTO
+		if err := json.Unmarshal([]byte(status), &started); err != nil || started.Address == "" {
+			fatal(status)
+		}
+		printJSON(status)
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt)
+		<-stop
+		printJSON(core.StopLANSync())
+	case "discover":
+		fs := flag.NewFlagSet("lan discover", flag.ExitOnError)
+		timeout := fs.Int64("timeout", 2000, "milliseconds to wait for answers")
+		_ = fs.Parse(args[1:])
+		printJSON(core.DiscoverPeers(*timeout))
+	case "sync":
+		if len(args) != 2 {
+			fatal("usage: lan sync <address>")
+		}
+		printJSON(core.SyncWithPeer(args[1]))
+	default:
+		fatal("usage: lan serve|discover|sync [args]")
+	}
+}
+
+func cmdPair(core *bind.Core, args []string) {
+	if len(args) < 1 {
+		fatal("usage: pair start | pair join <payload>|<address> <code>")
+	}
+	switch args[0] {
+	case "start":
+		offer := core.StartPairing()
+		var started bind.PairingDTO
+		if err := json.Unmarshal([]byte(offer), &started); err != nil || started.Payload == "" {
+			fatal(offer)
+		}
+		printJSON(offer)
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt)
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				printJSON(core.CancelPairing())
+				return
+			case <-ticker.C:
+				status := core.PairingStatus()
+				var current bind.PairingStatusDTO
+				if err := json.Unmarshal([]byte(status), &current); err != nil || current.State != "waiting" {
+					printJSON(status)
+					return
+				}
+			}
+		}
+	case "join":
+		if len(args) < 2 || len(args) > 3 {
+			fatal("usage: pair join <payload>|<address> <code>")
+		}
+		printJSON(core.CompletePairing(strings.Join(args[1:], " ")))
+	default:
+		fatal("usage: pair start | pair join <payload>|<address> <code>")
+	}
+}
+
+func cmdAccount(core *bind.Core, args []string) {
+	if len(args) < 1 {
+		fatal("usage: account signup|login|logout|status|recovery-key|reset [args]")
+	}
+	switch args[0] {
+	case "signup", "login":
+		if len(args) < 3 {
+			fatal("usage: account " + args[0] + " <username> <password>")
+		}
+		if args[0] == "signup" {
+			printJSON(core.Signup(args[1], args[2]))
+			return
+		}
+		printJSON(core.Login(args[1], args[2]))
+	case "logout":
+		printJSON(core.Logout())
+	case "status":
+		printJSON(core.AccountStatus())
+	case "recovery-key":
+		printJSON(core.CreateRecoveryKey())
+	case "reset":
+		if len(args) < 4 {
+			fatal("usage: account reset <username> <recovery-key> <new-password>")
+		}
+		printJSON(core.ResetPassword(args[1], args[2], args[3]))
+	default:
+		fatal("usage: account signup|login|logout|status|recovery-key|reset [args]")
+	}
+}
+
 func cmdDelete(core *bind.Core, args []string) {
 	if len(args) < 1 {
 		fatal("usage: delete <task-id>")
@@ -290,6 +719,393 @@ func cmdDelete(core *bind.Core, args []string) {
 	printJSON(result)
 }
 
+func cmdProject(core *bind.Core, args []string) {
+	if len(args) < 1 {
+		fatal("usage: project add|list|rename|update|delete [args]")
+	}
+	switch args[0] {
+	case "add":
+		fs := flag.NewFlagSet("project add", flag.ExitOnError)
+		name := fs.String("name", "", "project name")
+		color := fs.String("color", "", "#RRGGBB")
+		order := fs.Int64("order", 0, "sort order")
+		_ = fs.Parse(args[1:])
+		if strings.TrimSpace(*name) == "" {
+			fatal("name is required")
+		}
+		payload, _ := json.Marshal(bind.ProjectDTO{Name: *name, Color: *color, Order: *order})
+		printJSON(core.CreateProject(string(payload)))
+	case "list":
+		fs := flag.NewFlagSet("project list", flag.ExitOnError)
+		all := fs.Bool("all", false, "include archived projects")
+		_ = fs.Parse(args[1:])
+		printJSON(core.ListProjects(*all))
+	case "rename":
+		if len(args) < 3 {
+			fatal("usage: project rename <project-id> <name>")
+		}
+		printJSON(core.RenameProject(args[1], args[2]))
+	case "update":
+		fs := flag.NewFlagSet("project update", flag.ExitOnError)
+		id := fs.String("id", "", "project id")
+		name := fs.String("name", "", "project name")
+		color := fs.String("color", "", "#RRGGBB")
+		order := fs.String("order", "", "sort order")
+		archived := fs.String("archived", "", "true|false")
+		_ = fs.Parse(args[1:])
+		if strings.TrimSpace(*id) == "" {
+			fatal("id is required")
+		}
+		dto, err := loadProject(core, *id)
+		if err != nil {
+			fatal(err.Error())
+		}
+		if *name != "" {
+			dto.Name = *name
+		}
+		if *color != "" {
+			dto.Color = *color
+		}
+		if *order != "" {
+			value, err := parseInt64(*order)
+			if err != nil {
+				fatal("invalid order")
+			}
+			dto.Order = value
+		}
+		if *archived != "" {
+			dto.Archived = *archived == "true"
+		}
+		payload, _ := json.Marshal(dto)
+		printJSON(core.UpdateProject(string(payload)))
+	case "delete":
+		fs := flag.NewFlagSet("project delete", flag.ExitOnError)
+		policy := fs.String("tasks", "unassign", "unassign|delete")
+		_ = fs.Parse(args[1:])
+		if fs.NArg() < 1 {
+			fatal("usage: project delete [-tasks unassign|delete] <project-id>")
+		}
+		printJSON(core.DeleteProject(fs.Arg(0), *policy))
+	default:
+		fatal("usage: project add|list|rename|update|delete [args]")
+	}
+}
+
+func cmdTag(core *bind.Core, args []string) {
+	if len(args) < 1 {
+		fatal("usage: tag add|remove|list|rename|merge [args]")
+	}
+	switch args[0] {
+	case "add":
+		if len(args) < 3 {
+			fatal("usage: tag add <task-id> <tag>")
+		}
+		printJSON(core.AddTag(args[1], args[2]))
+	case "remove":
+		if len(args) < 3 {
+			fatal("usage: tag remove <task-id> <tag>")
+		}
+		printJSON(core.RemoveTag(args[1], args[2]))
+	case "list":
+		printJSON(core.ListTags())
+	case "rename":
+		if len(args) < 3 {
+			fatal("usage: tag rename <from> <to>")
+		}
+		printJSON(core.RenameTag(args[1], args[2]))
+	case "merge":
+		fs := flag.NewFlagSet("tag merge", flag.ExitOnError)
+		into := fs.String("into", "", "target tag")
+		_ = fs.Parse(args[1:])
+		if strings.TrimSpace(*into) == "" || fs.NArg() < 1 {
+			fatal("usage: tag merge -into <tag> <tag,tag>")
+		}
+		payload, _ := json.Marshal(splitList(fs.Arg(0)))
+		printJSON(core.MergeTags(string(payload), *into))
+	default:
+		fatal("usage: tag add|remove|list|rename|merge [args]")
+	}
+}
+
+func cmdDepend(core *bind.Core, args []string) {
+	if len(args) < 3 {
+		fatal("usage: depend add|remove <task-id> <blocker-id>")
+	}
+	switch args[0] {
+	case "add":
+		printJSON(core.AddDependency(args[1], args[2]))
+	case "remove":
+		printJSON(core.RemoveDependency(args[1], args[2]))
+	default:
+		fatal("usage: depend add|remove <task-id> <blocker-id>")
+	}
+}
+
+func cmdChecklist(core *bind.Core, args []string) {
+	if len(args) < 3 {
+		fatal("usage: checklist add|toggle|remove|reorder <task-id> <text|item-id|id,id>")
+	}
+	switch args[0] {
+	case "add":
+		printJSON(core.AddChecklistItem(args[1], strings.Join(args[2:], " ")))
+	case "toggle":
+		printJSON(core.ToggleChecklistItem(args[1], args[2]))
+	case "remove":
+		printJSON(core.RemoveChecklistItem(args[1], args[2]))
+	case "reorder":
+		payload, _ := json.Marshal(splitList(args[2]))
+		printJSON(core.ReorderChecklist(args[1], string(payload)))
+	default:
+		fatal("usage: checklist add|toggle|remove|reorder <task-id> <text|item-id|id,id>")
+	}
+}
+
+func cmdComment(core *bind.Core, args []string) {
+	if len(args) < 2 {
+		fatal("usage: comment add|list|edit|delete [args]")
+	}
+	switch args[0] {
+	case "add":
+		if len(args) < 3 {
+			fatal("usage: comment add <task-id> <text>")
+		}
+		printJSON(core.AddComment(args[1], strings.Join(args[2:], " ")))
+	case "list":
+		printJSON(core.ListComments(args[1]))
+	case "edit":
+		if len(args) < 3 {
+			fatal("usage: comment edit <comment-id> <text>")
+		}
+		printJSON(core.EditComment(args[1], strings.Join(args[2:], " ")))
+	case "delete":
+		printJSON(core.DeleteComment(args[1]))
+	default:
+		fatal("usage: comment add|list|edit|delete [args]")
+	}
+}
+
+func cmdAttach(core *bind.Core, args []string) {
+	if len(args) < 1 {
+		fatal("usage: attach add|export|remove|missing|gc [args]")
+	}
+	switch args[0] {
+	case "add":
+		if len(args) < 3 {
+			fatal("usage: attach add <task-id> <path>")
+		}
+		printJSON(core.AddAttachment(args[1], args[2]))
+	case "export":
+		if len(args) < 4 {
+			fatal("usage: attach export <task-id> <attachment-id> <dest-path>")
+		}
+		printJSON(core.ExportAttachment(args[1], args[2], args[3]))
+	case "remove":
+		if len(args) < 3 {
+			fatal("usage: attach remove <task-id> <attachment-id>")
+		}
+		printJSON(core.RemoveAttachment(args[1], args[2]))
+	case "missing":
+		printJSON(core.MissingBlobs())
+	case "gc":
+		printJSON(core.CollectGarbage())
+	default:
+		fatal("usage: attach add|export|remove|missing|gc [args]")
+	}
+}
+
+func cmdLink(core *bind.Core, args []string) {
+	if len(args) < 1 {
+		fatal("usage: link add|remove|list|extract [args]")
+	}
+	switch args[0] {
+	case "add":
+		fs := flag.NewFlagSet("link add", flag.ExitOnError)
+		label := fs.String("label", "", "display label")
+		_ = fs.Parse(args[1:])
+		if fs.NArg() < 3 {
+			fatal("usage: link add [-label <label>] <task-id> url|tel|mailto|file <value>")
+		}
+		payload, _ := json.Marshal(bind.LinkDTO{Type: fs.Arg(1), Label: *label, Value: fs.Arg(2)})
+		printJSON(core.AddLink(fs.Arg(0), string(payload)))
+	case "remove":
+		if len(args) < 3 {
+			fatal("usage: link remove <task-id> <value>")
+		}
+		printJSON(core.RemoveLink(args[1], args[2]))
+	case "list":
+		if len(args) < 2 {
+			fatal("usage: link list <task-id>")
+		}
+		printJSON(core.TaskLinks(args[1]))
+	case "extract":
+		if len(args) < 2 {
+			fatal("usage: link extract <text>")
+		}
+		printJSON(core.ExtractLinks(strings.Join(args[1:], " ")))
+	default:
+		fatal("usage: link add|remove|list|extract [args]")
+	}
+}
+
+func cmdWorkflow(core *bind.Core, args []string) {
+	if len(args) < 1 {
+		fatal("usage: workflow show|set|move [args]")
+	}
+	switch args[0] {
+	case "show":
+		printJSON(core.GetWorkflow())
+	case "set":
+		if len(args) < 2 {
+			fatal("usage: workflow set <workflow-json>")
+		}
+		printJSON(core.SetWorkflow(args[1]))
+	case "move":
+		if len(args) < 3 {
+			fatal("usage: workflow move <task-id> <state>")
+		}
+		printJSON(core.TransitionTask(args[1], args[2]))
+	default:
+		fatal("usage: workflow show|set|move [args]")
+	}
+}
+
+func cmdTime(core *bind.Core, args []string) {
+	if len(args) < 1 {
+		fatal("usage: time start|stop|status|list|add|delete [args]")
+	}
+	switch args[0] {
+	case "start":
+		if len(args) < 2 {
+			fatal("usage: time start <task-id>")
+		}
+		printJSON(core.StartTimer(args[1]))
+	case "stop":
+		printJSON(core.StopTimer())
+	case "status":
+		printJSON(core.RunningTimer())
+	case "list":
+		taskID := ""
+		if len(args) > 1 {
+			taskID = args[1]
+		}
+		printJSON(core.ListTimeEntries(taskID))
+	case "add":
+		fs := flag.NewFlagSet("time add", flag.ExitOnError)
+		start := fs.String("start", "", "start time (RFC3339)")
+		end := fs.String("end", "", "end time (RFC3339)")
+		note := fs.String("note", "", "note")
+		_ = fs.Parse(args[1:])
+		if fs.NArg() < 1 {
+			fatal("usage: time add -start <time> -end <time> [-note <text>] <task-id>")
+		}
+		payload, _ := json.Marshal(bind.TimeEntryDTO{TaskID: fs.Arg(0), Start: *start, End: *end, Note: *note})
+		printJSON(core.AddTimeEntry(string(payload)))
+	case "delete":
+		if len(args) < 2 {
+			fatal("usage: time delete <entry-id>")
+		}
+		printJSON(core.DeleteTimeEntry(args[1]))
+	default:
+		fatal("usage: time start|stop|status|list|add|delete [args]")
+	}
+}
+
+func cmdReport(core *bind.Core, args []string) {
+	if len(args) < 1 || args[0] != "time" {
+		fatal("usage: report time [-from YYYY-MM-DD] [-to YYYY-MM-DD] [-by task|project|tag]")
+	}
+	fs := flag.NewFlagSet("report time", flag.ExitOnError)
+	from := fs.String("from", "", "first day, YYYY-MM-DD|today")
+	to := fs.String("to", "", "last day, YYYY-MM-DD|today")
+	by := fs.String("by", "task", "task|project|tag")
+	_ = fs.Parse(args[1:])
+	printJSON(core.TimeReport(*from, *to, *by))
+}
+
+func cmdReminders(core *bind.Core, args []string) {
+	if len(args) < 1 {
+		fatal("usage: reminders add|remove|ack|snooze|pending|watch [args]")
+	}
+	switch args[0] {
+	case "add":
+		fs := flag.NewFlagSet("reminders add", flag.ExitOnError)
+		at := fs.String("at", "", "absolute time (RFC3339)")
+		before := fs.Int64("before", 0, "minutes before the due time")
+		_ = fs.Parse(args[1:])
+		if fs.NArg() < 1 {
+			fatal("usage: reminders add [-at <time>|-before <minutes>] <task-id>")
+		}
+		payload, _ := json.Marshal(bind.ReminderDTO{At: *at, OffsetMinutes: *before})
+		printJSON(core.AddReminder(fs.Arg(0), string(payload)))
+	case "remove":
+		if len(args) < 3 {
+			fatal("usage: reminders remove <task-id> <reminder-id>")
+		}
+		printJSON(core.RemoveReminder(args[1], args[2]))
+	case "ack":
+		if len(args) < 3 {
+			fatal("usage: reminders ack <task-id> <reminder-id>")
+		}
+		printJSON(core.AcknowledgeReminder(args[1], args[2]))
+	case "snooze":
+		fs := flag.NewFlagSet("reminders snooze", flag.ExitOnError)
+		minutes := fs.Int64("minutes", 10, "minutes to snooze")
+		_ = fs.Parse(args[1:])
+		if fs.NArg() < 2 {
+			fatal("usage: reminders snooze [-minutes <n>] <task-id> <reminder-id>")
+		}
+		printJSON(core.SnoozeReminder(fs.Arg(0), fs.Arg(1), *minutes))
+	case "pending":
+		printJSON(core.PendingReminders(""))
+	case "watch":
+		fs := flag.NewFlagSet("reminders watch", flag.ExitOnError)
+		interval := fs.Duration("interval", 30*time.Second, "poll interval")
+		command := fs.String("exec", "", "shell command to run per reminder (TASKPP_TASK_ID, TASKPP_REMINDER_ID, TASKPP_TITLE set)")
+		_ = fs.Parse(args[1:])
+		watchReminders(core, *interval, *command)
+	default:
+		fatal("usage: reminders add|remove|ack|snooze|pending|watch [args]")
+	}
+}
+
+// watchReminders polls for due reminders, announces each one and
+// acknowledges it so it fires once.
+func watchReminders(core *bind.Core, interval time.Duration, command string) {
+	for {
+		var firings []bind.FiringDTO
+		result := core.PendingReminders("")
+		if err := json.Unmarshal([]byte(result), &firings); err != nil {
+			fatal(result)
+		}
+		for _, firing := range firings {
+			if command == "" {
+				fmt.Printf("%s\t%s\t%s\n", firing.FireAt, firing.TaskID, firing.Title)
+			} else if err := runReminderCommand(command, firing); err != nil {
+				fmt.Fprintf(os.Stderr, "reminder command: %v\n", err)
+			}
+			if errStr := core.AcknowledgeReminder(firing.TaskID, firing.ReminderID); errStr != "" {
+				fmt.Fprintln(os.Stderr, errStr)
+			}
+		}
+		time.Sleep(interval)
+	}
+}
+
+func runReminderCommand(command string, firing bind.FiringDTO) error {
+	cmd := exec.Command("sh", "-c", command)
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	}
+	cmd.Env = append(os.Environ(),
+		"TASKPP_TASK_ID="+firing.TaskID,
+		"TASKPP_REMINDER_ID="+firing.ReminderID,
+		"TASKPP_TITLE="+firing.Title,
+	)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
 func printJSON(payload string) {
 	if payload == "" {
 		fmt.Println("ok")
@@ -314,20 +1130,86 @@ func fatal(message string) {
 }
 
 func printUsage() {
-	fmt.Println("corecli -db <path> [-pass <passphrase>] [-init] <command> [args]")
+	fmt.Println("corecli -db <path> [-pass <passphrase>] [-init] [-tz <zone>] [-device <name>] [-folder <dir>] [-server <url>] <command> [args]")
 	fmt.Println("commands:")
 	fmt.Println("  init-keys -pass <passphrase>")
 	fmt.Println("  unlock-keys -pass <passphrase>")
-	fmt.Println("  add    -title <t> [-desc <d>] [-priority low|med|high] [-due YYYY-MM-DD]")
-	fmt.Println("  list   [-status active|done] [-archived true|false] [-due YYYY-MM-DD]")
-	fmt.Println("  update -id <id> [-title <t>] [-desc <d>] [-status active|done] [-priority low|med|high] [-due YYYY-MM-DD] [-archived true|false]")
+	fmt.Println("  add    -title <t> [-desc <d>] [-priority low|med|high] [-due YYYY-MM-DD|today] [-time HH:MM] [-zone <tz>] [-project <id>] [-parent <id>] [-order <n>] [-tags a,b] [-repeat daily|eod|RRULE:...] [-start YYYY-MM-DD|next_week] [-blocked-by id,id] [-estimate <minutes>]")
+	fmt.Println("  list   [-status active|done] [-archived true|false] [-due YYYY-MM-DD] [-due-from d] [-due-to d] [-project <id>|none] [-tags-any a,b] [-tags-all a,b] [-tags-none a,b] [-deferred] [-blocked true|false] [-state <state>]")
+	fmt.Println("  tree   [-status active|done] [-project <id>]")
+	fmt.Println("  board  [-by status|priority|project|tag|due] [-status active|done] [-project <id>]")
+	fmt.Println("  move   <task-id> <group:key> [position]")
+	fmt.Println("  calendar [-from YYYY-MM-DD] [-to YYYY-MM-DD] [-zone <iana>]")
+	fmt.Println("  reschedule <task-id|task-id@date> <date> [HH:MM|none]")
+	fmt.Println("  update -id <id> [-title <t>] [-desc <d>] [-status active|done] [-state <state>] [-priority low|med|high] [-due YYYY-MM-DD] [-start YYYY-MM-DD|none] [-archived true|false] [-project <id>|none] [-parent <id>|none] [-repeat daily|eod|RRULE:...|none] [-estimate <minutes>]")
 	fmt.Println("  done   <task-id>")
-	fmt.Println("  due    <task-id> <YYYY-MM-DD>")
+	fmt.Println("  due    <task-id> <YYYY-MM-DD> [HH:MM [zone]]")
+	fmt.Println("  snooze <task-id> [YYYY-MM-DD|tomorrow|next_week]")
 	fmt.Println("  reorder -items id:order[:due_date],id:order[:due_date]")
+	fmt.Println("  place  [-after <id>] [-before <id>] <task-id>")
+	fmt.Println("  undo | redo")
+	fmt.Println("  history <task-id>")
+	fmt.Println("  history restore <task-id> <event-id>")
 	fmt.Println("  export [-since <seq>]")
 	fmt.Println("  import -events <json>")
 	fmt.Println("  decrypt-event -payload <base64>")
+	fmt.Println("  sync (requires -folder <dir>)")
+	fmt.Println("  compact")
+	fmt.Println("  snapshot export | snapshot import <file>")
+	fmt.Println("  cursor")
+	fmt.Println("  ack [<device-id> <cursor-json>]")
+	fmt.Println("  lan serve [-listen <addr>]")
+	fmt.Println("  lan discover [-timeout <ms>]")
+	fmt.Println("  lan sync <address>")
+	fmt.Println("  pair start")
+	fmt.Println("  pair join <payload> | pair join <address> <code>  (new database, no -pass)")
+	fmt.Println("  account signup|login <username> <password>")
+	fmt.Println("  account logout|status|recovery-key")
+	fmt.Println("  account reset <username> <recovery-key> <new-password>")
+	fmt.Println("  device list")
+	fmt.Println("  device rename <device-id> <name>")
+	fmt.Println("  device revoke <device-id>")
 	fmt.Println("  delete <task-id>")
+	fmt.Println("  project add -name <n> [-color #RRGGBB] [-order <n>]")
+	fmt.Println("  project list [-all]")
+	fmt.Println("  project rename <project-id> <name>")
+	fmt.Println("  project update -id <id> [-name <n>] [-color #RRGGBB] [-order <n>] [-archived true|false]")
+	fmt.Println("  project delete [-tasks unassign|delete] <project-id>")
+	fmt.Println("  tag add <task-id> <tag>")
+	fmt.Println("  tag remove <task-id> <tag>")
+	fmt.Println("  tag list")
+	fmt.Println("  tag rename <from> <to>")
+	fmt.Println("  tag merge -into <tag> <tag,tag>")
+	fmt.Println("  depend add|remove <task-id> <blocker-id>")
+	fmt.Println("  checklist add <task-id> <text>")
+	fmt.Println("  checklist toggle|remove <task-id> <item-id>")
+	fmt.Println("  checklist reorder <task-id> <item-id,item-id>")
+	fmt.Println("  comment add <task-id> <text>")
+	fmt.Println("  comment list <task-id>")
+	fmt.Println("  comment edit <comment-id> <text>")
+	fmt.Println("  comment delete <comment-id>")
+	fmt.Println("  attach add <task-id> <path>")
+	fmt.Println("  attach export <task-id> <attachment-id> <dest-path>")
+	fmt.Println("  attach remove <task-id> <attachment-id>")
+	fmt.Println("  attach missing|gc")
+	fmt.Println("  link add [-label <label>] <task-id> url|tel|mailto|file <value>")
+	fmt.Println("  link remove <task-id> <value>")
+	fmt.Println("  link list <task-id>")
+	fmt.Println("  link extract <text>")
+	fmt.Println("  workflow show")
+	fmt.Println("  workflow set <workflow-json>")
+	fmt.Println("  workflow move <task-id> <state>")
+	fmt.Println("  time start <task-id>")
+	fmt.Println("  time stop|status")
+	fmt.Println("  time list [task-id]")
+	fmt.Println("  time add -start <time> -end <time> [-note <text>] <task-id>")
+	fmt.Println("  time delete <entry-id>")
+	fmt.Println("  report time [-from YYYY-MM-DD] [-to YYYY-MM-DD] [-by task|project|tag]")
+	fmt.Println("  reminders add [-at <RFC3339>|-before <minutes>] <task-id>")
+	fmt.Println("  reminders remove|ack <task-id> <reminder-id>")
+	fmt.Println("  reminders snooze [-minutes <n>] <task-id> <reminder-id>")
+	fmt.Println("  reminders pending")
+	fmt.Println("  reminders watch [-interval 30s] [-exec <command>]")
 }
 
 func parseInt64(input string) (int64, error) {
@@ -336,12 +1218,26 @@ func parseInt64(input string) (int64, error) {
 	return value, err
 }
 
+func splitList(input string) []string {
+	if strings.TrimSpace(input) == "" {
+		return nil
+	}
+	parts := strings.Split(input, ",")
+	out := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
 func isAlreadyInit(errStr string) bool {
 	return strings.Contains(errStr, "keys already initialized")
 }
 
 func loadTask(core *bind.Core, id string) (bind.TaskDTO, error) {
-	result := core.ListTasks("")
+	result := core.ListTasks(`{"include_deferred":true}`)
 	var tasks []bind.TaskDTO
 	if err := json.Unmarshal([]byte(result), &tasks); err != nil {
 		return bind.TaskDTO{}, fmt.Errorf("decode tasks: %v", err)
@@ -353,3 +1249,17 @@ func loadTask(core *bind.Core, id string) (bind.TaskDTO, error) {
 	}
 	return bind.TaskDTO{}, fmt.Errorf("task not found: %s", id)
 }
+
+func loadProject(core *bind.Core, id string) (bind.ProjectDTO, error) {
+	result := core.ListProjects(true)
+	var projects []bind.ProjectDTO
+	if err := json.Unmarshal([]byte(result), &projects); err != nil {
+		return bind.ProjectDTO{}, fmt.Errorf("decode projects: %v", err)
+	}
+	for _, project := range projects {
+		if project.ID == id {
+			return project, nil
+		}
+	}
+	return bind.ProjectDTO{}, fmt.Errorf("project not found: %s", id)
+}
@@ -87,6 +87,15 @@ func Core_ListTasks(handle C.uint64_t, filterJSON *C.char) *C.char {
 	return cString(core.ListTasks(cGoString(filterJSON)))
 }
 
+//export Core_ListTaskTree
+func Core_ListTaskTree(handle C.uint64_t, filterJSON *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.ListTaskTree(cGoString(filterJSON)))
+}
+
 //export Core_CreateTask
 func Core_CreateTask(handle C.uint64_t, taskJSON *C.char) *C.char {
 	core := getCore(handle)
@@ -123,6 +132,258 @@ func Core_ReorderTasks(handle C.uint64_t, reorderJSON *C.char) *C.char {
 	return cString(core.ReorderTasks(cGoString(reorderJSON)))
 }
 
+//export Core_MoveTaskBetween
+func Core_MoveTaskBetween(handle C.uint64_t, taskID *C.char, beforeID *C.char, afterID *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.MoveTaskBetween(cGoString(taskID), cGoString(beforeID), cGoString(afterID)))
+}
+
+//export Core_Undo
+func Core_Undo(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.Undo())
+}
+
+//export Core_Redo
+func Core_Redo(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.Redo())
+}
+
+//export Core_TaskHistory
+func Core_TaskHistory(handle C.uint64_t, taskID *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.TaskHistory(cGoString(taskID)))
+}
+
+//export Core_RestoreTaskVersion
+func Core_RestoreTaskVersion(handle C.uint64_t, taskID *C.char, eventID *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.RestoreTaskVersion(cGoString(taskID), cGoString(eventID)))
+}
+
+//export Core_Sync
+func Core_Sync(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.Sync())
+}
+
+//export Core_StartLANSync
+func Core_StartLANSync(handle C.uint64_t, listenAddr *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.StartLANSync(cGoString(listenAddr)))
+}
+
+//export Core_StopLANSync
+func Core_StopLANSync(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.StopLANSync())
+}
+
+//export Core_DiscoverPeers
+func Core_DiscoverPeers(handle C.uint64_t, timeoutMillis C.longlong) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.DiscoverPeers(int64(timeoutMillis)))
+}
+
+//export Core_SyncWithPeer
+func Core_SyncWithPeer(handle C.uint64_t, address *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.SyncWithPeer(cGoString(address)))
+}
+
+//export Core_StartPairing
+func Core_StartPairing(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.StartPairing())
+}
+
+//export Core_PairingStatus
+func Core_PairingStatus(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.PairingStatus())
+}
+
+//export Core_CancelPairing
+func Core_CancelPairing(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.CancelPairing())
+}
+
+//export Core_CompletePairing
+func Core_CompletePairing(handle C.uint64_t, payload *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.CompletePairing(cGoString(payload)))
+}
+
+//export Core_Signup
+func Core_Signup(handle C.uint64_t, username *C.char, password *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.Signup(cGoString(username), cGoString(password)))
+}
+
+//export Core_Login
+func Core_Login(handle C.uint64_t, username *C.char, password *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.Login(cGoString(username), cGoString(password)))
+}
+
+//export Core_Logout
+func Core_Logout(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.Logout())
+}
+
+//export Core_AccountStatus
+func Core_AccountStatus(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.AccountStatus())
+}
+
+//export Core_CreateRecoveryKey
+func Core_CreateRecoveryKey(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.CreateRecoveryKey())
+}
+
+//export Core_ResetPassword
+func Core_ResetPassword(handle C.uint64_t, username *C.char, recoveryKey *C.char, newPassword *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.ResetPassword(cGoString(username), cGoString(recoveryKey), cGoString(newPassword)))
+}
+
+//export Core_Compact
+func Core_Compact(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.Compact())
+}
+
+//export Core_ExportSnapshot
+func Core_ExportSnapshot(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.ExportSnapshot())
+}
+
+//export Core_ImportSnapshot
+func Core_ImportSnapshot(handle C.uint64_t, snapshotJSON *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.ImportSnapshot(cGoString(snapshotJSON)))
+}
+
+//export Core_SyncCursor
+func Core_SyncCursor(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.SyncCursor())
+}
+
+//export Core_AckEvents
+func Core_AckEvents(handle C.uint64_t, deviceID *C.char, cursorJSON *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.AckEvents(cGoString(deviceID), cGoString(cursorJSON)))
+}
+
+//export Core_ListDevices
+func Core_ListDevices(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.ListDevices())
+}
+
+//export Core_RenameDevice
+func Core_RenameDevice(handle C.uint64_t, deviceID *C.char, name *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.RenameDevice(cGoString(deviceID), cGoString(name)))
+}
+
+//export Core_RevokeDevice
+func Core_RevokeDevice(handle C.uint64_t, deviceID *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.RevokeDevice(cGoString(deviceID)))
+}
+
 //export Core_SetDueDate
 func Core_SetDueDate(handle C.uint64_t, taskID *C.char, dueDate *C.char) *C.char {
 	core := getCore(handle)
@@ -132,6 +393,24 @@ func Core_SetDueDate(handle C.uint64_t, taskID *C.char, dueDate *C.char) *C.char
 	return cString(core.SetDueDate(cGoString(taskID), cGoString(dueDate)))
 }
 
+//export Core_SetDueDateTime
+func Core_SetDueDateTime(handle C.uint64_t, taskID *C.char, dueDate *C.char, dueTime *C.char, timeZone *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.SetDueDateTime(cGoString(taskID), cGoString(dueDate), cGoString(dueTime), cGoString(timeZone)))
+}
+
+//export Core_SnoozeTask
+func Core_SnoozeTask(handle C.uint64_t, taskID *C.char, until *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.SnoozeTask(cGoString(taskID), cGoString(until)))
+}
+
 //export Core_SetCompleted
 func Core_SetCompleted(handle C.uint64_t, taskID *C.char, completed C.int) *C.char {
 	core := getCore(handle)
@@ -177,6 +456,465 @@ func Core_DebugDecryptEvent(handle C.uint64_t, payloadBase64 *C.char) *C.char {
 	return cString(core.DebugDecryptEvent(cGoString(payloadBase64)))
 }
 
+//export Core_ListProjects
+func Core_ListProjects(handle C.uint64_t, includeArchived C.int) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.ListProjects(includeArchived != 0))
+}
+
+//export Core_CreateProject
+func Core_CreateProject(handle C.uint64_t, projectJSON *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.CreateProject(cGoString(projectJSON)))
+}
+
+//export Core_UpdateProject
+func Core_UpdateProject(handle C.uint64_t, projectJSON *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.UpdateProject(cGoString(projectJSON)))
+}
+
+//export Core_RenameProject
+func Core_RenameProject(handle C.uint64_t, projectID *C.char, name *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.RenameProject(cGoString(projectID), cGoString(name)))
+}
+
+//export Core_DeleteProject
+func Core_DeleteProject(handle C.uint64_t, projectID *C.char, policy *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.DeleteProject(cGoString(projectID), cGoString(policy)))
+}
+
+//export Core_ListTags
+func Core_ListTags(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.ListTags())
+}
+
+//export Core_AddTag
+func Core_AddTag(handle C.uint64_t, taskID *C.char, tag *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.AddTag(cGoString(taskID), cGoString(tag)))
+}
+
+//export Core_RemoveTag
+func Core_RemoveTag(handle C.uint64_t, taskID *C.char, tag *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.RemoveTag(cGoString(taskID), cGoString(tag)))
+}
+
+//export Core_RenameTag
+func Core_RenameTag(handle C.uint64_t, from *C.char, to *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.RenameTag(cGoString(from), cGoString(to)))
+}
+
+//export Core_MergeTags
+func Core_MergeTags(handle C.uint64_t, tagsJSON *C.char, into *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.MergeTags(cGoString(tagsJSON), cGoString(into)))
+}
+
+//export Core_AddDependency
+func Core_AddDependency(handle C.uint64_t, taskID *C.char, blockerID *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.AddDependency(cGoString(taskID), cGoString(blockerID)))
+}
+
+//export Core_RemoveDependency
+func Core_RemoveDependency(handle C.uint64_t, taskID *C.char, blockerID *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.RemoveDependency(cGoString(taskID), cGoString(blockerID)))
+}
+
+//export Core_AddChecklistItem
+func Core_AddChecklistItem(handle C.uint64_t, taskID *C.char, text *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.AddChecklistItem(cGoString(taskID), cGoString(text)))
+}
+
+//export Core_ToggleChecklistItem
+func Core_ToggleChecklistItem(handle C.uint64_t, taskID *C.char, itemID *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.ToggleChecklistItem(cGoString(taskID), cGoString(itemID)))
+}
+
+//export Core_ReorderChecklist
+func Core_ReorderChecklist(handle C.uint64_t, taskID *C.char, itemIDsJSON *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.ReorderChecklist(cGoString(taskID), cGoString(itemIDsJSON)))
+}
+
+//export Core_RemoveChecklistItem
+func Core_RemoveChecklistItem(handle C.uint64_t, taskID *C.char, itemID *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.RemoveChecklistItem(cGoString(taskID), cGoString(itemID)))
+}
+
+//export Core_AddComment
+func Core_AddComment(handle C.uint64_t, taskID *C.char, text *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.AddComment(cGoString(taskID), cGoString(text)))
+}
+
+//export Core_ListComments
+func Core_ListComments(handle C.uint64_t, taskID *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.ListComments(cGoString(taskID)))
+}
+
+//export Core_EditComment
+func Core_EditComment(handle C.uint64_t, commentID *C.char, text *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.EditComment(cGoString(commentID), cGoString(text)))
+}
+
+//export Core_DeleteComment
+func Core_DeleteComment(handle C.uint64_t, commentID *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.DeleteComment(cGoString(commentID)))
+}
+
+//export Core_AddAttachment
+func Core_AddAttachment(handle C.uint64_t, taskID *C.char, path *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.AddAttachment(cGoString(taskID), cGoString(path)))
+}
+
+//export Core_ExportAttachment
+func Core_ExportAttachment(handle C.uint64_t, taskID *C.char, attachmentID *C.char, destPath *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.ExportAttachment(cGoString(taskID), cGoString(attachmentID), cGoString(destPath)))
+}
+
+//export Core_RemoveAttachment
+func Core_RemoveAttachment(handle C.uint64_t, taskID *C.char, attachmentID *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.RemoveAttachment(cGoString(taskID), cGoString(attachmentID)))
+}
+
+//export Core_MissingBlobs
+func Core_MissingBlobs(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.MissingBlobs())
+}
+
+//export Core_ExportBlobs
+func Core_ExportBlobs(handle C.uint64_t, hashesJSON *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.ExportBlobs(cGoString(hashesJSON)))
+}
+
+//export Core_ImportBlobs
+func Core_ImportBlobs(handle C.uint64_t, blobsJSON *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.ImportBlobs(cGoString(blobsJSON)))
+}
+
+//export Core_CollectGarbage
+func Core_CollectGarbage(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.CollectGarbage())
+}
+
+//export Core_AddLink
+func Core_AddLink(handle C.uint64_t, taskID *C.char, linkJSON *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.AddLink(cGoString(taskID), cGoString(linkJSON)))
+}
+
+//export Core_RemoveLink
+func Core_RemoveLink(handle C.uint64_t, taskID *C.char, value *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.RemoveLink(cGoString(taskID), cGoString(value)))
+}
+
+//export Core_TaskLinks
+func Core_TaskLinks(handle C.uint64_t, taskID *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.TaskLinks(cGoString(taskID)))
+}
+
+//export Core_ExtractLinks
+func Core_ExtractLinks(handle C.uint64_t, text *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.ExtractLinks(cGoString(text)))
+}
+
+//export Core_StartTimer
+func Core_StartTimer(handle C.uint64_t, taskID *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.StartTimer(cGoString(taskID)))
+}
+
+//export Core_StopTimer
+func Core_StopTimer(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.StopTimer())
+}
+
+//export Core_RunningTimer
+func Core_RunningTimer(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.RunningTimer())
+}
+
+//export Core_ListTimeEntries
+func Core_ListTimeEntries(handle C.uint64_t, taskID *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.ListTimeEntries(cGoString(taskID)))
+}
+
+//export Core_AddTimeEntry
+func Core_AddTimeEntry(handle C.uint64_t, entryJSON *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.AddTimeEntry(cGoString(entryJSON)))
+}
+
+//export Core_DeleteTimeEntry
+func Core_DeleteTimeEntry(handle C.uint64_t, entryID *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.DeleteTimeEntry(cGoString(entryID)))
+}
+
+//export Core_TimeReport
+func Core_TimeReport(handle C.uint64_t, from *C.char, to *C.char, groupBy *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.TimeReport(cGoString(from), cGoString(to), cGoString(groupBy)))
+}
+
+//export Core_GetWorkflow
+func Core_GetWorkflow(handle C.uint64_t) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.GetWorkflow())
+}
+
+//export Core_SetWorkflow
+func Core_SetWorkflow(handle C.uint64_t, workflowJSON *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.SetWorkflow(cGoString(workflowJSON)))
+}
+
+//export Core_TransitionTask
+func Core_TransitionTask(handle C.uint64_t, taskID *C.char, state *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.TransitionTask(cGoString(taskID), cGoString(state)))
+}
+
+//export Core_ListTasksGrouped
+func Core_ListTasksGrouped(handle C.uint64_t, filterJSON *C.char, groupBy *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.ListTasksGrouped(cGoString(filterJSON), cGoString(groupBy)))
+}
+
+//export Core_MoveTask
+func Core_MoveTask(handle C.uint64_t, taskID *C.char, column *C.char, position C.longlong) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.MoveTask(cGoString(taskID), cGoString(column), int64(position)))
+}
+
+//export Core_CalendarRange
+func Core_CalendarRange(handle C.uint64_t, start *C.char, end *C.char, zone *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.CalendarRange(cGoString(start), cGoString(end), cGoString(zone)))
+}
+
+//export Core_MoveToDate
+func Core_MoveToDate(handle C.uint64_t, id *C.char, date *C.char, dueTime *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.MoveToDate(cGoString(id), cGoString(date), cGoString(dueTime)))
+}
+
+//export Core_AddReminder
+func Core_AddReminder(handle C.uint64_t, taskID *C.char, reminderJSON *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.AddReminder(cGoString(taskID), cGoString(reminderJSON)))
+}
+
+//export Core_RemoveReminder
+func Core_RemoveReminder(handle C.uint64_t, taskID *C.char, reminderID *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.RemoveReminder(cGoString(taskID), cGoString(reminderID)))
+}
+
+//export Core_AcknowledgeReminder
+func Core_AcknowledgeReminder(handle C.uint64_t, taskID *C.char, reminderID *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.AcknowledgeReminder(cGoString(taskID), cGoString(reminderID)))
+}
+
+//export Core_SnoozeReminder
+func Core_SnoozeReminder(handle C.uint64_t, taskID *C.char, reminderID *C.char, minutes C.longlong) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.SnoozeReminder(cGoString(taskID), cGoString(reminderID), int64(minutes)))
+}
+
+//export Core_PendingReminders
+func Core_PendingReminders(handle C.uint64_t, now *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.PendingReminders(cGoString(now)))
+}
+
+//export Core_NextReminder
+func Core_NextReminder(handle C.uint64_t, now *C.char) *C.char {
+	core := getCore(handle)
+	if core == nil {
+		return cError("core not found")
+	}
+	return cString(core.NextReminder(cGoString(now)))
+}
+
 //export Core_FreeString
 func Core_FreeString(str *C.char) {
 	if str != nil {
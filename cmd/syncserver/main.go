@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"taskpp/internal/server"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	dbPath := flag.String("db", "syncserver.db", "path to sqlite db")
+	sessionTTL := flag.Duration("session-ttl", server.DefaultSessionTTL, "how long a login stays valid")
+	flag.Parse()
+
+	ctx := context.Background()
+	store, err := server.OpenStore(ctx, "file:"+*dbPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer store.Close()
+	srv, err := server.New(store, server.Options{SessionTTL: *sessionTTL})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt)
+		<-stop
+		shutdown, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdown)
+	}()
+	log.Printf("sync server listening on %s", *addr)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
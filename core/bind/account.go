@@ -0,0 +1,309 @@
+package bind
+
+import (
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"taskpp/core/crypto"
+	"taskpp/core/model"
+	"taskpp/core/sync"
+)
+
+// AccountDTO describes this device's login on the sync server. The token
+// itself never leaves the core.
+type AccountDTO struct {
+	ServerURL string `json:"server_url"`
+	Username  string `json:"username"`
+	UserID    string `json:"user_id"`
+	ExpiresAt string `json:"expires_at"`
+	LoggedIn  bool   `json:"logged_in"`
+}
+
+// RecoveryKeyDTO carries a new recovery key, shown to the user once.
+type RecoveryKeyDTO struct {
+	RecoveryKey string `json:"recovery_key"`
+}
+
+// recoveryEncoding spells recovery keys without padding or lookalike
+// lowercase letters.
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Signup creates an account on Config.server_url and logs this device in.
+// Returns JSON-encoded AccountDTO.
+func (c *Core) Signup(username string, password string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.startAccountSession(username, password, true)
+}
+
+// Login logs this device in to its account on Config.server_url. The token
+// is stored encrypted and bound to this device. Returns JSON-encoded
+// AccountDTO.
+func (c *Core) Login(username string, password string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.startAccountSession(username, password, false)
+}
+
+func (c *Core) startAccountSession(username, password string, signup bool) string {
+	client, err := c.accountClient()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	username = strings.TrimSpace(username)
+	if username == "" || password == "" {
+		return errorJSON("username and password are required")
+	}
+	login := client.Login
+	if signup {
+		login = client.Signup
+	}
+	session, err := login(username, password, c.deviceName)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("login: %v", err))
+	}
+	account := accountFromSession(c.serverURL, username, session)
+	if err := c.store.SaveAccount(account); err != nil {
+		return errorJSON(fmt.Sprintf("save account: %v", err))
+	}
+	return encodeAccount(account)
+}
+
+// Logout revokes this device's token on the server and forgets it. A token
+// the server already refuses is forgotten as well. Returns empty string on
+// success.
+func (c *Core) Logout() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return errorJSON("keys not unlocked")
+	}
+	account, err := c.store.GetAccount()
+	if err != nil {
+		return errorJSON(fmt.Sprintf("get account: %v", err))
+	}
+	if account.Token == "" {
+		return errorJSON("not logged in")
+	}
+	localID, err := c.localDeviceID()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	client := sync.NewAccountClient(account.ServerURL, localID)
+	if err := client.Logout(accountSession(account)); err != nil && !errors.Is(err, sync.ErrUnauthorized) {
+		return errorJSON(fmt.Sprintf("logout: %v", err))
+	}
+	if err := c.store.SaveAccount(model.Account{ServerURL: account.ServerURL, Username: account.Username}); err != nil {
+		return errorJSON(fmt.Sprintf("save account: %v", err))
+	}
+	return ""
+}
+
+// AccountStatus returns JSON-encoded AccountDTO; logged_in is false once the
+// token expired.
+func (c *Core) AccountStatus() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return errorJSON("keys not unlocked")
+	}
+	account, err := c.store.GetAccount()
+	if err != nil {
+		return errorJSON(fmt.Sprintf("get account: %v", err))
+	}
+	if account.ServerURL == "" {
+		account.ServerURL = c.serverURL
+	}
+	return encodeAccount(account)
+}
+
+// CreateRecoveryKey makes a new recovery key, uploads the vault key wrapped
+// with it and returns the key for the user to write down. It replaces any
+// earlier recovery key. Returns JSON-encoded RecoveryKeyDTO.
+func (c *Core) CreateRecoveryKey() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return errorJSON("keys not unlocked")
+	}
+	account, err := c.store.GetAccount()
+	if err != nil {
+		return errorJSON(fmt.Sprintf("get account: %v", err))
+	}
+	if !accountLoggedIn(account) {
+		return errorJSON("not logged in")
+	}
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return errorJSON(fmt.Sprintf("recovery key: %v", err))
+	}
+	wrapKey, verifier, err := recoveryKeys(secret)
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	wrapped, err := c.keys.WrapKey(wrapKey)
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	localID, err := c.localDeviceID()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	client := sync.NewAccountClient(account.ServerURL, localID)
+	if err := client.SaveRecovery(accountSession(account), wrapped, verifier); err != nil {
+		return errorJSON(fmt.Sprintf("save recovery key: %v", err))
+	}
+	data, err := json.Marshal(RecoveryKeyDTO{RecoveryKey: formatRecoveryKey(secret)})
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode recovery key: %v", err))
+	}
+	return string(data)
+}
+
+// ResetPassword sets a new account password with the recovery key instead
+// of the old password, signs out every other device and logs this one in.
+// The session is only kept if the returned vault key opens with the
+// recovery key and matches this vault; the password is changed either way.
+// Returns JSON-encoded AccountDTO.
+func (c *Core) ResetPassword(username string, recoveryKey string, newPassword string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	client, err := c.accountClient()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	username = strings.TrimSpace(username)
+	secret, err := parseRecoveryKey(recoveryKey)
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	wrapKey, verifier, err := recoveryKeys(secret)
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	session, wrapped, err := client.ResetPassword(username, verifier, newPassword, c.deviceName)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("reset password: %v", err))
+	}
+	recovered := crypto.NewManager()
+	if err := recovered.UnwrapKey(wrapKey, wrapped); err != nil {
+		return errorJSON("recovery key does not open the stored vault key")
+	}
+	probe, err := c.keys.Encrypt([]byte("taskpp recovery probe"))
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	if _, err := recovered.Decrypt(probe); err != nil {
+		return errorJSON("recovery key belongs to another vault")
+	}
+	account := accountFromSession(c.serverURL, username, session)
+	if err := c.store.SaveAccount(account); err != nil {
+		return errorJSON(fmt.Sprintf("save account: %v", err))
+	}
+	return encodeAccount(account)
+}
+
+// accountClient checks what every account call needs and returns a client
+// for Config.server_url.
+func (c *Core) accountClient() (*sync.AccountClient, error) {
+	if c.store == nil {
+		return nil, fmt.Errorf("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return nil, fmt.Errorf("keys not unlocked")
+	}
+	if c.serverURL == "" {
+		return nil, fmt.Errorf("server_url not configured")
+	}
+	localID, err := c.localDeviceID()
+	if err != nil {
+		return nil, err
+	}
+	return sync.NewAccountClient(c.serverURL, localID), nil
+}
+
+// recoveryKeys derives the key that wraps the vault key and the verifier
+// that authorizes a password reset. The server only ever sees the verifier
+// and the wrapped key, neither of which reveals the other.
+func recoveryKeys(secret []byte) ([]byte, []byte, error) {
+	wrapKey, err := hkdf.Key(sha256.New, secret, nil, "taskpp recovery wrap v1", 32)
+	if err != nil {
+		return nil, nil, fmt.Errorf("derive recovery key: %w", err)
+	}
+	verifier, err := hkdf.Key(sha256.New, secret, nil, "taskpp recovery verifier v1", 32)
+	if err != nil {
+		return nil, nil, fmt.Errorf("derive recovery key: %w", err)
+	}
+	return wrapKey, verifier, nil
+}
+
+// formatRecoveryKey groups the base32 key in blocks of four.
+func formatRecoveryKey(secret []byte) string {
+	encoded := recoveryEncoding.EncodeToString(secret)
+	groups := make([]string, 0, len(encoded)/4+1)
+	for len(encoded) > 4 {
+		groups = append(groups, encoded[:4])
+		encoded = encoded[4:]
+	}
+	return strings.Join(append(groups, encoded), "-")
+}
+
+func parseRecoveryKey(key string) ([]byte, error) {
+	cleaned := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(key))
+	secret, err := recoveryEncoding.DecodeString(cleaned)
+	if err != nil || len(secret) != 20 {
+		return nil, fmt.Errorf("invalid recovery key")
+	}
+	return secret, nil
+}
+
+func accountFromSession(serverURL, username string, session sync.AccountSession) model.Account {
+	return model.Account{
+		ServerURL: serverURL,
+		Username:  username,
+		UserID:    session.UserID,
+		Token:     session.Token,
+		ExpiresAt: session.ExpiresAt,
+	}
+}
+
+func accountSession(account model.Account) sync.AccountSession {
+	return sync.AccountSession{UserID: account.UserID, Token: account.Token, ExpiresAt: account.ExpiresAt}
+}
+
+func accountLoggedIn(account model.Account) bool {
+	return account.Token != "" && time.Now().Before(account.ExpiresAt)
+}
+
+func encodeAccount(account model.Account) string {
+	dto := AccountDTO{
+		ServerURL: account.ServerURL,
+		Username:  account.Username,
+		UserID:    account.UserID,
+		LoggedIn:  accountLoggedIn(account),
+	}
+	if !account.ExpiresAt.IsZero() {
+		dto.ExpiresAt = formatTime(account.ExpiresAt)
+	}
+	data, err := json.Marshal(dto)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode account: %v", err))
+	}
+	return string(data)
+}
@@ -0,0 +1,101 @@
+package bind
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"taskpp/internal/server"
+)
+
+func newAccountCore(t *testing.T, serverURL string) *Core {
+	t.Helper()
+	cfgJSON, _ := json.Marshal(Config{
+		StorageDriver: "sqlite",
+		StoragePath:   "file:" + filepath.Join(t.TempDir(), "bind.db"),
+		ServerURL:     serverURL,
+	})
+	core := NewCore(string(cfgJSON))
+	if errStr := core.Open(); errStr != "" {
+		t.Fatalf("open: %s", errStr)
+	}
+	t.Cleanup(func() { core.Close() })
+	return core
+}
+
+func decodeAccount(t *testing.T, out string) AccountDTO {
+	t.Helper()
+	var account AccountDTO
+	if err := json.Unmarshal([]byte(out), &account); err != nil || hasError(out) {
+		t.Fatalf("decode account: %v (%s)", err, out)
+	}
+	return account
+}
+
+func TestAccountLoginRecoveryAndReset(t *testing.T) {
+	store, err := server.OpenStore(context.Background(), "file:"+filepath.Join(t.TempDir(), "server.db"))
+	if err != nil {
+		t.Fatalf("open server store: %v", err)
+	}
+	defer store.Close()
+	srv, err := server.New(store, server.Options{})
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	httpServer := httptest.NewServer(srv.Handler())
+	defer httpServer.Close()
+
+	laptop := newAccountCore(t, httpServer.URL)
+	if errStr := laptop.InitKeys("passphrase"); errStr != "" {
+		t.Fatalf("init keys: %s", errStr)
+	}
+	if status := decodeAccount(t, laptop.AccountStatus()); status.LoggedIn || status.ServerURL != httpServer.URL {
+		t.Fatalf("expected a logged out account, got %+v", status)
+	}
+	if !hasError(laptop.CreateRecoveryKey()) {
+		t.Fatalf("expected a recovery key to need a login")
+	}
+	account := decodeAccount(t, laptop.Signup("alice", "correct horse"))
+	if !account.LoggedIn || account.UserID == "" || account.ExpiresAt == "" {
+		t.Fatalf("unexpected account after signup: %+v", account)
+	}
+	var recovery RecoveryKeyDTO
+	if out := laptop.CreateRecoveryKey(); json.Unmarshal([]byte(out), &recovery) != nil || recovery.RecoveryKey == "" {
+		t.Fatalf("create recovery key: %s", out)
+	}
+
+	phone := newAccountCore(t, httpServer.URL)
+	shareKeys(t, laptop, phone)
+	if !hasError(phone.Login("alice", "wrong password")) {
+		t.Fatalf("expected a wrong password to fail")
+	}
+	if !hasError(phone.ResetPassword("alice", "AAAA-AAAA-AAAA-AAAA-AAAA-AAAA-AAAA-AAAA", "battery staple")) {
+		t.Fatalf("expected a wrong recovery key to fail")
+	}
+	reset := decodeAccount(t, phone.ResetPassword("alice", recovery.RecoveryKey, "battery staple"))
+	if !reset.LoggedIn || reset.UserID != account.UserID {
+		t.Fatalf("unexpected account after reset: %+v", reset)
+	}
+
+	// The reset signed the laptop out; logging out still forgets the token.
+	if errStr := laptop.Logout(); errStr != "" {
+		t.Fatalf("logout: %s", errStr)
+	}
+	if status := decodeAccount(t, laptop.AccountStatus()); status.LoggedIn || status.Username != "alice" {
+		t.Fatalf("expected the laptop to be logged out, got %+v", status)
+	}
+	if !hasError(laptop.Login("alice", "correct horse")) {
+		t.Fatalf("expected the old password to stop working")
+	}
+	decodeAccount(t, laptop.Login("alice", "battery staple"))
+
+	other := newAccountCore(t, httpServer.URL)
+	if errStr := other.InitKeys("other passphrase"); errStr != "" {
+		t.Fatalf("init keys: %s", errStr)
+	}
+	if out := other.ResetPassword("alice", recovery.RecoveryKey, "third password"); !hasError(out) {
+		t.Fatalf("expected a recovery key of another vault to be refused, got %s", out)
+	}
+}
@@ -0,0 +1,361 @@
+package bind
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+
+	"taskpp/core/logic"
+	"taskpp/core/model"
+	"taskpp/core/storage/sqlite"
+)
+
+// AttachmentDTO is bind-safe attachment metadata.
+type AttachmentDTO struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+	Hash     string `json:"hash"`
+	AddedAt  string `json:"added_at"`
+}
+
+// BlobDTO carries attachment bytes between devices as base64 ciphertext
+// chunks encrypted with the vault key.
+type BlobDTO struct {
+	Hash   string   `json:"hash"`
+	Chunks []string `json:"chunks"`
+}
+
+// AddAttachment reads a file, stores it in the encrypted blob store and
+// attaches it to a task. Returns AttachmentDTO JSON.
+func (c *Core) AddAttachment(taskID string, path string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("read attachment: %v", err))
+	}
+	name := filepath.Base(path)
+	if err := logic.ValidateAttachment(name, int64(len(data))); err != nil {
+		return errorJSON(fmt.Sprintf("validate attachment: %v", err))
+	}
+	hash, err := c.keys.ContentHash(data)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("hash attachment: %v", err))
+	}
+	attachment := model.Attachment{
+		ID:       uuid.NewString(),
+		Name:     name,
+		MimeType: mime.TypeByExtension(filepath.Ext(name)),
+		Size:     int64(len(data)),
+		Hash:     hash,
+		AddedAt:  time.Now().UTC(),
+	}
+	if err := c.store.PutBlob(hash, data); err != nil {
+		return errorJSON(fmt.Sprintf("store attachment: %v", err))
+	}
+	errStr := c.editAttachments(taskID, "attachment_add", func(task *model.Task) error {
+		task.Attachments = append(task.Attachments, attachment)
+		return nil
+	})
+	if errStr != "" {
+		return errStr
+	}
+	out, err := json.Marshal(attachmentsToDTO([]model.Attachment{attachment})[0])
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode attachment: %v", err))
+	}
+	return string(out)
+}
+
+// ExportAttachment decrypts an attachment to destPath. Returns empty string on
+// success, or an error when the blob has not been synced to this device yet.
+func (c *Core) ExportAttachment(taskID string, attachmentID string, destPath string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	task, err := c.store.GetTask(taskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load task: %v", err))
+	}
+	for _, attachment := range task.Attachments {
+		if attachment.ID != attachmentID {
+			continue
+		}
+		data, err := c.store.GetBlob(attachment.Hash)
+		if err != nil {
+			return errorJSON(fmt.Sprintf("load attachment: %v", err))
+		}
+		if err := os.WriteFile(destPath, data, 0o600); err != nil {
+			return errorJSON(fmt.Sprintf("write attachment: %v", err))
+		}
+		return ""
+	}
+	return errorJSON("attachment not found")
+}
+
+// RemoveAttachment detaches a file from a task. The blob stays, since Undo
+// can attach it again; CollectGarbage deletes it once nothing refers to it.
+// Returns empty string on success.
+func (c *Core) RemoveAttachment(taskID string, attachmentID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.editAttachments(taskID, "attachment_remove", func(task *model.Task) error {
+		for i, attachment := range task.Attachments {
+			if attachment.ID == attachmentID {
+				task.Attachments = append(task.Attachments[:i], task.Attachments[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("attachment not found")
+	})
+}
+
+// CollectGarbage deletes stored blobs that nothing refers to and returns the
+// removed hashes as a JSON array. Besides current tasks, every task version
+// still in the event log counts: Undo, Redo and RestoreTaskVersion restore
+// from those (the undo stacks only point into the log), so their blobs
+// stay until Compact prunes the events.
+func (c *Core) CollectGarbage() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return errorJSON("keys not unlocked")
+	}
+	stored, err := c.store.ListBlobs()
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list blobs: %v", err))
+	}
+	tasks, err := c.store.ListTasks(model.TaskFilter{})
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list tasks: %v", err))
+	}
+	log, err := c.taskEventLog()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	for _, entries := range log.byTask {
+		for _, entry := range entries {
+			attachments, err := dtoToAttachments(entry.dto.Attachments)
+			if err != nil {
+				return errorJSON(fmt.Sprintf("logged attachments: %v", err))
+			}
+			tasks = append(tasks, model.Task{Attachments: attachments})
+		}
+	}
+	orphans := logic.OrphanedBlobs(stored, tasks)
+	for _, hash := range orphans {
+		if err := c.store.DeleteBlob(hash); err != nil {
+			return errorJSON(fmt.Sprintf("delete blob: %v", err))
+		}
+	}
+	data, err := json.Marshal(orphans)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode hashes: %v", err))
+	}
+	return string(data)
+}
+
+// MissingBlobs returns a JSON array of attachment hashes referenced by tasks
+// but not yet stored on this device, for fetching after an event import.
+func (c *Core) MissingBlobs() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	tasks, err := c.store.ListTasks(model.TaskFilter{})
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list tasks: %v", err))
+	}
+	missing := make([]string, 0)
+	for _, hash := range logic.ReferencedBlobs(tasks) {
+		exists, err := c.store.HasBlob(hash)
+		if err != nil {
+			return errorJSON(fmt.Sprintf("check blob: %v", err))
+		}
+		if !exists {
+			missing = append(missing, hash)
+		}
+	}
+	data, err := json.Marshal(missing)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode hashes: %v", err))
+	}
+	return string(data)
+}
+
+// ExportBlobs returns JSON-encoded BlobDTO for the hashes in hashesJSON (a
+// JSON array). Hashes not stored locally are skipped.
+func (c *Core) ExportBlobs(hashesJSON string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	var hashes []string
+	if err := json.Unmarshal([]byte(hashesJSON), &hashes); err != nil {
+		return errorJSON(fmt.Sprintf("decode hashes: %v", err))
+	}
+	out := make([]BlobDTO, 0, len(hashes))
+	for _, hash := range hashes {
+		exists, err := c.store.HasBlob(hash)
+		if err != nil {
+			return errorJSON(fmt.Sprintf("check blob: %v", err))
+		}
+		if !exists {
+			continue
+		}
+		data, err := c.store.GetBlob(hash)
+		if err != nil {
+			return errorJSON(fmt.Sprintf("load blob: %v", err))
+		}
+		blob := BlobDTO{Hash: hash, Chunks: make([]string, 0, len(data)/sqlite.BlobChunkSize+1)}
+		for offset := 0; ; offset += sqlite.BlobChunkSize {
+			end := min(offset+sqlite.BlobChunkSize, len(data))
+			ciphertext, err := c.keys.Encrypt(data[offset:end])
+			if err != nil {
+				return errorJSON(fmt.Sprintf("encrypt blob: %v", err))
+			}
+			blob.Chunks = append(blob.Chunks, base64.StdEncoding.EncodeToString(ciphertext))
+			if end == len(data) {
+				break
+			}
+		}
+		out = append(out, blob)
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode blobs: %v", err))
+	}
+	return string(data)
+}
+
+// ImportBlobs stores JSON-encoded BlobDTO received from another device. Each
+// blob is verified against its content hash; blobs already present are
+// skipped. Returns empty string on success.
+func (c *Core) ImportBlobs(blobsJSON string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	var blobs []BlobDTO
+	if err := json.Unmarshal([]byte(blobsJSON), &blobs); err != nil {
+		return errorJSON(fmt.Sprintf("decode blobs: %v", err))
+	}
+	for _, blob := range blobs {
+		exists, err := c.store.HasBlob(blob.Hash)
+		if err != nil {
+			return errorJSON(fmt.Sprintf("check blob: %v", err))
+		}
+		if exists {
+			continue
+		}
+		var data []byte
+		for _, chunk := range blob.Chunks {
+			ciphertext, err := base64.StdEncoding.DecodeString(chunk)
+			if err != nil {
+				return errorJSON(fmt.Sprintf("decode blob chunk: %v", err))
+			}
+			plaintext, err := c.keys.Decrypt(ciphertext)
+			if err != nil {
+				return errorJSON(fmt.Sprintf("decrypt blob chunk: %v", err))
+			}
+			data = append(data, plaintext...)
+		}
+		hash, err := c.keys.ContentHash(data)
+		if err != nil {
+			return errorJSON(fmt.Sprintf("hash blob: %v", err))
+		}
+		if hash != blob.Hash {
+			return errorJSON(fmt.Sprintf("blob %s failed verification", blob.Hash))
+		}
+		if err := c.store.PutBlob(hash, data); err != nil {
+			return errorJSON(fmt.Sprintf("store blob: %v", err))
+		}
+	}
+	return ""
+}
+
+func (c *Core) editAttachments(taskID string, eventType string, mutate func(task *model.Task) error) string {
+	if taskID == "" {
+		return errorJSON("missing id")
+	}
+	task, err := c.store.GetTask(taskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load task: %v", err))
+	}
+	if task.ID == "" {
+		return errorJSON("task not found")
+	}
+	if err := mutate(&task); err != nil {
+		return errorJSON(fmt.Sprintf("%s: %v", eventType, err))
+	}
+	task.UpdatedAt = time.Now().UTC()
+	if err := c.store.UpsertTask(task); err != nil {
+		return errorJSON(fmt.Sprintf("update task: %v", err))
+	}
+	if err := c.appendEvent(eventType, task); err != nil {
+		return errorJSON(fmt.Sprintf("event %s: %v", eventType, err))
+	}
+	return ""
+}
+
+func attachmentsToDTO(attachments []model.Attachment) []AttachmentDTO {
+	if len(attachments) == 0 {
+		return nil
+	}
+	out := make([]AttachmentDTO, 0, len(attachments))
+	for _, attachment := range attachments {
+		out = append(out, AttachmentDTO{
+			ID:       attachment.ID,
+			Name:     attachment.Name,
+			MimeType: attachment.MimeType,
+			Size:     attachment.Size,
+			Hash:     attachment.Hash,
+			AddedAt:  formatTime(attachment.AddedAt),
+		})
+	}
+	return out
+}
+
+func dtoToAttachments(dtos []AttachmentDTO) ([]model.Attachment, error) {
+	if len(dtos) == 0 {
+		return nil, nil
+	}
+	out := make([]model.Attachment, 0, len(dtos))
+	for _, dto := range dtos {
+		addedAt, err := parseTime(dto.AddedAt)
+		if err != nil {
+			return nil, fmt.Errorf("attachment added_at: %w", err)
+		}
+		if dto.ID == "" || dto.Hash == "" {
+			return nil, fmt.Errorf("attachment requires id and hash")
+		}
+		out = append(out, model.Attachment{
+			ID:       dto.ID,
+			Name:     dto.Name,
+			MimeType: dto.MimeType,
+			Size:     dto.Size,
+			Hash:     dto.Hash,
+			AddedAt:  addedAt,
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,107 @@
+package bind
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAttachmentsSyncDedupeAndGC(t *testing.T) {
+	phone := newTestCore(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "receipt.pdf")
+	content := []byte("%PDF-1.7 receipt")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	first := createTask(t, phone, TaskDTO{Title: "Expense report"})
+	second := createTask(t, phone, TaskDTO{Title: "Tax folder"})
+	var attachment AttachmentDTO
+	if err := json.Unmarshal([]byte(phone.AddAttachment(first.ID, path)), &attachment); err != nil {
+		t.Fatalf("decode attachment: %v", err)
+	}
+	if attachment.MimeType != "application/pdf" || attachment.Size != int64(len(content)) {
+		t.Fatalf("unexpected attachment metadata: %+v", attachment)
+	}
+	if out := phone.AddAttachment(second.ID, path); hasError(out) {
+		t.Fatalf("add attachment: %s", out)
+	}
+	hashes, _ := phone.store.ListBlobs()
+	if len(hashes) != 1 {
+		t.Fatalf("expected identical files to share one blob, got %v", hashes)
+	}
+
+	laptop := newTestCore(t)
+	shareKeys(t, phone, laptop)
+	if errStr := laptop.ImportEvents(phone.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import: %s", errStr)
+	}
+	missing := laptop.MissingBlobs()
+	if missing != `["`+attachment.Hash+`"]` {
+		t.Fatalf("expected attachment blob to be missing, got %s", missing)
+	}
+	if errStr := laptop.ImportBlobs(phone.ExportBlobs(missing)); errStr != "" {
+		t.Fatalf("import blobs: %s", errStr)
+	}
+	out := filepath.Join(dir, "copy.pdf")
+	if errStr := laptop.ExportAttachment(first.ID, attachment.ID, out); errStr != "" {
+		t.Fatalf("export attachment: %s", errStr)
+	}
+	if got, _ := os.ReadFile(out); string(got) != string(content) {
+		t.Fatalf("exported attachment mismatch: %q", got)
+	}
+
+	tampered := `[{"hash":"` + attachment.Hash + `","chunks":[]}]`
+	other := newTestCore(t)
+	shareKeys(t, phone, other)
+	if !hasError(other.ImportBlobs(tampered)) {
+		t.Fatalf("expected blob with wrong content to be rejected")
+	}
+
+	if errStr := phone.RemoveAttachment(first.ID, attachment.ID); errStr != "" {
+		t.Fatalf("remove attachment: %s", errStr)
+	}
+	if hashes, _ := phone.store.ListBlobs(); len(hashes) != 1 {
+		t.Fatalf("expected blob kept while still referenced, got %v", hashes)
+	}
+	if errStr := phone.DeleteTask(second.ID); errStr != "" {
+		t.Fatalf("delete task: %s", errStr)
+	}
+	if removed := phone.CollectGarbage(); removed != `[]` {
+		t.Fatalf("expected blob kept while logged versions refer to it, got %s", removed)
+	}
+	if out := phone.Compact(); hasError(out) {
+		t.Fatalf("compact: %s", out)
+	}
+	if removed := phone.CollectGarbage(); removed != `["`+attachment.Hash+`"]` {
+		t.Fatalf("expected orphaned blob collected, got %s", removed)
+	}
+}
+
+func TestUndoRemoveAttachmentKeepsBlob(t *testing.T) {
+	core := newTestCore(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "screenshot.png")
+	if err := os.WriteFile(path, []byte("png bytes"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	task := createTask(t, core, TaskDTO{Title: "Bug report"})
+	var attachment AttachmentDTO
+	if err := json.Unmarshal([]byte(core.AddAttachment(task.ID, path)), &attachment); err != nil {
+		t.Fatalf("decode attachment: %v", err)
+	}
+	if errStr := core.RemoveAttachment(task.ID, attachment.ID); errStr != "" {
+		t.Fatalf("remove attachment: %s", errStr)
+	}
+	if removed := core.CollectGarbage(); removed != `[]` {
+		t.Fatalf("expected the blob to outlive the removal, got %s", removed)
+	}
+	if errStr := core.Undo(); errStr != "" {
+		t.Fatalf("undo: %s", errStr)
+	}
+	if errStr := core.ExportAttachment(task.ID, attachment.ID, filepath.Join(dir, "copy.png")); errStr != "" {
+		t.Fatalf("export after undo: %s", errStr)
+	}
+}
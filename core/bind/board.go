@@ -0,0 +1,287 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"taskpp/core/logic"
+	"taskpp/core/model"
+)
+
+// BoardColumnDTO is one kanban column. ID is "<group_by>:<key>" and is what
+// MoveTask takes; Key is "" for the no-project and untagged columns.
+type BoardColumnDTO struct {
+	ID    string    `json:"id"`
+	Key   string    `json:"key"`
+	Label string    `json:"label"`
+	Tasks []TaskDTO `json:"tasks"`
+}
+
+var boardLabels = map[string]string{
+	"high":            "High",
+	"med":             "Medium",
+	"low":             "Low",
+	logic.DueOverdue:  "Overdue",
+	logic.DueToday:    "Today",
+	logic.DueThisWeek: "This week",
+	logic.DueLater:    "Later",
+	logic.DueNone:     "No date",
+}
+
+// ListTasksGrouped returns JSON-encoded BoardColumnDTO for tasks matching
+// the filter, grouped by "status", "priority", "project", "tag" or "due".
+// Each column lists its tasks in manual order.
+func (c *Core) ListTasksGrouped(filterJSON string, groupBy string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	filter, err := c.decodeTaskFilter(filterJSON)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("decode filter: %v", err))
+	}
+	tasks, err := c.store.ListTasks(filter)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list tasks: %v", err))
+	}
+	wf, err := c.workflow()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	columns, labels, err := c.boardColumns(groupBy, wf)
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	today := logic.Today(time.Now(), filter.Location)
+	grouped, err := logic.GroupBoard(tasks, groupBy, columns, wf, today, filter.Location)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("group tasks: %v", err))
+	}
+	lookup := c.blockerLookup()
+	out := make([]BoardColumnDTO, 0, len(grouped))
+	for _, column := range grouped {
+		dto := BoardColumnDTO{
+			ID:    groupBy + ":" + column.Key,
+			Key:   column.Key,
+			Label: labels(column.Key),
+			Tasks: make([]TaskDTO, 0, len(column.Tasks)),
+		}
+		for _, task := range column.Tasks {
+			dto.Tasks = append(dto.Tasks, blockedTaskDTO(task, lookup))
+		}
+		out = append(out, dto)
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode board: %v", err))
+	}
+	return string(data)
+}
+
+// MoveTask drops a task into a board column (a BoardColumnDTO id) at a
+// zero-based position among every task in that column. The grouping field
+// and rank change together in one "move" event. Status moves follow the
+// workflow transitions; tag moves add the tag, and the untagged column
+// clears all tags. Returns empty string on success.
+func (c *Core) MoveTask(taskID string, column string, position int64) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	defer c.undoGroup()()
+	if taskID == "" {
+		return errorJSON("missing id")
+	}
+	groupBy, key, ok := strings.Cut(column, ":")
+	if !ok {
+		return errorJSON("column must be <group_by>:<key>")
+	}
+	task, err := c.store.GetTask(taskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load task: %v", err))
+	}
+	if task.ID == "" {
+		return errorJSON("task not found")
+	}
+	wf, err := c.workflow()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	now := time.Now().UTC()
+	today := logic.Today(now, c.location)
+	completed, err := c.moveToColumn(&task, groupBy, key, wf, today, now)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("move: %v", err))
+	}
+
+	tasks, err := c.store.ListTasks(model.TaskFilter{})
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list tasks: %v", err))
+	}
+	peers := make([]model.Task, 0)
+	for _, other := range tasks {
+		if other.ID == task.ID {
+			continue
+		}
+		keys, _ := logic.BoardKeys(other, groupBy, wf, today, c.location)
+		for _, k := range keys {
+			if k == key {
+				peers = append(peers, other)
+				break
+			}
+		}
+	}
+	logic.SortSiblings(peers)
+	rank, err := c.rankSlot(peers, int(position), now)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("rebalance column: %v", err))
+	}
+
+	task.Rank = rank
+	task.UpdatedAt = now
+	if err := c.store.UpsertTask(task); err != nil {
+		return errorJSON(fmt.Sprintf("move: %v", err))
+	}
+	if err := c.appendEvent("move", task); err != nil {
+		return errorJSON(fmt.Sprintf("event move: %v", err))
+	}
+	if completed {
+		if err := c.afterCompleted(task); err != nil {
+			return errorJSON(err.Error())
+		}
+	}
+	return ""
+}
+
+// moveToColumn sets the field a board groups by so the task lands in key.
+// It reports whether the move completed the task.
+func (c *Core) moveToColumn(task *model.Task, groupBy, key string, wf model.Workflow, today, now time.Time) (bool, error) {
+	switch groupBy {
+	case logic.BoardByStatus:
+		return moveToState(task, wf, key, now)
+	case logic.BoardByPriority:
+		for _, priority := range logic.FixedColumns(groupBy, wf) {
+			if priority == key {
+				task.Priority = key
+				return false, nil
+			}
+		}
+		return false, fmt.Errorf("invalid priority: %s", key)
+	case logic.BoardByProject:
+		if err := c.checkProjectRef(key); err != nil {
+			return false, err
+		}
+		task.ProjectID = key
+	case logic.BoardByTag:
+		if key == "" {
+			task.Tags = nil
+			return false, nil
+		}
+		tag, err := logic.NormalizeTag(key)
+		if err != nil {
+			return false, err
+		}
+		tags, err := logic.NormalizeTags(append(task.Tags, tag))
+		if err != nil {
+			return false, err
+		}
+		task.Tags = tags
+	case logic.BoardByDue:
+		return false, moveToDueBucket(task, key, today, c.location)
+	default:
+		return false, fmt.Errorf("invalid group_by: %s", groupBy)
+	}
+	return false, nil
+}
+
+// moveToDueBucket picks a due date for the bucket, keeping the current one
+// when it already falls there. This week means the last day before next
+// Monday; later means next Monday.
+func moveToDueBucket(task *model.Task, bucket string, today time.Time, loc *time.Location) error {
+	if logic.DueBucket(*task, today, loc) == bucket {
+		return nil
+	}
+	switch bucket {
+	case logic.DueToday:
+		task.DueDate = today
+	case logic.DueThisWeek:
+		end := logic.NextWeek(today).AddDate(0, 0, -1)
+		if !end.After(today) {
+			return fmt.Errorf("no days left this week")
+		}
+		task.DueDate = end
+	case logic.DueLater:
+		task.DueDate = logic.NextWeek(today)
+	case logic.DueNone:
+		task.DueDate = time.Time{}
+		task.DueTime = ""
+		task.TimeZone = ""
+	case logic.DueOverdue:
+		return fmt.Errorf("cannot move a task into overdue")
+	default:
+		return fmt.Errorf("invalid due bucket: %s", bucket)
+	}
+	return nil
+}
+
+// boardColumns returns the columns a grouping always shows and a labeller
+// for column keys.
+func (c *Core) boardColumns(groupBy string, wf model.Workflow) ([]string, func(string) string, error) {
+	names := make(map[string]string)
+	var columns []string
+	switch groupBy {
+	case logic.BoardByStatus:
+		for _, state := range wf.States {
+			names[state.ID] = state.Name
+		}
+		columns = logic.FixedColumns(groupBy, wf)
+	case logic.BoardByPriority, logic.BoardByDue:
+		columns = logic.FixedColumns(groupBy, wf)
+		names = boardLabels
+	case logic.BoardByProject:
+		projects, err := c.store.ListProjects()
+		if err != nil {
+			return nil, nil, fmt.Errorf("list projects: %w", err)
+		}
+		sort.SliceStable(projects, func(i, j int) bool { return projects[i].Order < projects[j].Order })
+		for _, project := range projects {
+			names[project.ID] = project.Name
+			if !project.Archived {
+				columns = append(columns, project.ID)
+			}
+		}
+		columns = append(columns, "")
+		names[""] = "No project"
+	case logic.BoardByTag:
+		tasks, err := c.store.ListTasks(model.TaskFilter{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("list tasks: %w", err)
+		}
+		seen := make(map[string]bool)
+		for _, task := range tasks {
+			for _, tag := range task.Tags {
+				if !seen[tag] {
+					seen[tag] = true
+					columns = append(columns, tag)
+					names[tag] = tag
+				}
+			}
+		}
+		sort.Strings(columns)
+		columns = append(columns, "")
+		names[""] = "Untagged"
+	default:
+		return nil, nil, fmt.Errorf("invalid group_by: %s", groupBy)
+	}
+	return columns, func(key string) string {
+		if name, ok := names[key]; ok {
+			return name
+		}
+		return key
+	}, nil
+}
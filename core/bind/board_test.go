@@ -0,0 +1,88 @@
+package bind
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func decodeBoard(t *testing.T, payload string) []BoardColumnDTO {
+	t.Helper()
+	var columns []BoardColumnDTO
+	if err := json.Unmarshal([]byte(payload), &columns); err != nil {
+		t.Fatalf("decode board %s: %v", payload, err)
+	}
+	return columns
+}
+
+func columnIDs(column BoardColumnDTO) []string {
+	ids := make([]string, 0, len(column.Tasks))
+	for _, task := range column.Tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func TestBoardGroupingAndMove(t *testing.T) {
+	core := newTestCore(t)
+	first := createTask(t, core, TaskDTO{Title: "First"})
+	second := createTask(t, core, TaskDTO{Title: "Second"})
+	third := createTask(t, core, TaskDTO{Title: "Third"})
+
+	board := decodeBoard(t, core.ListTasksGrouped("", "status"))
+	if len(board) != 3 || board[0].ID != "status:todo" || board[0].Label != "To do" || len(board[0].Tasks) != 3 || len(board[2].Tasks) != 0 {
+		t.Fatalf("unexpected status board: %+v", board)
+	}
+
+	if errStr := core.MoveTask(third.ID, "status:doing", 0); errStr != "" {
+		t.Fatalf("move to doing: %s", errStr)
+	}
+	// New tasks are ranked in creation order, leaving room between them.
+	if errStr := core.MoveTask(third.ID, "status:todo", 1); errStr != "" {
+		t.Fatalf("move back: %s", errStr)
+	}
+	board = decodeBoard(t, core.ListTasksGrouped("", "status"))
+	if got := columnIDs(board[0]); len(got) != 3 || got[0] != first.ID || got[1] != third.ID || got[2] != second.ID {
+		t.Fatalf("expected third between first and second, got %v", got)
+	}
+
+	if errStr := core.MoveTask(first.ID, "status:done", 0); errStr != "" {
+		t.Fatalf("move to done: %s", errStr)
+	}
+	tasks := decodeTasks(t, core.ListTasks(`{"status":"done"}`))
+	if len(tasks) != 1 || tasks[0].ID != first.ID || tasks[0].CompletedAt == "" {
+		t.Fatalf("expected done column to complete the task, got %+v", tasks)
+	}
+
+	if errStr := core.MoveTask(second.ID, "priority:high", 0); errStr != "" {
+		t.Fatalf("move priority: %s", errStr)
+	}
+	board = decodeBoard(t, core.ListTasksGrouped(`{"status":"active"}`, "priority"))
+	if board[0].Label != "High" || len(board[0].Tasks) != 1 || board[0].Tasks[0].ID != second.ID {
+		t.Fatalf("unexpected priority board: %+v", board)
+	}
+	if !hasError(core.MoveTask(second.ID, "priority:urgent", 0)) {
+		t.Fatalf("expected unknown priority to be rejected")
+	}
+
+	if errStr := core.MoveTask(second.ID, "due:today", 0); errStr != "" {
+		t.Fatalf("move due: %s", errStr)
+	}
+	board = decodeBoard(t, core.ListTasksGrouped(`{"status":"active"}`, "due"))
+	if board[1].ID != "due:today" || len(board[1].Tasks) != 1 || board[1].Tasks[0].DueDate == "" {
+		t.Fatalf("expected task due today, got %+v", board)
+	}
+	if !hasError(core.MoveTask(second.ID, "due:overdue", 0)) {
+		t.Fatalf("expected move into overdue to be rejected")
+	}
+
+	if errStr := core.MoveTask(second.ID, "tag:client", 0); errStr != "" {
+		t.Fatalf("move tag: %s", errStr)
+	}
+	board = decodeBoard(t, core.ListTasksGrouped(`{"status":"active"}`, "tag"))
+	if len(board) != 2 || board[0].Key != "client" || board[1].Label != "Untagged" {
+		t.Fatalf("unexpected tag board: %+v", board)
+	}
+	if !hasError(core.ListTasksGrouped("", "colour")) {
+		t.Fatalf("expected invalid grouping to fail")
+	}
+}
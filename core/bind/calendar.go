@@ -0,0 +1,219 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"taskpp/core/logic"
+	"taskpp/core/model"
+)
+
+// ExceptionDTO moves one occurrence of a recurring series. Date is the day
+// the rule gives the occurrence; MoveTo and DueTime are where it lands.
+type ExceptionDTO struct {
+	Date    string `json:"date"`
+	MoveTo  string `json:"move_to"`
+	DueTime string `json:"due_time"`
+}
+
+// CalendarDayDTO lists the occurrences falling on one day.
+type CalendarDayDTO struct {
+	Date        string          `json:"date"`
+	Occurrences []OccurrenceDTO `json:"occurrences"`
+}
+
+// OccurrenceDTO places a task on a calendar day. Virtual occurrences are
+// future instances of a recurring series; their ID is
+// "<task-id>@<occurrence_date>" and is what MoveToDate takes.
+type OccurrenceDTO struct {
+	ID             string  `json:"id"`
+	Task           TaskDTO `json:"task"`
+	Date           string  `json:"date"`
+	OccurrenceDate string  `json:"occurrence_date"`
+	Virtual        bool    `json:"virtual"`
+}
+
+// CalendarRange returns JSON-encoded CalendarDayDTO for every day from start
+// to end inclusive, as seen from zone (the device zone when empty).
+// Recurring tasks are expanded into virtual occurrences.
+func (c *Core) CalendarRange(start string, end string, zone string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	from, err := parseDate(c.resolveDate(start))
+	if err != nil || from.IsZero() {
+		return errorJSON(fmt.Sprintf("invalid start: %s", start))
+	}
+	to, err := parseDate(c.resolveDate(end))
+	if err != nil || to.IsZero() {
+		return errorJSON(fmt.Sprintf("invalid end: %s", end))
+	}
+	if to.Before(from) {
+		return errorJSON("end before start")
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > logic.MaxCalendarDays {
+		return errorJSON(fmt.Sprintf("range exceeds %d days", logic.MaxCalendarDays))
+	}
+	loc := c.location
+	if zone != "" {
+		if loc, err = time.LoadLocation(zone); err != nil {
+			return errorJSON(fmt.Sprintf("invalid zone: %s", zone))
+		}
+	}
+	tasks, err := c.store.ListTasks(model.TaskFilter{Location: loc})
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list tasks: %v", err))
+	}
+	occurrences := logic.Occurrences(tasks, from, to, logic.Today(time.Now(), loc), loc)
+	lookup := c.blockerLookup()
+	out := make([]CalendarDayDTO, 0)
+	index := make(map[string]int)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		index[formatDate(day)] = len(out)
+		out = append(out, CalendarDayDTO{Date: formatDate(day), Occurrences: make([]OccurrenceDTO, 0)})
+	}
+	for _, occurrence := range occurrences {
+		dto := OccurrenceDTO{
+			ID:             occurrence.Task.ID,
+			Task:           blockedTaskDTO(occurrence.Task, lookup),
+			Date:           formatDate(occurrence.Day),
+			OccurrenceDate: formatDate(occurrence.SeriesDate),
+			Virtual:        occurrence.Virtual,
+		}
+		if occurrence.Virtual {
+			dto.ID = occurrence.Task.ID + "@" + dto.OccurrenceDate
+		}
+		day := &out[index[dto.Date]]
+		day.Occurrences = append(day.Occurrences, dto)
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode calendar: %v", err))
+	}
+	return string(data)
+}
+
+// MoveToDate reschedules a task or calendar occurrence to date. dueTime is
+// "HH:MM", "" to keep the current time, or "none" to make it all-day.
+// Moving a virtual occurrence ("<task-id>@<date>") records an exception on
+// the series instead of touching the current instance; moving a recurring
+// instance keeps the series on its rule days.
+func (c *Core) MoveToDate(id string, date string, dueTime string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if id == "" {
+		return errorJSON("missing id")
+	}
+	taskID, occurrence, virtual := strings.Cut(id, "@")
+	date = c.resolveDate(date)
+	moveTo, err := parseDate(date)
+	if err != nil || moveTo.IsZero() {
+		return errorJSON(fmt.Sprintf("invalid date: %s", date))
+	}
+	task, err := c.store.GetTask(taskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load task: %v", err))
+	}
+	if task.ID == "" {
+		return errorJSON("task not found")
+	}
+	var seriesDate time.Time
+	if virtual {
+		seriesDate, err = parseDate(occurrence)
+		if err != nil || !logic.IsSeriesDate(task, seriesDate) ||
+			!seriesDate.After(task.DueDate) || !seriesDate.After(logic.Today(time.Now(), c.location)) {
+			return errorJSON(fmt.Sprintf("no occurrence on %s", occurrence))
+		}
+		if logic.IsDone(task) {
+			return errorJSON("series has ended")
+		}
+	}
+	switch dueTime {
+	case "":
+		dueTime = task.DueTime
+		if exception, ok := logic.ExceptionFor(task, seriesDate); virtual && ok {
+			dueTime = exception.DueTime
+		}
+	case "none":
+		dueTime = ""
+	}
+	timeZone := task.TimeZone
+	if dueTime == "" {
+		timeZone = ""
+	}
+	if err := logic.ValidateDue(date, dueTime, timeZone); err != nil {
+		return errorJSON(fmt.Sprintf("validate due: %v", err))
+	}
+	if virtual {
+		previous := task.Exceptions
+		var exceptions []model.RecurrenceException
+		for _, exception := range task.Exceptions {
+			if !exception.Date.Equal(seriesDate) {
+				exceptions = append(exceptions, exception)
+			}
+		}
+		if !moveTo.Equal(seriesDate) || dueTime != task.DueTime {
+			exceptions = append(exceptions, model.RecurrenceException{Date: seriesDate, MoveTo: moveTo, DueTime: dueTime})
+		}
+		task.Exceptions = exceptions
+		if err := logic.ValidateExceptions(task, previous); err != nil {
+			return errorJSON(fmt.Sprintf("validate exception: %v", err))
+		}
+	} else {
+		if logic.IsRecurring(task.Recurrence) && task.OccurrenceDate.IsZero() {
+			task.OccurrenceDate = task.DueDate
+		}
+		task.DueDate = moveTo
+		task.DueTime = dueTime
+		task.TimeZone = timeZone
+	}
+	task.UpdatedAt = time.Now().UTC()
+	if err := c.store.UpsertTask(task); err != nil {
+		return errorJSON(fmt.Sprintf("move task: %v", err))
+	}
+	if err := c.appendEvent("move_date", task); err != nil {
+		return errorJSON(fmt.Sprintf("event move date: %v", err))
+	}
+	return ""
+}
+
+func exceptionsToDTO(exceptions []model.RecurrenceException) []ExceptionDTO {
+	if len(exceptions) == 0 {
+		return nil
+	}
+	out := make([]ExceptionDTO, 0, len(exceptions))
+	for _, exception := range exceptions {
+		out = append(out, ExceptionDTO{
+			Date:    formatDate(exception.Date),
+			MoveTo:  formatDate(exception.MoveTo),
+			DueTime: exception.DueTime,
+		})
+	}
+	return out
+}
+
+func dtoToExceptions(dtos []ExceptionDTO) ([]model.RecurrenceException, error) {
+	if len(dtos) == 0 {
+		return nil, nil
+	}
+	out := make([]model.RecurrenceException, 0, len(dtos))
+	for _, dto := range dtos {
+		date, err := parseDate(dto.Date)
+		if err != nil {
+			return nil, fmt.Errorf("parse exception date: %w", err)
+		}
+		moveTo, err := parseDate(dto.MoveTo)
+		if err != nil {
+			return nil, fmt.Errorf("parse exception move_to: %w", err)
+		}
+		out = append(out, model.RecurrenceException{Date: date, MoveTo: moveTo, DueTime: dto.DueTime})
+	}
+	return out, nil
+}
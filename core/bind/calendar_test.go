@@ -0,0 +1,110 @@
+package bind
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func decodeCalendar(t *testing.T, out string) []CalendarDayDTO {
+	t.Helper()
+	var days []CalendarDayDTO
+	if err := json.Unmarshal([]byte(out), &days); err != nil {
+		t.Fatalf("decode calendar: %v (%s)", err, out)
+	}
+	return days
+}
+
+func TestCalendarRangeAndMoveVirtualOccurrence(t *testing.T) {
+	core := newTestCore(t)
+	task := createTask(t, core, TaskDTO{Title: "Standup", DueDate: "2030-03-02", Recurrence: "RRULE:FREQ=WEEKLY"})
+
+	days := decodeCalendar(t, core.CalendarRange("2030-03-01", "2030-03-16", "UTC"))
+	if len(days) != 16 || len(days[1].Occurrences) != 1 || days[1].Occurrences[0].Virtual {
+		t.Fatalf("expected real occurrence on 2030-03-02, got %+v", days)
+	}
+	virtual := days[8].Occurrences
+	if len(virtual) != 1 || !virtual[0].Virtual || virtual[0].ID != task.ID+"@2030-03-09" {
+		t.Fatalf("expected virtual occurrence on 2030-03-09, got %+v", virtual)
+	}
+
+	if errStr := core.MoveToDate(virtual[0].ID, "2030-03-11", "09:00"); errStr != "" {
+		t.Fatalf("move occurrence: %s", errStr)
+	}
+	days = decodeCalendar(t, core.CalendarRange("2030-03-01", "2030-03-16", "UTC"))
+	if len(days[8].Occurrences) != 0 || len(days[10].Occurrences) != 1 {
+		t.Fatalf("expected occurrence moved to 2030-03-11, got %+v", days)
+	}
+	moved := days[10].Occurrences[0]
+	if moved.OccurrenceDate != "2030-03-09" || moved.Task.DueTime != "09:00" {
+		t.Fatalf("unexpected moved occurrence: %+v", moved)
+	}
+
+	// Moving the current instance keeps the series on Mondays.
+	if errStr := core.MoveToDate(task.ID, "2030-03-04", ""); errStr != "" {
+		t.Fatalf("move instance: %s", errStr)
+	}
+	days = decodeCalendar(t, core.CalendarRange("2030-03-01", "2030-03-16", "UTC"))
+	if len(days[3].Occurrences) != 1 || len(days[15].Occurrences) != 1 {
+		t.Fatalf("expected instance on 2030-03-04 and series on 2030-03-16, got %+v", days)
+	}
+
+	if out := core.MoveToDate(task.ID+"@2030-03-10", "2030-03-12", ""); !hasError(out) {
+		t.Fatalf("expected error for non-series date, got %s", out)
+	}
+	if out := core.CalendarRange("2030-03-16", "2030-03-01", ""); !hasError(out) {
+		t.Fatalf("expected error for inverted range, got %s", out)
+	}
+}
+
+func TestCompletingSeriesAppliesException(t *testing.T) {
+	core := newTestCore(t)
+	today := time.Now().UTC()
+	task := createTask(t, core, TaskDTO{Title: "Review", DueDate: today.Format("2006-01-02"), Recurrence: "RRULE:FREQ=WEEKLY"})
+	next := today.AddDate(0, 0, 7).Format("2006-01-02")
+	movedTo := today.AddDate(0, 0, 9).Format("2006-01-02")
+	if errStr := core.MoveToDate(task.ID+"@"+next, movedTo, ""); errStr != "" {
+		t.Fatalf("move occurrence: %s", errStr)
+	}
+	if errStr := core.SetCompleted(task.ID, true); errStr != "" {
+		t.Fatalf("complete: %s", errStr)
+	}
+	tasks := decodeTasks(t, core.ListTasks(`{"status":"active"}`))
+	if len(tasks) != 1 {
+		t.Fatalf("expected next instance, got %+v", tasks)
+	}
+	instance := tasks[0]
+	if instance.DueDate != movedTo || instance.OccurrenceDate != next || len(instance.Exceptions) != 0 {
+		t.Fatalf("expected instance to take the exception, got %+v", instance)
+	}
+}
+
+func TestUpdateTaskValidatesExceptions(t *testing.T) {
+	core := newTestCore(t)
+	today := time.Now().UTC()
+	task := createTask(t, core, TaskDTO{Title: "Review", DueDate: today.Format("2006-01-02"), Recurrence: "daily"})
+
+	task.Exceptions = []ExceptionDTO{{Date: "9999-01-01", MoveTo: today.AddDate(0, 0, 1).Format("2006-01-02")}}
+	payload, _ := json.Marshal(task)
+	if out := core.UpdateTask(string(payload)); !hasError(out) {
+		t.Fatalf("expected an exception far ahead to be rejected, got %s", out)
+	}
+	next := today.AddDate(0, 0, 1).Format("2006-01-02")
+	task.Exceptions = []ExceptionDTO{{Date: next, MoveTo: today.AddDate(0, 0, 2).Format("2006-01-02")}}
+	payload, _ = json.Marshal(task)
+	if out := core.UpdateTask(string(payload)); hasError(out) {
+		t.Fatalf("update with a valid exception: %s", out)
+	}
+
+	// An overdue series shows no rule days that completing it would skip.
+	started := today.AddDate(0, 0, -3).Format("2006-01-02")
+	overdue := createTask(t, core, TaskDTO{Title: "Overdue", DueDate: started, Recurrence: "daily"})
+	days := decodeCalendar(t, core.CalendarRange(started, today.Format("2006-01-02"), "UTC"))
+	for _, day := range days {
+		for _, occurrence := range day.Occurrences {
+			if occurrence.Task.ID == overdue.ID && occurrence.Virtual {
+				t.Fatalf("expected no past virtual occurrences, got %+v", occurrence)
+			}
+		}
+	}
+}
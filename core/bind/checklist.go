@@ -0,0 +1,260 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"taskpp/core/logic"
+	"taskpp/core/model"
+)
+
+// ChecklistItemDTO is a bind-safe checklist item.
+type ChecklistItemDTO struct {
+	ID        string `json:"id"`
+	Text      string `json:"text"`
+	Checked   bool   `json:"checked"`
+	Order     int64  `json:"order"`
+	UpdatedAt string `json:"updated_at"`
+	OrderedAt string `json:"ordered_at"`
+	AddedAt   string `json:"added_at"`
+}
+
+// AddChecklistItem appends an item to a task's checklist and returns the
+// ChecklistItemDTO JSON.
+func (c *Core) AddChecklistItem(taskID string, text string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := logic.ValidateChecklistText(text); err != nil {
+		return errorJSON(fmt.Sprintf("validate checklist item: %v", err))
+	}
+	var added model.ChecklistItem
+	errStr := c.editChecklist(taskID, "checklist_add", func(task *model.Task, now time.Time) error {
+		order := int64(0)
+		for _, item := range task.Checklist {
+			if item.Order >= order {
+				order = item.Order + 1
+			}
+		}
+		added = model.ChecklistItem{ID: uuid.NewString(), Text: text, Order: order, UpdatedAt: now, OrderedAt: now, AddedAt: now}
+		task.Checklist = append(task.Checklist, added)
+		return nil
+	})
+	if errStr != "" {
+		return errStr
+	}
+	out, err := json.Marshal(checklistToDTO([]model.ChecklistItem{added})[0])
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode checklist item: %v", err))
+	}
+	return string(out)
+}
+
+// ToggleChecklistItem flips an item's checked state. Returns empty string on
+// success.
+func (c *Core) ToggleChecklistItem(taskID string, itemID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.editChecklist(taskID, "checklist_toggle", func(task *model.Task, now time.Time) error {
+		for i := range task.Checklist {
+			if task.Checklist[i].ID == itemID {
+				task.Checklist[i].Checked = !task.Checklist[i].Checked
+				task.Checklist[i].UpdatedAt = now
+				return nil
+			}
+		}
+		return fmt.Errorf("checklist item not found")
+	})
+}
+
+// ReorderChecklist orders a task's checklist by itemIDsJSON, a JSON array of
+// item ids. Items left out keep their relative order after the listed ones.
+// Returns empty string on success.
+func (c *Core) ReorderChecklist(taskID string, itemIDsJSON string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var ids []string
+	if err := json.Unmarshal([]byte(itemIDsJSON), &ids); err != nil {
+		return errorJSON(fmt.Sprintf("decode item ids: %v", err))
+	}
+	return c.editChecklist(taskID, "checklist_reorder", func(task *model.Task, now time.Time) error {
+		position := make(map[string]int, len(ids))
+		for i, id := range ids {
+			position[id] = i
+		}
+		logic.SortChecklist(task.Checklist)
+		ordered := make([]model.ChecklistItem, 0, len(task.Checklist))
+		rest := make([]model.ChecklistItem, 0)
+		for _, id := range ids {
+			for _, item := range task.Checklist {
+				if item.ID == id {
+					ordered = append(ordered, item)
+				}
+			}
+		}
+		for _, item := range task.Checklist {
+			if _, ok := position[item.ID]; !ok {
+				rest = append(rest, item)
+			}
+		}
+		ordered = append(ordered, rest...)
+		for i := range ordered {
+			if ordered[i].Order != int64(i) {
+				ordered[i].Order = int64(i)
+				ordered[i].OrderedAt = now
+			}
+		}
+		task.Checklist = ordered
+		return nil
+	})
+}
+
+// RemoveChecklistItem deletes an item from a task's checklist. Returns empty
+// string on success.
+func (c *Core) RemoveChecklistItem(taskID string, itemID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.editChecklist(taskID, "checklist_remove", func(task *model.Task, now time.Time) error {
+		for i, item := range task.Checklist {
+			if item.ID == itemID {
+				task.Checklist = append(task.Checklist[:i], task.Checklist[i+1:]...)
+				task.ChecklistRemoved = append(task.ChecklistRemoved, itemID)
+				if task.ChecklistRemovedAt == nil {
+					task.ChecklistRemovedAt = make(map[string]time.Time)
+				}
+				task.ChecklistRemovedAt[itemID] = now
+				return nil
+			}
+		}
+		return fmt.Errorf("checklist item not found")
+	})
+}
+
+func (c *Core) editChecklist(taskID string, eventType string, mutate func(task *model.Task, now time.Time) error) string {
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if taskID == "" {
+		return errorJSON("missing id")
+	}
+	task, err := c.store.GetTask(taskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load task: %v", err))
+	}
+	if task.ID == "" {
+		return errorJSON("task not found")
+	}
+	now := time.Now().UTC()
+	if err := mutate(&task, now); err != nil {
+		return errorJSON(fmt.Sprintf("%s: %v", eventType, err))
+	}
+	logic.SortChecklist(task.Checklist)
+	task.UpdatedAt = now
+	if err := c.store.UpsertTask(task); err != nil {
+		return errorJSON(fmt.Sprintf("update task: %v", err))
+	}
+	if err := c.appendEvent(eventType, task); err != nil {
+		return errorJSON(fmt.Sprintf("event %s: %v", eventType, err))
+	}
+	return ""
+}
+
+// resetChecklist unticks every item for the next instance of a recurring task.
+func resetChecklist(items []model.ChecklistItem, now time.Time) []model.ChecklistItem {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]model.ChecklistItem, len(items))
+	for i, item := range items {
+		item.Checked = false
+		item.UpdatedAt = now
+		out[i] = item
+	}
+	return out
+}
+
+func checklistToDTO(items []model.ChecklistItem) []ChecklistItemDTO {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]ChecklistItemDTO, 0, len(items))
+	for _, item := range items {
+		out = append(out, ChecklistItemDTO{
+			ID:        item.ID,
+			Text:      item.Text,
+			Checked:   item.Checked,
+			Order:     item.Order,
+			UpdatedAt: formatTime(item.UpdatedAt),
+			OrderedAt: formatTime(item.OrderedAt),
+			AddedAt:   formatTime(item.AddedAt),
+		})
+	}
+	return out
+}
+
+func dtoToChecklist(dtos []ChecklistItemDTO) ([]model.ChecklistItem, error) {
+	if len(dtos) == 0 {
+		return nil, nil
+	}
+	out := make([]model.ChecklistItem, 0, len(dtos))
+	for _, dto := range dtos {
+		if err := logic.ValidateChecklistText(dto.Text); err != nil {
+			return nil, err
+		}
+		updatedAt, err := parseTime(dto.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("checklist updated_at: %w", err)
+		}
+		orderedAt, err := parseTime(dto.OrderedAt)
+		if err != nil {
+			return nil, fmt.Errorf("checklist ordered_at: %w", err)
+		}
+		addedAt, err := parseTime(dto.AddedAt)
+		if err != nil {
+			return nil, fmt.Errorf("checklist added_at: %w", err)
+		}
+		id := dto.ID
+		if id == "" {
+			id = uuid.NewString()
+		}
+		out = append(out, model.ChecklistItem{
+			ID:        id,
+			Text:      dto.Text,
+			Checked:   dto.Checked,
+			Order:     dto.Order,
+			UpdatedAt: updatedAt,
+			OrderedAt: orderedAt,
+			AddedAt:   addedAt,
+		})
+	}
+	logic.SortChecklist(out)
+	return out, nil
+}
+
+func removedAtToDTO(removedAt map[string]time.Time) map[string]string {
+	if len(removedAt) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(removedAt))
+	for id, at := range removedAt {
+		out[id] = formatTime(at)
+	}
+	return out
+}
+
+func dtoToRemovedAt(dtos map[string]string) (map[string]time.Time, error) {
+	if len(dtos) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]time.Time, len(dtos))
+	for id, value := range dtos {
+		at, err := parseTime(value)
+		if err != nil {
+			return nil, fmt.Errorf("checklist removed_at: %w", err)
+		}
+		out[id] = at
+	}
+	return out, nil
+}
@@ -0,0 +1,59 @@
+package bind
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestChecklistConcurrentTogglesMerge(t *testing.T) {
+	phone := newTestCore(t)
+	task := createTask(t, phone, TaskDTO{Title: "Pack"})
+	var items []ChecklistItemDTO
+	for _, text := range []string{"Socks", "Charger", "Hat"} {
+		var item ChecklistItemDTO
+		if err := json.Unmarshal([]byte(phone.AddChecklistItem(task.ID, text)), &item); err != nil {
+			t.Fatalf("decode item: %v", err)
+		}
+		items = append(items, item)
+	}
+
+	laptop := newTestCore(t)
+	shareKeys(t, phone, laptop)
+	if errStr := laptop.ImportEvents(phone.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import: %s", errStr)
+	}
+
+	if errStr := phone.ToggleChecklistItem(task.ID, items[0].ID); errStr != "" {
+		t.Fatalf("toggle: %s", errStr)
+	}
+	if errStr := laptop.ToggleChecklistItem(task.ID, items[1].ID); errStr != "" {
+		t.Fatalf("toggle: %s", errStr)
+	}
+	if errStr := laptop.RemoveChecklistItem(task.ID, items[2].ID); errStr != "" {
+		t.Fatalf("remove: %s", errStr)
+	}
+	reorder, _ := json.Marshal([]string{items[1].ID, items[0].ID})
+	if errStr := laptop.ReorderChecklist(task.ID, string(reorder)); errStr != "" {
+		t.Fatalf("reorder: %s", errStr)
+	}
+
+	if errStr := phone.ImportEvents(laptop.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import on phone: %s", errStr)
+	}
+	if errStr := laptop.ImportEvents(phone.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import on laptop: %s", errStr)
+	}
+	for name, core := range map[string]*Core{"phone": phone, "laptop": laptop} {
+		tasks := decodeTasks(t, core.ListTasks(""))
+		if len(tasks) != 1 {
+			t.Fatalf("%s: expected one task, got %+v", name, tasks)
+		}
+		checklist := tasks[0].Checklist
+		if len(checklist) != 2 || checklist[0].ID != items[1].ID {
+			t.Fatalf("%s: expected reordered checklist without removed item, got %+v", name, checklist)
+		}
+		if !checklist[0].Checked || !checklist[1].Checked {
+			t.Fatalf("%s: expected both toggles to survive, got %+v", name, checklist)
+		}
+	}
+}
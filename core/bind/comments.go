@@ -0,0 +1,187 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"taskpp/core/logic"
+	"taskpp/core/model"
+	"taskpp/core/sync"
+)
+
+// CommentDTO is a bind-safe comment representation.
+type CommentDTO struct {
+	ID        string `json:"id"`
+	TaskID    string `json:"task_id"`
+	DeviceID  string `json:"device_id"`
+	Text      string `json:"text"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+	Deleted   bool   `json:"deleted"`
+}
+
+// AddComment appends a comment to a task's thread and returns CommentDTO JSON.
+func (c *Core) AddComment(taskID string, text string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if taskID == "" {
+		return errorJSON("missing id")
+	}
+	if err := logic.ValidateComment(text); err != nil {
+		return errorJSON(fmt.Sprintf("validate comment: %v", err))
+	}
+	task, err := c.store.GetTask(taskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load task: %v", err))
+	}
+	if task.ID == "" {
+		return errorJSON("task not found")
+	}
+	deviceID, err := c.localDeviceID()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	now := time.Now().UTC()
+	comment := model.Comment{
+		ID:        uuid.NewString(),
+		TaskID:    taskID,
+		DeviceID:  deviceID,
+		Text:      text,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if errStr := c.saveComment(comment, "comment_add"); errStr != "" {
+		return errStr
+	}
+	out, err := json.Marshal(commentToDTO(comment))
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode comment: %v", err))
+	}
+	return string(out)
+}
+
+// ListComments returns a task's comments as JSON-encoded CommentDTO, oldest
+// first. Deleted comments are left out.
+func (c *Core) ListComments(taskID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	comments, err := c.store.ListComments(taskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list comments: %v", err))
+	}
+	out := make([]CommentDTO, 0, len(comments))
+	for _, comment := range comments {
+		if !comment.Deleted {
+			out = append(out, commentToDTO(comment))
+		}
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode comments: %v", err))
+	}
+	return string(data)
+}
+
+// EditComment replaces a comment's text. Only the authoring device may edit.
+// Returns empty string on success.
+func (c *Core) EditComment(commentID string, text string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := logic.ValidateComment(text); err != nil {
+		return errorJSON(fmt.Sprintf("validate comment: %v", err))
+	}
+	return c.editComment(commentID, "comment_edit", func(comment *model.Comment) {
+		comment.Text = text
+	})
+}
+
+// DeleteComment removes a comment from its thread. Only the authoring device
+// may delete. Returns empty string on success.
+func (c *Core) DeleteComment(commentID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.editComment(commentID, "comment_delete", func(comment *model.Comment) {
+		comment.Text = ""
+		comment.Deleted = true
+	})
+}
+
+func (c *Core) editComment(commentID string, eventType string, mutate func(comment *model.Comment)) string {
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if commentID == "" {
+		return errorJSON("missing id")
+	}
+	comment, err := c.store.GetComment(commentID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load comment: %v", err))
+	}
+	if comment.ID == "" || comment.Deleted {
+		return errorJSON("comment not found")
+	}
+	deviceID, err := c.localDeviceID()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	if comment.DeviceID != deviceID {
+		return errorJSON("comment belongs to another device")
+	}
+	mutate(&comment)
+	comment.UpdatedAt = time.Now().UTC()
+	return c.saveComment(comment, eventType)
+}
+
+func (c *Core) saveComment(comment model.Comment, eventType string) string {
+	if err := c.store.UpsertComment(comment); err != nil {
+		return errorJSON(fmt.Sprintf("save comment: %v", err))
+	}
+	if err := c.appendPayloadEvent(eventType, commentToDTO(comment)); err != nil {
+		return errorJSON(fmt.Sprintf("event %s: %v", eventType, err))
+	}
+	return ""
+}
+
+func (c *Core) applyCommentEvent(event model.Event) error {
+	var ref CommentDTO
+	_ = json.Unmarshal(event.Payload, &ref)
+	if ref.ID == "" {
+		return fmt.Errorf("missing comment id in payload")
+	}
+	comment, err := c.store.GetComment(ref.ID)
+	if err != nil {
+		return fmt.Errorf("get comment: %w", err)
+	}
+	updated, changed, _, err := sync.ApplyCommentEvent(comment, event)
+	if err != nil {
+		return fmt.Errorf("apply comment event: %w", err)
+	}
+	if !changed {
+		return nil
+	}
+	if err := c.store.UpsertComment(updated); err != nil {
+		return fmt.Errorf("upsert comment: %w", err)
+	}
+	return nil
+}
+
+func commentToDTO(comment model.Comment) CommentDTO {
+	return CommentDTO{
+		ID:        comment.ID,
+		TaskID:    comment.TaskID,
+		DeviceID:  comment.DeviceID,
+		Text:      comment.Text,
+		CreatedAt: formatTime(comment.CreatedAt),
+		UpdatedAt: formatTime(comment.UpdatedAt),
+		Deleted:   comment.Deleted,
+	}
+}
@@ -0,0 +1,52 @@
+package bind
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCommentThreadSyncAndAuthorship(t *testing.T) {
+	phone := newTestCore(t)
+	task := createTask(t, phone, TaskDTO{Title: "Renew passport"})
+
+	var first CommentDTO
+	if err := json.Unmarshal([]byte(phone.AddComment(task.ID, "Booked photo")), &first); err != nil {
+		t.Fatalf("decode comment: %v", err)
+	}
+	second := phone.AddComment(task.ID, "Form sent")
+	if hasError(second) {
+		t.Fatalf("add comment: %s", second)
+	}
+	if errStr := phone.EditComment(first.ID, "Booked photo for Friday"); errStr != "" {
+		t.Fatalf("edit: %s", errStr)
+	}
+
+	laptop := newTestCore(t)
+	shareKeys(t, phone, laptop)
+	if errStr := laptop.ImportEvents(phone.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import: %s", errStr)
+	}
+	var comments []CommentDTO
+	if err := json.Unmarshal([]byte(laptop.ListComments(task.ID)), &comments); err != nil {
+		t.Fatalf("decode comments: %v", err)
+	}
+	if len(comments) != 2 || comments[0].Text != "Booked photo for Friday" {
+		t.Fatalf("expected synced thread, got %+v", comments)
+	}
+	if !hasError(laptop.EditComment(first.ID, "Not mine")) {
+		t.Fatalf("expected edit from another device to be rejected")
+	}
+
+	if errStr := phone.DeleteComment(first.ID); errStr != "" {
+		t.Fatalf("delete: %s", errStr)
+	}
+	if errStr := laptop.ImportEvents(phone.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import delete: %s", errStr)
+	}
+	if err := json.Unmarshal([]byte(laptop.ListComments(task.ID)), &comments); err != nil {
+		t.Fatalf("decode comments: %v", err)
+	}
+	if len(comments) != 1 || comments[0].Text != "Form sent" {
+		t.Fatalf("expected deleted comment to disappear, got %+v", comments)
+	}
+}
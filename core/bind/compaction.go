@@ -0,0 +1,361 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"taskpp/core/model"
+	"taskpp/core/sync"
+)
+
+// CompactionDTO reports what Compact did.
+type CompactionDTO struct {
+	Cursor    model.Cursor `json:"cursor"`
+	Entries   int          `json:"entries"`
+	Pruned    int          `json:"pruned"`
+	Remaining int          `json:"remaining"`
+}
+
+// SnapshotDTO carries an encrypted snapshot between devices. The cursor is
+// repeated in the clear so a peer can tell which events it still needs.
+type SnapshotDTO struct {
+	Cursor    model.Cursor `json:"cursor"`
+	CreatedAt string       `json:"created_at"`
+	Payload   []byte       `json:"payload"`
+}
+
+// Compact snapshots the current state at the cursor of every event seen so
+// far and prunes the events that the snapshot covers and every peer device
+// has acknowledged (see AckEvents). Returns JSON-encoded CompactionDTO.
+func (c *Core) Compact() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return errorJSON("keys not unlocked")
+	}
+	events, err := c.store.ListEventsSince(0)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list events: %v", err))
+	}
+	snapshot, entries, err := c.sealSnapshot(events)
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	if err := c.store.SaveSnapshot(snapshot); err != nil {
+		return errorJSON(fmt.Sprintf("save snapshot: %v", err))
+	}
+
+	devices, err := c.store.ListDevices()
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list devices: %v", err))
+	}
+	acks := make(map[string]model.Cursor, len(devices))
+	for _, device := range devices {
+		acks[device.ID] = device.Acked
+		if device.Revoked {
+			acks[device.ID] = snapshot.Cursor
+		}
+	}
+	localID, err := c.localDeviceID()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	pruned := sync.Prunable(events, snapshot.Cursor, localID, acks)
+	if err := c.store.DeleteEvents(pruned); err != nil {
+		return errorJSON(fmt.Sprintf("prune events: %v", err))
+	}
+	data, err := json.Marshal(CompactionDTO{
+		Cursor:    snapshot.Cursor,
+		Entries:   entries,
+		Pruned:    len(pruned),
+		Remaining: len(events) - len(pruned),
+	})
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode compaction: %v", err))
+	}
+	return string(data)
+}
+
+// ExportSnapshot returns the latest snapshot as JSON-encoded SnapshotDTO.
+func (c *Core) ExportSnapshot() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	snapshot, err := c.store.GetSnapshot()
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load snapshot: %v", err))
+	}
+	if len(snapshot.Payload) == 0 {
+		return errorJSON("no snapshot; run Compact first")
+	}
+	data, err := json.Marshal(SnapshotDTO{
+		Cursor:    snapshot.Cursor,
+		CreatedAt: formatTime(snapshot.CreatedAt),
+		Payload:   snapshot.Payload,
+	})
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode snapshot: %v", err))
+	}
+	return string(data)
+}
+
+// ImportSnapshot bootstraps a fresh device from a SnapshotDTO. Afterwards
+// ImportEvents only needs the tail: events the snapshot covers are skipped.
+// Returns empty string on success.
+func (c *Core) ImportSnapshot(snapshotJSON string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return errorJSON("keys not unlocked")
+	}
+	var dto SnapshotDTO
+	if err := json.Unmarshal([]byte(snapshotJSON), &dto); err != nil {
+		return errorJSON(fmt.Sprintf("decode snapshot: %v", err))
+	}
+	if err := c.importSnapshot(dto); err != nil {
+		return errorJSON(err.Error())
+	}
+	return ""
+}
+
+// SyncCursor returns JSON-encoded model.Cursor of every event this device
+// has, through its snapshot or its log.
+func (c *Core) SyncCursor() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	cursor, err := c.syncCursor()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode cursor: %v", err))
+	}
+	return string(data)
+}
+
+// AckEvents records in the device registry that a device has every event
+// up to cursorJSON (its SyncCursor), which lets Compact prune them. A device
+// acknowledges for itself after each sync, which also announces its name
+// and platform; acks for a peer can be relayed the same way. Logged as a
+// "device_update" event. Returns empty string on success.
+func (c *Core) AckEvents(deviceID string, cursorJSON string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return errorJSON("keys not unlocked")
+	}
+	if deviceID == "" {
+		return errorJSON("missing device id")
+	}
+	var cursor model.Cursor
+	if err := json.Unmarshal([]byte(cursorJSON), &cursor); err != nil {
+		return errorJSON(fmt.Sprintf("decode cursor: %v", err))
+	}
+	localID, err := c.localDeviceID()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	stored, err := c.store.GetDevice(deviceID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("get device: %v", err))
+	}
+	device := stored
+	if deviceID == localID {
+		if device, err = c.localDevice(); err != nil {
+			return errorJSON(err.Error())
+		}
+	} else if device.ID == "" {
+		device = model.Device{ID: deviceID, CreatedAt: time.Now().UTC()}
+	}
+	acked := sync.MergeCursor(device.Acked, cursor)
+	if stored.ID != "" && device.Name == stored.Name && reflect.DeepEqual(acked, stored.Acked) {
+		return ""
+	}
+	device.Acked = acked
+	if err := c.saveDevice(device, "device_update"); err != nil {
+		return errorJSON(err.Error())
+	}
+	return ""
+}
+
+// sealSnapshot seals the current state at the cursor of the snapshot and
+// events so far. It also returns the number of entries.
+func (c *Core) sealSnapshot(events []model.Event) (model.Snapshot, int, error) {
+	previous, err := c.store.GetSnapshot()
+	if err != nil {
+		return model.Snapshot{}, 0, fmt.Errorf("load snapshot: %w", err)
+	}
+	entries, err := c.snapshotEntries()
+	if err != nil {
+		return model.Snapshot{}, 0, err
+	}
+	body := sync.SnapshotBody{
+		Cursor:    sync.CursorOf(previous.Cursor, events),
+		CreatedAt: formatTime(time.Now().UTC()),
+		Entries:   entries,
+	}
+	snapshot, err := sync.SealSnapshot(body, c.keys)
+	if err != nil {
+		return model.Snapshot{}, 0, err
+	}
+	return snapshot, len(entries), nil
+}
+
+// importSnapshot applies a snapshot to an empty device and keeps it as the
+// local snapshot.
+func (c *Core) importSnapshot(dto SnapshotDTO) error {
+	createdAt, err := parseTime(dto.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("parse created_at: %w", err)
+	}
+	events, err := c.store.ListEventsSince(0)
+	if err != nil {
+		return fmt.Errorf("list events: %w", err)
+	}
+	current, err := c.store.GetSnapshot()
+	if err != nil {
+		return fmt.Errorf("load snapshot: %w", err)
+	}
+	if len(events) > 0 || len(current.Payload) > 0 {
+		return fmt.Errorf("snapshots can only bootstrap an empty device")
+	}
+	snapshot := model.Snapshot{Cursor: dto.Cursor, CreatedAt: createdAt, Payload: dto.Payload}
+	body, err := sync.OpenSnapshot(snapshot, c.keys)
+	if err != nil {
+		return err
+	}
+	entries, err := sync.SnapshotEvents(body)
+	if err != nil {
+		return err
+	}
+	for _, event := range entries {
+		if err := c.applyEvent(event); err != nil {
+			return fmt.Errorf("apply snapshot: %w", err)
+		}
+	}
+	if err := c.store.SaveSnapshot(snapshot); err != nil {
+		return fmt.Errorf("save snapshot: %w", err)
+	}
+	return nil
+}
+
+// syncCursor merges the snapshot cursor with the cursor of the log.
+func (c *Core) syncCursor() (model.Cursor, error) {
+	events, err := c.store.ListEventsSince(0)
+	if err != nil {
+		return nil, fmt.Errorf("list events: %w", err)
+	}
+	snapshot, err := c.store.GetSnapshot()
+	if err != nil {
+		return nil, fmt.Errorf("load snapshot: %w", err)
+	}
+	return sync.CursorOf(snapshot.Cursor, events), nil
+}
+
+// snapshotEntries lists the current state as event-form entries, ordered
+// so every reference resolves while bootstrapping.
+func (c *Core) snapshotEntries() ([]sync.SnapshotEntry, error) {
+	var entries []sync.SnapshotEntry
+	add := func(eventType string, dto any) error {
+		payload, err := json.Marshal(dto)
+		if err != nil {
+			return fmt.Errorf("encode snapshot entry: %w", err)
+		}
+		entries = append(entries, sync.SnapshotEntry{Type: eventType, Payload: payload})
+		return nil
+	}
+	devices, err := c.store.ListDevices()
+	if err != nil {
+		return nil, fmt.Errorf("list devices: %w", err)
+	}
+	for _, device := range devices {
+		if err := add("device_update", deviceToDTO(device)); err != nil {
+			return nil, err
+		}
+	}
+	wf, err := c.store.GetWorkflow()
+	if err != nil {
+		return nil, fmt.Errorf("load workflow: %w", err)
+	}
+	if !wf.UpdatedAt.IsZero() {
+		if err := add("workflow_update", workflowToDTO(wf)); err != nil {
+			return nil, err
+		}
+	}
+	projects, err := c.store.ListProjects()
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	for _, project := range projects {
+		if err := add("project_create", projectToDTO(project)); err != nil {
+			return nil, err
+		}
+	}
+	tasks, err := c.store.ListTasks(model.TaskFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+	for _, task := range tasks {
+		if err := add("create", taskToDTO(task)); err != nil {
+			return nil, err
+		}
+	}
+	for _, task := range tasks {
+		comments, err := c.store.ListComments(task.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list comments: %w", err)
+		}
+		for _, comment := range comments {
+			if err := add("comment_add", commentToDTO(comment)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	timeEntries, err := c.store.ListTimeEntries("")
+	if err != nil {
+		return nil, fmt.Errorf("list time entries: %w", err)
+	}
+	for _, entry := range timeEntries {
+		if err := add("time_add", timeEntryToDTO(entry)); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// withoutSnapshotted drops imported events the local snapshot already
+// covers; their effect is part of the snapshot and replaying a pruned
+// create could bring back a deleted task.
+func (c *Core) withoutSnapshotted(events []model.Event) ([]model.Event, error) {
+	snapshot, err := c.store.GetSnapshot()
+	if err != nil {
+		return nil, fmt.Errorf("load snapshot: %w", err)
+	}
+	if len(snapshot.Cursor) == 0 {
+		return events, nil
+	}
+	out := make([]model.Event, 0, len(events))
+	for _, event := range events {
+		if !sync.Covers(snapshot.Cursor, event) {
+			out = append(out, event)
+		}
+	}
+	return out, nil
+}
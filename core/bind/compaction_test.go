@@ -0,0 +1,141 @@
+package bind
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func decodeCompaction(t *testing.T, out string) CompactionDTO {
+	t.Helper()
+	var result CompactionDTO
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("decode compaction: %v (%s)", err, out)
+	}
+	return result
+}
+
+func TestCompactPrunesAckedEventsAndBootstraps(t *testing.T) {
+	phone := newTestCore(t)
+	laptop := newTestCore(t)
+	shareKeys(t, phone, laptop)
+
+	kept := createTask(t, phone, TaskDTO{Title: "Kept"})
+	gone := createTask(t, phone, TaskDTO{Title: "Gone"})
+	if out := phone.AddComment(kept.ID, "from the phone"); hasError(out) {
+		t.Fatalf("comment: %s", out)
+	}
+	if errStr := laptop.ImportEvents(phone.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import on laptop: %s", errStr)
+	}
+	createTask(t, laptop, TaskDTO{Title: "From laptop"})
+	if errStr := phone.ImportEvents(laptop.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import on phone: %s", errStr)
+	}
+	if errStr := phone.DeleteTask(gone.ID); errStr != "" {
+		t.Fatalf("delete: %s", errStr)
+	}
+
+	// The laptop has not acknowledged anything yet, so only its own event
+	// may go.
+	result := decodeCompaction(t, phone.Compact())
+	if result.Entries != 4 || result.Pruned != 1 || result.Remaining != 4 {
+		t.Fatalf("unexpected first compaction: %+v", result)
+	}
+	laptopID, err := laptop.localDeviceID()
+	if err != nil {
+		t.Fatalf("laptop device id: %v", err)
+	}
+	if errStr := laptop.AckEvents(laptopID, laptop.SyncCursor()); errStr != "" {
+		t.Fatalf("ack: %s", errStr)
+	}
+	if errStr := phone.ImportEvents(laptop.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import ack: %s", errStr)
+	}
+	result = decodeCompaction(t, phone.Compact())
+	if result.Pruned != 4 || result.Remaining != 1 {
+		t.Fatalf("expected all but the delete to be pruned, got %+v", result)
+	}
+
+	tablet := newTestCore(t)
+	shareKeys(t, phone, tablet)
+	if errStr := tablet.ImportSnapshot(phone.ExportSnapshot()); errStr != "" {
+		t.Fatalf("bootstrap: %s", errStr)
+	}
+	createTask(t, phone, TaskDTO{Title: "After snapshot"})
+	if errStr := tablet.ImportEvents(phone.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import tail: %s", errStr)
+	}
+	// The laptop still has the full log; the covered part must not replay.
+	if errStr := tablet.ImportEvents(laptop.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import laptop log: %s", errStr)
+	}
+	tasks := decodeTasks(t, tablet.ListTasks(`{"include_deferred":true}`))
+	if len(tasks) != 3 {
+		t.Fatalf("expected 3 tasks after bootstrap, got %+v", tasks)
+	}
+	if _, ok := taskByID(t, tablet, gone.ID); ok {
+		t.Fatalf("deleted task came back")
+	}
+	var comments []CommentDTO
+	if err := json.Unmarshal([]byte(tablet.ListComments(kept.ID)), &comments); err != nil || len(comments) != 1 {
+		t.Fatalf("expected the comment to survive the snapshot, got %v %+v", err, comments)
+	}
+	if !hasError(tablet.ImportSnapshot(phone.ExportSnapshot())) {
+		t.Fatalf("expected snapshot import on a used device to fail")
+	}
+}
+
+func TestCompactKeepsUndoHistory(t *testing.T) {
+	core := newTestCore(t)
+	task := createTask(t, core, TaskDTO{Title: "Solo"})
+	result := decodeCompaction(t, core.Compact())
+	if result.Pruned != 1 {
+		t.Fatalf("expected a single-device log to be pruned, got %+v", result)
+	}
+	if _, ok := taskByID(t, core, task.ID); !ok {
+		t.Fatalf("compaction must not change state")
+	}
+	// Undo steps keep their own snapshots, so pruning does not end them.
+	if errStr := core.Undo(); errStr != "" {
+		t.Fatalf("undo after compaction: %s", errStr)
+	}
+	if _, ok := taskByID(t, core, task.ID); ok {
+		t.Fatalf("expected undoing the create to remove the task")
+	}
+}
+
+func TestUndoSurvivesPruningThePreviousVersion(t *testing.T) {
+	phone := newTestCore(t)
+	laptop := newTestCore(t)
+	shareKeys(t, phone, laptop)
+	task := createTask(t, phone, TaskDTO{Title: "Draft"})
+	if errStr := laptop.ImportEvents(phone.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import on laptop: %s", errStr)
+	}
+	laptopID, err := laptop.localDeviceID()
+	if err != nil {
+		t.Fatalf("laptop device id: %v", err)
+	}
+	if errStr := laptop.AckEvents(laptopID, laptop.SyncCursor()); errStr != "" {
+		t.Fatalf("ack: %s", errStr)
+	}
+	if errStr := phone.ImportEvents(laptop.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import ack: %s", errStr)
+	}
+
+	task.Title = "Final"
+	payload, _ := json.Marshal(task)
+	if out := phone.UpdateTask(string(payload)); hasError(out) {
+		t.Fatalf("update: %s", out)
+	}
+	// The create is acked and goes; the update is not and stays.
+	if result := decodeCompaction(t, phone.Compact()); result.Pruned == 0 {
+		t.Fatalf("expected the create to be pruned, got %+v", result)
+	}
+	if errStr := phone.Undo(); errStr != "" {
+		t.Fatalf("undo: %s", errStr)
+	}
+	if got, ok := taskByID(t, phone, task.ID); !ok || got.Title != "Draft" {
+		t.Fatalf("expected the pruned version to be restored, got %+v", got)
+	}
+}
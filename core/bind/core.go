@@ -4,31 +4,82 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"os"
+	"runtime"
 	"sort"
+	"strings"
+	gosync "sync"
 	"time"
 
 	"github.com/google/uuid"
 
 	"taskpp/core/crypto"
-	"taskpp/core/model"
 	"taskpp/core/logic"
+	"taskpp/core/model"
 	"taskpp/core/storage"
 	"taskpp/core/storage/sqlite"
 	"taskpp/core/sync"
 )
 
-// Core is the bind-safe facade exposed to UIs.
+// Core is the bind-safe facade exposed to UIs. Its methods may be called from
+// any goroutine; they run one at a time.
 type Core struct {
+	// mu serializes exported methods with LAN sessions and pairing, which
+	// reach the store from their own goroutines.
+	mu    gosync.Mutex
 	store storage.Storage
-	keys  *crypto.Manager
+	// tasks is store, keeping what writes replaced for the undo stack.
+	tasks    *taskStore
+	keys     *crypto.Manager
 	deviceID string
+	// deviceName and platform describe this device in the registry.
+	deviceName string
+	platform   string
+	location   *time.Location
+	// syncTransport and syncFolder select how Sync moves events.
+	syncTransport string
+	syncFolder    string
+	// lan is the running LAN sync server, if any.
+	lan           *sync.LANServer
+	discoveryAddr string
+	// pairing is the latest pairing offer of this device, if any.
+	pairing   *sync.PairingHost
+	serverURL string
+	// undoStack and redoStack hold this session's actions, most recent
+	// last. undoGroupDepth is non-zero while an action that logs several
+	// task events runs, so they undo together; replaying suppresses
+	// recording while Undo and Redo write their own events.
+	undoStack      []undoStep
+	redoStack      []undoStep
+	undoGroupDepth int
+	undoGroupOpen  bool
+	replaying      bool
 }
 
 // Config is a bind-safe configuration struct.
 type Config struct {
 	StorageDriver string `json:"storage_driver"`
 	StoragePath   string `json:"storage_path"`
-	DeviceID      string `json:"device_id"`
+	// DeviceID is only used the first time a database is opened; after
+	// that the stored id wins. Empty picks a random one.
+	DeviceID string `json:"device_id"`
+	// DeviceName and Platform are announced in the device registry. They
+	// default to the host name and the Go OS name.
+	DeviceName string `json:"device_name"`
+	Platform   string `json:"platform"`
+	// SyncTransport selects how Sync exchanges events: "folder" writes
+	// segment files under SyncFolder. Empty leaves sync to the caller
+	// through ExportEvents and ImportEvents.
+	SyncTransport string `json:"sync_transport"`
+	SyncFolder    string `json:"sync_folder"`
+	// DiscoveryAddr is where LAN peers find each other; defaults to a
+	// multicast group. Tests use a loopback address.
+	DiscoveryAddr string `json:"discovery_addr"`
+	// ServerURL is the sync server accounts log in to.
+	ServerURL string `json:"server_url"`
+	// TimeZone is the device's IANA zone, used to resolve "today" and to
+	// place timed tasks on a day. Empty means the system zone.
+	TimeZone string `json:"time_zone"`
 }
 
 // NewCore constructs a core facade from JSON config.
@@ -53,22 +104,82 @@ func NewCore(configJSON string) *Core {
 	if deviceID == "" {
 		deviceID = uuid.NewString()
 	}
-	return &Core{store: store, keys: keys, deviceID: deviceID}
+	location := time.Local
+	if cfg.TimeZone != "" {
+		if loc, err := time.LoadLocation(cfg.TimeZone); err == nil {
+			location = loc
+		}
+	}
+	discoveryAddr := cfg.DiscoveryAddr
+	if discoveryAddr == "" {
+		discoveryAddr = sync.DefaultDiscoveryAddr
+	}
+	deviceName := cfg.DeviceName
+	if deviceName == "" {
+		deviceName, _ = os.Hostname()
+	}
+	platform := cfg.Platform
+	if platform == "" {
+		platform = runtime.GOOS
+	}
+	tasks := newTaskStore(store)
+	return &Core{
+		store:         tasks,
+		tasks:         tasks,
+		keys:          keys,
+		deviceID:      deviceID,
+		deviceName:    deviceName,
+		platform:      platform,
+		location:      location,
+		syncTransport: cfg.SyncTransport,
+		syncFolder:    cfg.SyncFolder,
+		discoveryAddr: discoveryAddr,
+		serverURL:     cfg.ServerURL,
+	}
 }
 
 // Open initializes the core. Returns empty string on success.
 func (c *Core) Open() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.store == nil {
 		return errorJSON("storage not initialized")
 	}
 	if err := c.store.Open(); err != nil {
 		return errorJSON(fmt.Sprintf("open store: %v", err))
 	}
+	// Persist the device id right away so it survives runs that never
+	// log an event.
+	state, err := c.store.GetSyncState()
+	if err != nil {
+		return errorJSON(fmt.Sprintf("get sync state: %v", err))
+	}
+	if state.DeviceID != "" {
+		c.deviceID = state.DeviceID
+		return ""
+	}
+	state.DeviceID = c.deviceID
+	if err := c.store.SaveSyncState(state); err != nil {
+		return errorJSON(fmt.Sprintf("save sync state: %v", err))
+	}
 	return ""
 }
 
 // Close shuts down the core. Returns empty string on success.
 func (c *Core) Close() string {
+	c.mu.Lock()
+	lan, pairing := c.lan, c.pairing
+	c.lan, c.pairing = nil, nil
+	c.mu.Unlock()
+	// LAN and pairing sessions take the lock, so they are shut down without it.
+	if lan != nil {
+		lan.Close()
+	}
+	if pairing != nil {
+		pairing.Close()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.store == nil {
 		return errorJSON("storage not initialized")
 	}
@@ -80,48 +191,83 @@ func (c *Core) Close() string {
 
 // TaskDTO is a bind-safe task representation.
 type TaskDTO struct {
-	ID          string `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
-	Priority    string `json:"priority"`
-	DueDate     string `json:"due_date"`
-	Order       int64  `json:"order"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
-	CompletedAt string `json:"completed_at"`
-	Archived    bool   `json:"archived"`
+	ID          string        `json:"id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Status      string        `json:"status"`
+	State       string        `json:"state"`
+	Priority    string        `json:"priority"`
+	DueDate     string        `json:"due_date"`
+	StartDate   string        `json:"start_date"`
+	DueTime     string        `json:"due_time"`
+	TimeZone    string        `json:"time_zone"`
+	Order       int64         `json:"order"`
+	CreatedAt   string        `json:"created_at"`
+	UpdatedAt   string        `json:"updated_at"`
+	CompletedAt string        `json:"completed_at"`
+	Archived    bool          `json:"archived"`
+	ProjectID   string        `json:"project_id"`
+	ParentID    string        `json:"parent_id"`
+	Tags        []string      `json:"tags"`
+	Recurrence  string        `json:"recurrence"`
+	SeriesID    string        `json:"series_id"`
+	Reminders   []ReminderDTO `json:"reminders"`
+	BlockedBy   []string      `json:"blocked_by"`
+	// Blocked is computed on output: some task in blocked_by is still open.
+	Blocked bool `json:"blocked"`
+
+	Checklist        []ChecklistItemDTO `json:"checklist"`
+	ChecklistRemoved []string           `json:"checklist_removed"`
+	// ChecklistRemovedAt maps removed item ids to when they were removed.
+	ChecklistRemovedAt map[string]string `json:"checklist_removed_at"`
+	Attachments        []AttachmentDTO   `json:"attachments"`
+	Links              []LinkDTO         `json:"links"`
+	EstimateMinutes    int64             `json:"estimate_minutes"`
+	OccurrenceDate     string            `json:"occurrence_date"`
+	Exceptions         []ExceptionDTO    `json:"recurrence_exceptions"`
+	Rank               string            `json:"rank"`
 }
 
 // TaskFilterDTO is a bind-safe filter representation.
 type TaskFilterDTO struct {
-	Status   string `json:"status"`
-	Archived *bool  `json:"archived"`
-	DueDate  string `json:"due_date"`
+	Status    string   `json:"status"`
+	Archived  *bool    `json:"archived"`
+	DueDate   string   `json:"due_date"`
+	ProjectID *string  `json:"project_id"`
+	TagsAny   []string `json:"tags_any"`
+	TagsAll   []string `json:"tags_all"`
+	TagsNone  []string `json:"tags_none"`
+	DueFrom   string   `json:"due_from"`
+	DueTo     string   `json:"due_to"`
+	// TimeZone overrides the device zone for placing and sorting timed tasks.
+	TimeZone string `json:"time_zone"`
+	// IncludeDeferred lists open tasks whose start_date is still ahead.
+	IncludeDeferred bool `json:"include_deferred"`
+	// Blocked keeps only blocked (true) or unblocked (false) tasks.
+	Blocked *bool `json:"blocked"`
+	// State keeps only tasks in one workflow state.
+	State string `json:"state"`
 }
 
 // ListTasks returns a JSON-encoded list of TaskDTO.
 func (c *Core) ListTasks(filterJSON string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.store == nil {
 		return errorJSON("storage not initialized")
 	}
-	var filterDTO TaskFilterDTO
-	if filterJSON != "" {
-		if err := json.Unmarshal([]byte(filterJSON), &filterDTO); err != nil {
-			return errorJSON(fmt.Sprintf("decode filter: %v", err))
-		}
+	filter, err := c.decodeTaskFilter(filterJSON)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("decode filter: %v", err))
 	}
-	tasks, err := c.store.ListTasks(model.TaskFilter{
-		Status:   filterDTO.Status,
-		Archived: filterDTO.Archived,
-		DueDate:  filterDTO.DueDate,
-	})
+	tasks, err := c.store.ListTasks(filter)
 	if err != nil {
 		return errorJSON(fmt.Sprintf("list tasks: %v", err))
 	}
+	lookup := c.blockerLookup()
 	out := make([]TaskDTO, 0, len(tasks))
 	for _, task := range tasks {
-		out = append(out, taskToDTO(task))
+		out = append(out, blockedTaskDTO(task, lookup))
 	}
 	data, err := json.Marshal(out)
 	if err != nil {
@@ -132,9 +278,12 @@ func (c *Core) ListTasks(filterJSON string) string {
 
 // CreateTask accepts TaskDTO JSON and returns TaskDTO JSON.
 func (c *Core) CreateTask(taskJSON string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.store == nil {
 		return errorJSON("storage not initialized")
 	}
+	defer c.undoGroup()()
 	var dto TaskDTO
 	if err := json.Unmarshal([]byte(taskJSON), &dto); err != nil {
 		return errorJSON(fmt.Sprintf("decode task: %v", err))
@@ -153,20 +302,62 @@ func (c *Core) CreateTask(taskJSON string) string {
 		dto.CreatedAt = now.Format(time.RFC3339Nano)
 	}
 	dto.UpdatedAt = now.Format(time.RFC3339Nano)
+	dto.DueDate = c.resolveDate(dto.DueDate)
+	dto.StartDate = c.resolveDate(dto.StartDate)
 	task, err := dtoToTask(dto)
 	if err != nil {
 		return errorJSON(fmt.Sprintf("convert task: %v", err))
 	}
-	if err := logic.ValidateTask(dto.Title, dto.Status, dto.Priority, dto.DueDate, task.CompletedAt); err != nil {
+	if err := c.resolveTaskState(&task, model.Task{}); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := logic.ValidateTask(dto.Title, task.Status, dto.Priority, dto.DueDate, task.CompletedAt); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := logic.ValidateRecurrence(task.Recurrence); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := logic.ValidateExceptions(task, nil); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := logic.ValidateDue(dto.DueDate, dto.DueTime, dto.TimeZone); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := c.checkProjectRef(task.ProjectID); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := c.checkParentRef(task, model.Task{}); err != nil {
 		return errorJSON(fmt.Sprintf("validate task: %v", err))
 	}
+	if err := validateReminders(task); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := c.checkDependencies(task, model.Task{}); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := validateLinks(task.Links); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := logic.ValidateEstimate(task.EstimateMinutes); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := logic.ValidateRank(task.Rank); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	// New tasks go to the bottom of their list. A legacy Order is kept as
+	// the task's place.
+	if task.Rank == "" && task.Order == 0 {
+		if task.Rank, err = c.appendRank(task, now); err != nil {
+			return errorJSON(fmt.Sprintf("rank task: %v", err))
+		}
+	}
 	if err := c.store.UpsertTask(task); err != nil {
 		return errorJSON(fmt.Sprintf("create task: %v", err))
 	}
 	if err := c.appendEvent("create", task); err != nil {
 		return errorJSON(fmt.Sprintf("event create: %v", err))
 	}
-	out, err := json.Marshal(taskToDTO(task))
+	out, err := json.Marshal(blockedTaskDTO(task, c.blockerLookup()))
 	if err != nil {
 		return errorJSON(fmt.Sprintf("encode task: %v", err))
 	}
@@ -175,9 +366,12 @@ func (c *Core) CreateTask(taskJSON string) string {
 
 // UpdateTask accepts TaskDTO JSON and returns TaskDTO JSON.
 func (c *Core) UpdateTask(taskJSON string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.store == nil {
 		return errorJSON("storage not initialized")
 	}
+	defer c.undoGroup()()
 	var dto TaskDTO
 	if err := json.Unmarshal([]byte(taskJSON), &dto); err != nil {
 		return errorJSON(fmt.Sprintf("decode task: %v", err))
@@ -186,20 +380,71 @@ func (c *Core) UpdateTask(taskJSON string) string {
 		return errorJSON("missing id")
 	}
 	dto.UpdatedAt = time.Now().UTC().Format(time.RFC3339Nano)
+	dto.DueDate = c.resolveDate(dto.DueDate)
+	dto.StartDate = c.resolveDate(dto.StartDate)
 	task, err := dtoToTask(dto)
 	if err != nil {
 		return errorJSON(fmt.Sprintf("convert task: %v", err))
 	}
-	if err := logic.ValidateTask(dto.Title, dto.Status, dto.Priority, dto.DueDate, task.CompletedAt); err != nil {
+	previous, err := c.store.GetTask(task.ID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load task: %v", err))
+	}
+	if err := c.resolveTaskState(&task, previous); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := logic.ValidateTask(dto.Title, task.Status, dto.Priority, dto.DueDate, task.CompletedAt); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := logic.ValidateRecurrence(task.Recurrence); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := logic.ValidateExceptions(task, previous.Exceptions); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := logic.ValidateDue(dto.DueDate, dto.DueTime, dto.TimeZone); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := c.checkProjectRef(task.ProjectID); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := c.checkParentRef(task, previous); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := validateReminders(task); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := c.checkDependencies(task, previous); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := validateLinks(task.Links); err != nil {
 		return errorJSON(fmt.Sprintf("validate task: %v", err))
 	}
+	if err := logic.ValidateEstimate(task.EstimateMinutes); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	if err := logic.ValidateRank(task.Rank); err != nil {
+		return errorJSON(fmt.Sprintf("validate task: %v", err))
+	}
+	completed := logic.IsDone(task) && !logic.IsDone(previous)
+	if completed && logic.IsRecurring(task.Recurrence) && task.SeriesID == "" {
+		task.SeriesID = task.ID
+	}
 	if err := c.store.UpsertTask(task); err != nil {
 		return errorJSON(fmt.Sprintf("update task: %v", err))
 	}
 	if err := c.appendEvent("update", task); err != nil {
 		return errorJSON(fmt.Sprintf("event update: %v", err))
 	}
-	out, err := json.Marshal(taskToDTO(task))
+	if err := c.cascadeParentChange(previous, task); err != nil {
+		return errorJSON(fmt.Sprintf("cascade: %v", err))
+	}
+	if completed {
+		if err := c.afterCompleted(task); err != nil {
+			return errorJSON(err.Error())
+		}
+	}
+	out, err := json.Marshal(blockedTaskDTO(task, c.blockerLookup()))
 	if err != nil {
 		return errorJSON(fmt.Sprintf("encode task: %v", err))
 	}
@@ -208,9 +453,12 @@ func (c *Core) UpdateTask(taskJSON string) string {
 
 // DeleteTask deletes a task by ID. Returns empty string on success.
 func (c *Core) DeleteTask(taskID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.store == nil {
 		return errorJSON("storage not initialized")
 	}
+	defer c.undoGroup()()
 	if taskID == "" {
 		return errorJSON("missing id")
 	}
@@ -218,9 +466,18 @@ func (c *Core) DeleteTask(taskID string) string {
 	if err != nil {
 		return errorJSON(fmt.Sprintf("load task: %v", err))
 	}
+	if err := c.deleteDescendants(taskID); err != nil {
+		return errorJSON(fmt.Sprintf("delete subtasks: %v", err))
+	}
 	if err := c.store.DeleteTask(taskID); err != nil {
 		return errorJSON(fmt.Sprintf("delete task: %v", err))
 	}
+	if err := c.store.DeleteTaskComments(taskID); err != nil {
+		return errorJSON(fmt.Sprintf("delete comments: %v", err))
+	}
+	if err := c.store.DeleteTaskTimeEntries(taskID); err != nil {
+		return errorJSON(fmt.Sprintf("delete time entries: %v", err))
+	}
 	if task.ID != "" {
 		if err := c.appendEvent("delete", task); err != nil {
 			return errorJSON(fmt.Sprintf("event delete: %v", err))
@@ -230,10 +487,15 @@ func (c *Core) DeleteTask(taskID string) string {
 }
 
 // ReorderTasks accepts reorder JSON and returns empty string on success.
+// It writes legacy integer orders, one event per task; MoveTaskBetween moves
+// a single task without touching its neighbours.
 func (c *Core) ReorderTasks(reorderJSON string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.store == nil {
 		return errorJSON("storage not initialized")
 	}
+	defer c.undoGroup()()
 	var items []ReorderItemDTO
 	if err := json.Unmarshal([]byte(reorderJSON), &items); err != nil {
 		return errorJSON(fmt.Sprintf("decode reorder: %v", err))
@@ -249,7 +511,10 @@ func (c *Core) ReorderTasks(reorderJSON string) string {
 		if task.ID == "" {
 			return errorJSON(fmt.Sprintf("task not found: %s", item.ID))
 		}
+		// An explicit Order replaces the rank key, so the task sorts where
+		// an older client would put it.
 		task.Order = item.Order
+		task.Rank = ""
 		if item.DueDate != "" {
 			parsed, err := parseDate(item.DueDate)
 			if err != nil {
@@ -270,6 +535,8 @@ func (c *Core) ReorderTasks(reorderJSON string) string {
 
 // SetDueDate updates the due date for a task.
 func (c *Core) SetDueDate(taskID string, dueDate string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.store == nil {
 		return errorJSON("storage not initialized")
 	}
@@ -283,11 +550,15 @@ func (c *Core) SetDueDate(taskID string, dueDate string) string {
 	if task.ID == "" {
 		return errorJSON("task not found")
 	}
-	parsed, err := parseDate(dueDate)
+	parsed, err := parseDate(c.resolveDate(dueDate))
 	if err != nil {
 		return errorJSON(fmt.Sprintf("parse due_date: %v", err))
 	}
 	task.DueDate = parsed
+	if parsed.IsZero() {
+		task.DueTime = ""
+		task.TimeZone = ""
+	}
 	task.UpdatedAt = time.Now().UTC()
 	if err := c.store.UpsertTask(task); err != nil {
 		return errorJSON(fmt.Sprintf("set due date: %v", err))
@@ -298,11 +569,15 @@ func (c *Core) SetDueDate(taskID string, dueDate string) string {
 	return ""
 }
 
-// SetCompleted updates completion state.
+// SetCompleted updates completion state. The task moves to the first state of
+// the new category, which the workflow must allow.
 func (c *Core) SetCompleted(taskID string, completed bool) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.store == nil {
 		return errorJSON("storage not initialized")
 	}
+	defer c.undoGroup()()
 	if taskID == "" {
 		return errorJSON("missing id")
 	}
@@ -313,13 +588,25 @@ func (c *Core) SetCompleted(taskID string, completed bool) string {
 	if task.ID == "" {
 		return errorJSON("task not found")
 	}
+	wf, err := c.workflow()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	from := logic.StateOf(task, wf)
 	if completed {
 		task.Status = "done"
 		task.CompletedAt = time.Now().UTC()
+		if logic.IsRecurring(task.Recurrence) && task.SeriesID == "" {
+			task.SeriesID = task.ID
+		}
 	} else {
 		task.Status = "active"
 		task.CompletedAt = time.Time{}
 	}
+	task.State = logic.StateOf(task, wf)
+	if !logic.CanTransition(wf, from, task.State) {
+		return errorJSON(fmt.Sprintf("set completed: %s -> %s not allowed", from, task.State))
+	}
 	task.UpdatedAt = time.Now().UTC()
 	if err := c.store.UpsertTask(task); err != nil {
 		return errorJSON(fmt.Sprintf("set completed: %v", err))
@@ -327,6 +614,14 @@ func (c *Core) SetCompleted(taskID string, completed bool) string {
 	if err := c.appendEvent("set_completed", task); err != nil {
 		return errorJSON(fmt.Sprintf("event set completed: %v", err))
 	}
+	if completed {
+		if err := c.completeDescendants(task.ID); err != nil {
+			return errorJSON(fmt.Sprintf("complete subtasks: %v", err))
+		}
+		if err := c.spawnNextInstance(task); err != nil {
+			return errorJSON(fmt.Sprintf("recur: %v", err))
+		}
+	}
 	return ""
 }
 
@@ -342,6 +637,8 @@ type EventDTO struct {
 
 // ExportEvents returns JSON-encoded EventDTO list.
 func (c *Core) ExportEvents(sinceSeq int64) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.store == nil {
 		return errorJSON("storage not initialized")
 	}
@@ -362,6 +659,8 @@ func (c *Core) ExportEvents(sinceSeq int64) string {
 
 // ImportEvents accepts JSON-encoded EventDTO list.
 func (c *Core) ImportEvents(eventsJSON string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.store == nil {
 		return errorJSON("storage not initialized")
 	}
@@ -380,19 +679,36 @@ func (c *Core) ImportEvents(eventsJSON string) string {
 		}
 		events = append(events, event)
 	}
+	if err := c.importEvents(events); err != nil {
+		return errorJSON(err.Error())
+	}
+	return ""
+}
+
+// importEvents applies and logs events received from other devices.
+func (c *Core) importEvents(events []model.Event) error {
 	events = dedupeEvents(events)
 	sortEvents(events)
+	events, err := c.withoutSnapshotted(events)
+	if err != nil {
+		return err
+	}
+	if events, err = c.withoutRevoked(events); err != nil {
+		return err
+	}
 	if err := c.applyImportedEvents(events); err != nil {
-		return errorJSON(fmt.Sprintf("apply events: %v", err))
+		return fmt.Errorf("apply events: %w", err)
 	}
 	if err := c.store.AppendEvents(events); err != nil {
-		return errorJSON(fmt.Sprintf("append events: %v", err))
+		return fmt.Errorf("append events: %w", err)
 	}
-	return ""
+	return c.touchDevices(events)
 }
 
 // GetSyncState returns JSON-encoded sync state.
 func (c *Core) GetSyncState() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.store == nil {
 		return errorJSON("storage not initialized")
 	}
@@ -413,6 +729,8 @@ func (c *Core) GetSyncState() string {
 
 // InitKeys initializes encryption keys.
 func (c *Core) InitKeys(passphrase string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.store == nil || c.keys == nil {
 		return errorJSON("storage not initialized")
 	}
@@ -446,6 +764,8 @@ func (c *Core) InitKeys(passphrase string) string {
 
 // UnlockKeys unlocks encryption keys.
 func (c *Core) UnlockKeys(passphrase string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.store == nil || c.keys == nil {
 		return errorJSON("storage not initialized")
 	}
@@ -462,6 +782,14 @@ func (c *Core) UnlockKeys(passphrase string) string {
 	if err := c.keys.DeriveKey(passphrase, state.Salt); err != nil {
 		return errorJSON(fmt.Sprintf("derive key: %v", err))
 	}
+	// Tasks written before workflows existed only carry a status.
+	wf, err := c.workflow()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	if err := c.normalizeTaskStates(wf); err != nil {
+		return errorJSON(fmt.Sprintf("migrate task states: %v", err))
+	}
 	return ""
 }
 
@@ -483,19 +811,79 @@ type SyncStateDTO struct {
 	ServerTag string `json:"server_tag"`
 }
 
+func (c *Core) decodeTaskFilter(filterJSON string) (model.TaskFilter, error) {
+	var filterDTO TaskFilterDTO
+	if filterJSON != "" {
+		if err := json.Unmarshal([]byte(filterJSON), &filterDTO); err != nil {
+			return model.TaskFilter{}, err
+		}
+	}
+	filter := model.TaskFilter{
+		Status:       filterDTO.Status,
+		Archived:     filterDTO.Archived,
+		DueDate:      c.resolveDate(filterDTO.DueDate),
+		DueFrom:      c.resolveDate(filterDTO.DueFrom),
+		DueTo:        c.resolveDate(filterDTO.DueTo),
+		ProjectID:    filterDTO.ProjectID,
+		Location:     c.location,
+		HideDeferred: !filterDTO.IncludeDeferred,
+		Blocked:      filterDTO.Blocked,
+		State:        filterDTO.State,
+	}
+	if filterDTO.TimeZone != "" {
+		loc, err := time.LoadLocation(filterDTO.TimeZone)
+		if err != nil {
+			return model.TaskFilter{}, fmt.Errorf("invalid time_zone: %s", filterDTO.TimeZone)
+		}
+		filter.Location = loc
+	}
+	var err error
+	if filter.TagsAny, err = logic.NormalizeTags(filterDTO.TagsAny); err != nil {
+		return model.TaskFilter{}, err
+	}
+	if filter.TagsAll, err = logic.NormalizeTags(filterDTO.TagsAll); err != nil {
+		return model.TaskFilter{}, err
+	}
+	if filter.TagsNone, err = logic.NormalizeTags(filterDTO.TagsNone); err != nil {
+		return model.TaskFilter{}, err
+	}
+	return filter, nil
+}
+
 func taskToDTO(task model.Task) TaskDTO {
 	return TaskDTO{
 		ID:          task.ID,
 		Title:       task.Title,
 		Description: task.Description,
 		Status:      task.Status,
+		State:       task.State,
 		Priority:    task.Priority,
 		DueDate:     formatDate(task.DueDate),
+		StartDate:   formatDate(task.StartDate),
+		DueTime:     task.DueTime,
+		TimeZone:    task.TimeZone,
 		Order:       task.Order,
 		CreatedAt:   formatTime(task.CreatedAt),
 		UpdatedAt:   formatTime(task.UpdatedAt),
 		CompletedAt: formatTime(task.CompletedAt),
 		Archived:    task.Archived,
+		ProjectID:   task.ProjectID,
+		ParentID:    task.ParentID,
+		Tags:        task.Tags,
+		Recurrence:  task.Recurrence,
+		SeriesID:    task.SeriesID,
+		Reminders:   remindersToDTO(task.Reminders),
+		BlockedBy:   task.BlockedBy,
+
+		Checklist:          checklistToDTO(task.Checklist),
+		ChecklistRemoved:   task.ChecklistRemoved,
+		ChecklistRemovedAt: removedAtToDTO(task.ChecklistRemovedAt),
+		Attachments:        attachmentsToDTO(task.Attachments),
+		Links:              linksToDTO(task.Links),
+		EstimateMinutes:    task.EstimateMinutes,
+		OccurrenceDate:     formatDate(task.OccurrenceDate),
+		Exceptions:         exceptionsToDTO(task.Exceptions),
+		Rank:               task.Rank,
 	}
 }
 
@@ -504,6 +892,10 @@ func dtoToTask(dto TaskDTO) (model.Task, error) {
 	if err != nil {
 		return model.Task{}, err
 	}
+	startDate, err := parseDate(dto.StartDate)
+	if err != nil {
+		return model.Task{}, err
+	}
 	createdAt, err := parseTime(dto.CreatedAt)
 	if err != nil {
 		return model.Task{}, err
@@ -516,18 +908,67 @@ func dtoToTask(dto TaskDTO) (model.Task, error) {
 	if err != nil {
 		return model.Task{}, err
 	}
+	tags, err := logic.NormalizeTags(dto.Tags)
+	if err != nil {
+		return model.Task{}, err
+	}
+	reminders, err := dtoToReminders(dto.Reminders)
+	if err != nil {
+		return model.Task{}, err
+	}
+	checklist, err := dtoToChecklist(dto.Checklist)
+	if err != nil {
+		return model.Task{}, err
+	}
+	removedAt, err := dtoToRemovedAt(dto.ChecklistRemovedAt)
+	if err != nil {
+		return model.Task{}, err
+	}
+	attachments, err := dtoToAttachments(dto.Attachments)
+	if err != nil {
+		return model.Task{}, err
+	}
+	occurrenceDate, err := parseDate(dto.OccurrenceDate)
+	if err != nil {
+		return model.Task{}, err
+	}
+	exceptions, err := dtoToExceptions(dto.Exceptions)
+	if err != nil {
+		return model.Task{}, err
+	}
 	return model.Task{
 		ID:          dto.ID,
 		Title:       dto.Title,
 		Description: dto.Description,
 		Status:      dto.Status,
+		State:       dto.State,
 		Priority:    dto.Priority,
 		DueDate:     dueDate,
+		StartDate:   startDate,
+		DueTime:     dto.DueTime,
+		TimeZone:    dto.TimeZone,
 		Order:       dto.Order,
 		CreatedAt:   createdAt,
 		UpdatedAt:   updatedAt,
 		CompletedAt: completedAt,
 		Archived:    dto.Archived,
+		ProjectID:   dto.ProjectID,
+		ParentID:    dto.ParentID,
+		Tags:        tags,
+		Recurrence:  dto.Recurrence,
+		SeriesID:    dto.SeriesID,
+		Reminders:   reminders,
+		BlockedBy:   uniqueIDs(dto.BlockedBy),
+
+		Checklist:          checklist,
+		ChecklistRemoved:   dto.ChecklistRemoved,
+		ChecklistRemovedAt: removedAt,
+		Attachments:        attachments,
+		Links:              dtoToLinks(dto.Links),
+		EstimateMinutes:    dto.EstimateMinutes,
+		OccurrenceDate:     occurrenceDate,
+		Exceptions:         exceptions,
+		Rank:               dto.Rank,
 	}, nil
 }
 
@@ -572,8 +1013,8 @@ func dtoToEvent(dto EventDTO) (model.Event, error) {
 func syncStateToDTO(state model.SyncState) SyncStateDTO {
 	return SyncStateDTO{
 		LastSeq:   state.LastSeq,
-		LastSync: formatTime(state.LastSync),
-		DeviceID: state.DeviceID,
+		LastSync:  formatTime(state.LastSync),
+		DeviceID:  state.DeviceID,
 		ServerTag: state.ServerTag,
 	}
 }
@@ -612,23 +1053,50 @@ func errorJSON(message string) string {
 }
 
 func (c *Core) appendEvent(eventType string, task model.Task) error {
+	previous := c.tasks.forget(task.ID)
+	if _, err := c.logEvent(eventType, taskToDTO(task)); err != nil {
+		return err
+	}
+	c.recordUndo(eventType, task, previous)
+	return nil
+}
+
+// localDeviceID returns the id this device signs its events with.
+func (c *Core) localDeviceID() (string, error) {
+	state, err := c.store.GetSyncState()
+	if err != nil {
+		return "", fmt.Errorf("get sync state: %w", err)
+	}
+	if state.DeviceID != "" {
+		return state.DeviceID, nil
+	}
+	return c.deviceID, nil
+}
+
+// appendPayloadEvent encrypts any bind DTO as an event payload and logs it.
+func (c *Core) appendPayloadEvent(eventType string, dto any) error {
+	_, err := c.logEvent(eventType, dto)
+	return err
+}
+
+func (c *Core) logEvent(eventType string, dto any) (model.Event, error) {
 	if c.store == nil {
-		return fmt.Errorf("storage not initialized")
+		return model.Event{}, fmt.Errorf("storage not initialized")
 	}
 	if c.keys == nil || !c.keys.IsUnlocked() {
-		return fmt.Errorf("keys not unlocked")
+		return model.Event{}, fmt.Errorf("keys not unlocked")
 	}
-	plaintext, err := json.Marshal(taskToDTO(task))
+	plaintext, err := json.Marshal(dto)
 	if err != nil {
-		return fmt.Errorf("encode payload: %w", err)
+		return model.Event{}, fmt.Errorf("encode payload: %w", err)
 	}
 	payload, err := c.keys.Encrypt(plaintext)
 	if err != nil {
-		return fmt.Errorf("encrypt payload: %w", err)
+		return model.Event{}, fmt.Errorf("encrypt payload: %w", err)
 	}
 	state, err := c.store.GetSyncState()
 	if err != nil {
-		return fmt.Errorf("get sync state: %w", err)
+		return model.Event{}, fmt.Errorf("get sync state: %w", err)
 	}
 	if state.DeviceID == "" {
 		state.DeviceID = c.deviceID
@@ -643,12 +1111,12 @@ func (c *Core) appendEvent(eventType string, task model.Task) error {
 		Payload:  payload,
 	}
 	if err := c.store.AppendEvents([]model.Event{event}); err != nil {
-		return fmt.Errorf("append event: %w", err)
+		return model.Event{}, fmt.Errorf("append event: %w", err)
 	}
 	if err := c.store.SaveSyncState(state); err != nil {
-		return fmt.Errorf("save sync state: %w", err)
+		return model.Event{}, fmt.Errorf("save sync state: %w", err)
 	}
-	return nil
+	return event, nil
 }
 
 func (c *Core) applyImportedEvents(events []model.Event) error {
@@ -665,37 +1133,82 @@ func (c *Core) applyImportedEvents(events []model.Event) error {
 			return fmt.Errorf("decrypt event payload: %w", err)
 		}
 		event.Payload = plaintext
-		taskID := taskIDFromPayload(event.Payload)
-		if taskID == "" {
-			return fmt.Errorf("missing task id in payload")
+		if err := c.applyEvent(event); err != nil {
+			return err
 		}
-		task, err := c.store.GetTask(taskID)
-		if err != nil {
-			return fmt.Errorf("get task: %w", err)
+	}
+	return nil
+}
+
+// applyEvent applies one decrypted event to local state.
+func (c *Core) applyEvent(event model.Event) error {
+	switch {
+	case strings.HasPrefix(event.Type, "project_"):
+		return c.applyProjectEvent(event)
+	case strings.HasPrefix(event.Type, "comment_"):
+		return c.applyCommentEvent(event)
+	case strings.HasPrefix(event.Type, "time_"):
+		return c.applyTimeEntryEvent(event)
+	case strings.HasPrefix(event.Type, "workflow_"):
+		return c.applyWorkflowEvent(event)
+	case strings.HasPrefix(event.Type, "device_"):
+		return c.applyDeviceEvent(event)
+	default:
+		return c.applyTaskEvent(event)
+	}
+}
+
+func (c *Core) applyTaskEvent(event model.Event) error {
+	taskID := taskIDFromPayload(event.Payload)
+	if taskID == "" {
+		return fmt.Errorf("missing task id in payload")
+	}
+	task, err := c.store.GetTask(taskID)
+	if err != nil {
+		return fmt.Errorf("get task: %w", err)
+	}
+	updated, changed, conflict, err := sync.ApplyEvent(task, event)
+	if err != nil {
+		return fmt.Errorf("apply event: %w", err)
+	}
+	if changed {
+		defer c.changedElsewhere(taskID)
+	}
+	if changed && event.Type == "delete" {
+		if err := c.store.DeleteTask(taskID); err != nil {
+			return fmt.Errorf("delete task: %w", err)
 		}
-		updated, changed, conflict, err := sync.ApplyEvent(task, event)
+		if err := c.store.DeleteTaskComments(taskID); err != nil {
+			return fmt.Errorf("delete comments: %w", err)
+		}
+		if err := c.store.DeleteTaskTimeEntries(taskID); err != nil {
+			return fmt.Errorf("delete time entries: %w", err)
+		}
+		return nil
+	}
+	if changed {
+		wf, err := c.workflow()
 		if err != nil {
-			return fmt.Errorf("apply event: %w", err)
+			return err
 		}
-		if changed {
-			if err := c.store.UpsertTask(updated); err != nil {
-				return fmt.Errorf("upsert task: %w", err)
-			}
-			continue
+		updated.State = logic.StateOf(updated, wf)
+		if err := c.store.UpsertTask(updated); err != nil {
+			return fmt.Errorf("upsert task: %w", err)
 		}
-		if conflict {
-			conflictRecord := model.Conflict{
-				ID:             uuid.NewString(),
-				TaskID:         task.ID,
-				LocalUpdatedAt: task.UpdatedAt,
-				RemoteEventID:  event.ID,
-				RemoteTS:       event.TS,
-				DetectedAt:     time.Now().UTC(),
-				Resolution:     "lww_local",
-			}
-			if err := c.store.AddConflict(conflictRecord); err != nil {
-				return fmt.Errorf("add conflict: %w", err)
-			}
+		return nil
+	}
+	if conflict {
+		conflictRecord := model.Conflict{
+			ID:             uuid.NewString(),
+			TaskID:         task.ID,
+			LocalUpdatedAt: task.UpdatedAt,
+			RemoteEventID:  event.ID,
+			RemoteTS:       event.TS,
+			DetectedAt:     time.Now().UTC(),
+			Resolution:     "lww_local",
+		}
+		if err := c.store.AddConflict(conflictRecord); err != nil {
+			return fmt.Errorf("add conflict: %w", err)
 		}
 	}
 	return nil
@@ -739,6 +1252,8 @@ func sortEvents(events []model.Event) {
 
 // DebugDecryptEvent is a local-only helper to decrypt an event payload.
 func (c *Core) DebugDecryptEvent(payloadBase64 string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.keys == nil || !c.keys.IsUnlocked() {
 		return errorJSON("keys not unlocked")
 	}
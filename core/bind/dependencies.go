@@ -0,0 +1,142 @@
+package bind
+
+import (
+	"fmt"
+	"time"
+
+	"taskpp/core/logic"
+	"taskpp/core/model"
+)
+
+// AddDependency marks taskID as blocked until blockerID is done. Returns
+// empty string on success.
+func (c *Core) AddDependency(taskID string, blockerID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.editDependencies(taskID, "dependency_add", func(task *model.Task) error {
+		for _, id := range task.BlockedBy {
+			if id == blockerID {
+				return nil
+			}
+		}
+		previous := *task
+		task.BlockedBy = append(task.BlockedBy, blockerID)
+		return c.checkDependencies(*task, previous)
+	})
+}
+
+// RemoveDependency drops blockerID from the task's blockers. Returns empty
+// string on success.
+func (c *Core) RemoveDependency(taskID string, blockerID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.editDependencies(taskID, "dependency_remove", func(task *model.Task) error {
+		kept := task.BlockedBy[:0]
+		for _, id := range task.BlockedBy {
+			if id != blockerID {
+				kept = append(kept, id)
+			}
+		}
+		task.BlockedBy = kept
+		return nil
+	})
+}
+
+func (c *Core) editDependencies(taskID string, eventType string, mutate func(task *model.Task) error) string {
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if taskID == "" {
+		return errorJSON("missing id")
+	}
+	task, err := c.store.GetTask(taskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load task: %v", err))
+	}
+	if task.ID == "" {
+		return errorJSON("task not found")
+	}
+	if err := mutate(&task); err != nil {
+		return errorJSON(fmt.Sprintf("validate dependency: %v", err))
+	}
+	task.UpdatedAt = time.Now().UTC()
+	if err := c.store.UpsertTask(task); err != nil {
+		return errorJSON(fmt.Sprintf("update task: %v", err))
+	}
+	if err := c.appendEvent(eventType, task); err != nil {
+		return errorJSON(fmt.Sprintf("event %s: %v", eventType, err))
+	}
+	return ""
+}
+
+// checkDependencies validates the blockers added since previous against the
+// stored graph. Blockers the task already had are left alone, so a deleted
+// blocker does not stop later edits (IsBlocked ignores it).
+func (c *Core) checkDependencies(task, previous model.Task) error {
+	known := make(map[string]bool, len(previous.BlockedBy))
+	for _, id := range previous.BlockedBy {
+		known[id] = true
+	}
+	added := make([]string, 0, len(task.BlockedBy))
+	for _, id := range task.BlockedBy {
+		if !known[id] {
+			added = append(added, id)
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	tasks, err := c.store.ListTasks(model.TaskFilter{})
+	if err != nil {
+		return fmt.Errorf("list tasks: %w", err)
+	}
+	blockedBy := make(map[string][]string, len(tasks)+1)
+	for _, existing := range tasks {
+		blockedBy[existing.ID] = existing.BlockedBy
+	}
+	blockedBy[task.ID] = task.BlockedBy
+	for _, blockerID := range added {
+		if err := logic.ValidateDependency(task.ID, blockerID, blockedBy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// blockerLookup returns a cached task lookup for computing blocked state.
+func (c *Core) blockerLookup() func(id string) (model.Task, bool) {
+	cache := make(map[string]model.Task)
+	return func(id string) (model.Task, bool) {
+		if task, ok := cache[id]; ok {
+			return task, task.ID != ""
+		}
+		task, err := c.store.GetTask(id)
+		if err != nil {
+			return model.Task{}, false
+		}
+		cache[id] = task
+		return task, task.ID != ""
+	}
+}
+
+func blockedTaskDTO(task model.Task, lookup func(id string) (model.Task, bool)) TaskDTO {
+	dto := taskToDTO(task)
+	dto.Blocked = logic.IsBlocked(task, lookup)
+	return dto
+}
+
+func uniqueIDs(ids []string) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package bind
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDependenciesBlockUntilDone(t *testing.T) {
+	core := newTestCore(t)
+
+	design := createTask(t, core, TaskDTO{Title: "Design"})
+	build := createTask(t, core, TaskDTO{Title: "Build", BlockedBy: []string{design.ID}})
+	ship := createTask(t, core, TaskDTO{Title: "Ship"})
+	if !build.Blocked {
+		t.Fatalf("expected build to be blocked on create")
+	}
+	if errStr := core.AddDependency(ship.ID, build.ID); errStr != "" {
+		t.Fatalf("add dependency: %s", errStr)
+	}
+	if !hasError(core.AddDependency(design.ID, ship.ID)) {
+		t.Fatalf("expected cycle to be rejected")
+	}
+
+	blocked := true
+	filter, _ := json.Marshal(TaskFilterDTO{Blocked: &blocked})
+	if tasks := decodeTasks(t, core.ListTasks(string(filter))); len(tasks) != 2 {
+		t.Fatalf("expected 2 blocked tasks, got %+v", tasks)
+	}
+
+	if errStr := core.SetCompleted(design.ID, true); errStr != "" {
+		t.Fatalf("complete: %s", errStr)
+	}
+	blocked = false
+	filter, _ = json.Marshal(TaskFilterDTO{Status: "active", Blocked: &blocked})
+	tasks := decodeTasks(t, core.ListTasks(string(filter)))
+	if len(tasks) != 1 || tasks[0].ID != build.ID {
+		t.Fatalf("expected build to be unblocked, got %+v", tasks)
+	}
+
+	if errStr := core.RemoveDependency(ship.ID, build.ID); errStr != "" {
+		t.Fatalf("remove dependency: %s", errStr)
+	}
+	for _, task := range decodeTasks(t, core.ListTasks("")) {
+		if task.ID == ship.ID && (task.Blocked || len(task.BlockedBy) != 0) {
+			t.Fatalf("expected ship to have no blockers, got %+v", task)
+		}
+	}
+}
+
+func TestDeletedBlockerDoesNotBlockEdits(t *testing.T) {
+	core := newTestCore(t)
+	design := createTask(t, core, TaskDTO{Title: "Design"})
+	build := createTask(t, core, TaskDTO{Title: "Build", BlockedBy: []string{design.ID}})
+	if errStr := core.DeleteTask(design.ID); errStr != "" {
+		t.Fatalf("delete blocker: %s", errStr)
+	}
+
+	build.Title = "Build it"
+	payload, _ := json.Marshal(build)
+	out := core.UpdateTask(string(payload))
+	if hasError(out) {
+		t.Fatalf("update after blocker deletion: %s", out)
+	}
+	var updated TaskDTO
+	if err := json.Unmarshal([]byte(out), &updated); err != nil || updated.Blocked {
+		t.Fatalf("expected a deleted blocker to be ignored, got %s", out)
+	}
+
+	build.BlockedBy = append(build.BlockedBy, "missing")
+	payload, _ = json.Marshal(build)
+	if !hasError(core.UpdateTask(string(payload))) {
+		t.Fatalf("expected a new unknown blocker to be rejected")
+	}
+}
@@ -0,0 +1,285 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"taskpp/core/logic"
+	"taskpp/core/model"
+	"taskpp/core/sync"
+)
+
+// DeviceDTO is a bind-safe device registry entry. Local marks this device;
+// last_seen is the newest event received from the device.
+type DeviceDTO struct {
+	ID         string       `json:"id"`
+	Name       string       `json:"name"`
+	Platform   string       `json:"platform"`
+	CreatedAt  string       `json:"created_at"`
+	UpdatedAt  string       `json:"updated_at"`
+	LastSeen   string       `json:"last_seen"`
+	Acked      model.Cursor `json:"acked"`
+	Revoked    bool         `json:"revoked"`
+	RevokedSeq int64        `json:"revoked_seq"`
+	Local      bool         `json:"local"`
+}
+
+// ListDevices returns JSON-encoded DeviceDTO list, this device first.
+// Devices only known from their events have an empty name.
+func (c *Core) ListDevices() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return errorJSON("keys not unlocked")
+	}
+	devices, err := c.store.ListDevices()
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list devices: %v", err))
+	}
+	local, err := c.localDevice()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	local.LastSeen = time.Now().UTC()
+	out := []DeviceDTO{deviceToDTO(local)}
+	out[0].Local = true
+	sort.Slice(devices, func(i, j int) bool {
+		if devices[i].Name != devices[j].Name {
+			return devices[i].Name < devices[j].Name
+		}
+		return devices[i].ID < devices[j].ID
+	})
+	for _, device := range devices {
+		if device.ID != local.ID {
+			out = append(out, deviceToDTO(device))
+		}
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode devices: %v", err))
+	}
+	return string(data)
+}
+
+// RenameDevice renames any registry entry, as a "device_update" event.
+// Returns empty string on success.
+func (c *Core) RenameDevice(deviceID string, name string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return errorJSON("keys not unlocked")
+	}
+	name = strings.TrimSpace(name)
+	if err := logic.ValidateDeviceName(name); err != nil {
+		return errorJSON(fmt.Sprintf("validate device: %v", err))
+	}
+	device, err := c.registeredDevice(deviceID)
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	device.Name = name
+	device.UpdatedAt = time.Now().UTC()
+	if err := c.saveDevice(device, "device_update"); err != nil {
+		return errorJSON(err.Error())
+	}
+	return ""
+}
+
+// RevokeDevice removes a device from sync: every device ignores its events
+// past the seq seen at revocation, and it no longer holds back compaction.
+// Revocation cannot be undone. Returns empty string on success.
+func (c *Core) RevokeDevice(deviceID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return errorJSON("keys not unlocked")
+	}
+	localID, err := c.localDeviceID()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	if deviceID == localID {
+		return errorJSON("cannot revoke this device")
+	}
+	device, err := c.registeredDevice(deviceID)
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	if device.Revoked {
+		return errorJSON("device already revoked")
+	}
+	cursor, err := c.syncCursor()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	device.Revoked = true
+	device.RevokedSeq = cursor[device.ID]
+	if err := c.saveDevice(device, "device_revoke"); err != nil {
+		return errorJSON(err.Error())
+	}
+	return ""
+}
+
+// localDevice returns this device's registry entry, filling in name and
+// platform from the config when it has not been announced yet.
+func (c *Core) localDevice() (model.Device, error) {
+	localID, err := c.localDeviceID()
+	if err != nil {
+		return model.Device{}, err
+	}
+	device, err := c.store.GetDevice(localID)
+	if err != nil {
+		return model.Device{}, fmt.Errorf("get device: %w", err)
+	}
+	if device.ID == "" {
+		device = model.Device{ID: localID, CreatedAt: time.Now().UTC()}
+	}
+	if device.Name == "" && device.Platform == "" {
+		device.Name = c.deviceName
+		device.Platform = c.platform
+		device.UpdatedAt = time.Now().UTC()
+	}
+	return device, nil
+}
+
+// registeredDevice loads a registry entry; this device always exists.
+func (c *Core) registeredDevice(deviceID string) (model.Device, error) {
+	if deviceID == "" {
+		return model.Device{}, fmt.Errorf("missing device id")
+	}
+	localID, err := c.localDeviceID()
+	if err != nil {
+		return model.Device{}, err
+	}
+	if deviceID == localID {
+		return c.localDevice()
+	}
+	device, err := c.store.GetDevice(deviceID)
+	if err != nil {
+		return model.Device{}, fmt.Errorf("get device: %w", err)
+	}
+	if device.ID == "" {
+		return model.Device{}, fmt.Errorf("device not found")
+	}
+	return device, nil
+}
+
+func (c *Core) saveDevice(device model.Device, eventType string) error {
+	if err := c.store.UpsertDevice(device); err != nil {
+		return fmt.Errorf("upsert device: %w", err)
+	}
+	if err := c.appendPayloadEvent(eventType, deviceToDTO(device)); err != nil {
+		return fmt.Errorf("event %s: %w", eventType, err)
+	}
+	return nil
+}
+
+func (c *Core) applyDeviceEvent(event model.Event) error {
+	var ref DeviceDTO
+	_ = json.Unmarshal(event.Payload, &ref)
+	if ref.ID == "" {
+		return fmt.Errorf("missing device id in payload")
+	}
+	device, err := c.store.GetDevice(ref.ID)
+	if err != nil {
+		return fmt.Errorf("get device: %w", err)
+	}
+	updated, changed, _, err := sync.ApplyDeviceEvent(device, event)
+	if err != nil {
+		return fmt.Errorf("apply device event: %w", err)
+	}
+	if !changed {
+		return nil
+	}
+	if err := c.store.UpsertDevice(updated); err != nil {
+		return fmt.Errorf("upsert device: %w", err)
+	}
+	return nil
+}
+
+// touchDevices records when each author of imported events was last seen,
+// adding unknown authors to the registry. This is local and not logged.
+func (c *Core) touchDevices(events []model.Event) error {
+	seen := make(map[string]time.Time)
+	for _, event := range events {
+		if event.TS.After(seen[event.DeviceID]) {
+			seen[event.DeviceID] = event.TS
+		}
+	}
+	localID, err := c.localDeviceID()
+	if err != nil {
+		return err
+	}
+	for deviceID, ts := range seen {
+		if deviceID == "" || deviceID == localID {
+			continue
+		}
+		device, err := c.store.GetDevice(deviceID)
+		if err != nil {
+			return fmt.Errorf("get device: %w", err)
+		}
+		if device.ID == "" {
+			device = model.Device{ID: deviceID, CreatedAt: ts}
+		}
+		if !ts.After(device.LastSeen) {
+			continue
+		}
+		device.LastSeen = ts
+		if err := c.store.UpsertDevice(device); err != nil {
+			return fmt.Errorf("upsert device: %w", err)
+		}
+	}
+	return nil
+}
+
+// withoutRevoked drops imported events a revoked device wrote after its
+// revocation.
+func (c *Core) withoutRevoked(events []model.Event) ([]model.Event, error) {
+	devices, err := c.store.ListDevices()
+	if err != nil {
+		return nil, fmt.Errorf("list devices: %w", err)
+	}
+	revokedAfter := make(map[string]int64)
+	for _, device := range devices {
+		if device.Revoked {
+			revokedAfter[device.ID] = device.RevokedSeq
+		}
+	}
+	if len(revokedAfter) == 0 {
+		return events, nil
+	}
+	out := make([]model.Event, 0, len(events))
+	for _, event := range events {
+		if seq, ok := revokedAfter[event.DeviceID]; ok && event.Seq > seq {
+			continue
+		}
+		out = append(out, event)
+	}
+	return out, nil
+}
+
+func deviceToDTO(device model.Device) DeviceDTO {
+	return DeviceDTO{
+		ID:         device.ID,
+		Name:       device.Name,
+		Platform:   device.Platform,
+		CreatedAt:  formatTime(device.CreatedAt),
+		UpdatedAt:  formatTime(device.UpdatedAt),
+		LastSeen:   formatTime(device.LastSeen),
+		Acked:      device.Acked,
+		Revoked:    device.Revoked,
+		RevokedSeq: device.RevokedSeq,
+	}
+}
@@ -0,0 +1,124 @@
+package bind
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func decodeDevices(t *testing.T, out string) []DeviceDTO {
+	t.Helper()
+	var devices []DeviceDTO
+	if err := json.Unmarshal([]byte(out), &devices); err != nil {
+		t.Fatalf("decode devices: %v (%s)", err, out)
+	}
+	return devices
+}
+
+func deviceByID(devices []DeviceDTO, id string) (DeviceDTO, bool) {
+	for _, device := range devices {
+		if device.ID == id {
+			return device, true
+		}
+	}
+	return DeviceDTO{}, false
+}
+
+func TestDeviceIDPersistsWithoutEvents(t *testing.T) {
+	cfgJSON, _ := json.Marshal(Config{
+		StorageDriver: "sqlite",
+		StoragePath:   "file:" + filepath.Join(t.TempDir(), "device.db"),
+	})
+	ids := make([]string, 0, 2)
+	for i := 0; i < 2; i++ {
+		core := NewCore(string(cfgJSON))
+		if errStr := core.Open(); errStr != "" {
+			t.Fatalf("open: %s", errStr)
+		}
+		var state SyncStateDTO
+		if err := json.Unmarshal([]byte(core.GetSyncState()), &state); err != nil {
+			t.Fatalf("decode sync state: %v", err)
+		}
+		ids = append(ids, state.DeviceID)
+		core.Close()
+	}
+	if ids[0] == "" || ids[0] != ids[1] {
+		t.Fatalf("expected a stable device id, got %v", ids)
+	}
+}
+
+func TestDeviceRegistrySyncs(t *testing.T) {
+	phone := newTestCore(t)
+	laptop := newTestCore(t)
+	shareKeys(t, phone, laptop)
+
+	local := decodeDevices(t, phone.ListDevices())
+	if len(local) != 1 || !local[0].Local || local[0].Platform == "" {
+		t.Fatalf("expected only this device, got %+v", local)
+	}
+	phoneID := local[0].ID
+	createTask(t, phone, TaskDTO{Title: "Synced"})
+	if errStr := phone.AckEvents(phoneID, phone.SyncCursor()); errStr != "" {
+		t.Fatalf("ack: %s", errStr)
+	}
+	if errStr := laptop.ImportEvents(phone.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import: %s", errStr)
+	}
+	seen, ok := deviceByID(decodeDevices(t, laptop.ListDevices()), phoneID)
+	if !ok || seen.Local || seen.Name != local[0].Name || seen.LastSeen == "" || seen.Acked[phoneID] != 1 {
+		t.Fatalf("expected the phone in the laptop registry, got %+v", seen)
+	}
+
+	if errStr := laptop.RenameDevice(phoneID, "  Pocket  "); errStr != "" {
+		t.Fatalf("rename: %s", errStr)
+	}
+	if errStr := phone.ImportEvents(laptop.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import rename: %s", errStr)
+	}
+	if got := decodeDevices(t, phone.ListDevices())[0]; got.ID != phoneID || got.Name != "Pocket" {
+		t.Fatalf("expected rename to reach the phone, got %+v", got)
+	}
+	if !hasError(laptop.RenameDevice("unknown", "Name")) || !hasError(laptop.RenameDevice(phoneID, " ")) {
+		t.Fatalf("expected invalid renames to fail")
+	}
+}
+
+func TestRevokedDeviceEventsAreIgnored(t *testing.T) {
+	phone := newTestCore(t)
+	laptop := newTestCore(t)
+	shareKeys(t, phone, laptop)
+
+	before := createTask(t, laptop, TaskDTO{Title: "Before revoke"})
+	if errStr := phone.ImportEvents(laptop.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import: %s", errStr)
+	}
+	laptopID, err := laptop.localDeviceID()
+	if err != nil {
+		t.Fatalf("laptop device id: %v", err)
+	}
+	if errStr := phone.RevokeDevice(laptopID); errStr != "" {
+		t.Fatalf("revoke: %s", errStr)
+	}
+	if !hasError(phone.RevokeDevice(laptopID)) {
+		t.Fatalf("expected a second revoke to fail")
+	}
+	phoneID, _ := phone.localDeviceID()
+	if !hasError(phone.RevokeDevice(phoneID)) {
+		t.Fatalf("expected revoking this device to fail")
+	}
+
+	after := createTask(t, laptop, TaskDTO{Title: "After revoke"})
+	if errStr := phone.ImportEvents(laptop.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import after revoke: %s", errStr)
+	}
+	if _, ok := taskByID(t, phone, before.ID); !ok {
+		t.Fatalf("expected events before the revocation to stay")
+	}
+	if _, ok := taskByID(t, phone, after.ID); ok {
+		t.Fatalf("expected events after the revocation to be ignored")
+	}
+	revoked, _ := deviceByID(decodeDevices(t, phone.ListDevices()), laptopID)
+	if !revoked.Revoked || revoked.RevokedSeq != 1 {
+		t.Fatalf("unexpected revoked entry: %+v", revoked)
+	}
+}
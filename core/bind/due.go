@@ -0,0 +1,62 @@
+package bind
+
+import (
+	"fmt"
+	"time"
+
+	"taskpp/core/logic"
+)
+
+// SetDueDateTime sets the due date, optional "HH:MM" time and optional IANA
+// zone of a task. An empty zone keeps the time floating in the viewer's zone.
+func (c *Core) SetDueDateTime(taskID string, dueDate string, dueTime string, timeZone string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if taskID == "" {
+		return errorJSON("missing id")
+	}
+	dueDate = c.resolveDate(dueDate)
+	if err := logic.ValidateDue(dueDate, dueTime, timeZone); err != nil {
+		return errorJSON(fmt.Sprintf("validate due: %v", err))
+	}
+	task, err := c.store.GetTask(taskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load task: %v", err))
+	}
+	if task.ID == "" {
+		return errorJSON("task not found")
+	}
+	parsed, err := parseDate(dueDate)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("parse due_date: %v", err))
+	}
+	task.DueDate = parsed
+	task.DueTime = dueTime
+	task.TimeZone = timeZone
+	task.UpdatedAt = time.Now().UTC()
+	if err := c.store.UpsertTask(task); err != nil {
+		return errorJSON(fmt.Sprintf("set due date: %v", err))
+	}
+	if err := c.appendEvent("set_due_date", task); err != nil {
+		return errorJSON(fmt.Sprintf("event set due date: %v", err))
+	}
+	return ""
+}
+
+// resolveDate turns the "today", "tomorrow" and "next_week" (the coming
+// Monday) keywords into dates in the device zone; anything else is returned
+// unchanged.
+func (c *Core) resolveDate(input string) string {
+	switch input {
+	case "today":
+		return formatDate(logic.Today(time.Now(), c.location))
+	case "tomorrow":
+		return formatDate(logic.Today(time.Now(), c.location).AddDate(0, 0, 1))
+	case "next_week":
+		return formatDate(logic.NextWeek(logic.Today(time.Now(), c.location)))
+	}
+	return input
+}
@@ -0,0 +1,55 @@
+package bind
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestZonedDueTimesFilterAndSort(t *testing.T) {
+	core := newTestCore(t)
+
+	allDay := createTask(t, core, TaskDTO{Title: "All day", DueDate: "2026-03-02"})
+	evening := createTask(t, core, TaskDTO{Title: "Call", DueDate: "2026-03-02", DueTime: "20:00", TimeZone: "America/Los_Angeles"})
+	morning := createTask(t, core, TaskDTO{Title: "Standup", DueDate: "2026-03-02", DueTime: "09:00"})
+
+	filter, _ := json.Marshal(TaskFilterDTO{DueDate: "2026-03-02", TimeZone: "America/Los_Angeles"})
+	tasks := decodeTasks(t, core.ListTasks(string(filter)))
+	if len(tasks) != 3 {
+		t.Fatalf("expected 3 tasks on 2026-03-02 in LA, got %+v", tasks)
+	}
+	if tasks[0].ID != morning.ID || tasks[1].ID != evening.ID || tasks[2].ID != allDay.ID {
+		t.Fatalf("expected timed tasks before the all-day deadline, got %+v", tasks)
+	}
+
+	filter, _ = json.Marshal(TaskFilterDTO{DueDate: "2026-03-03", TimeZone: "Asia/Tokyo"})
+	tasks = decodeTasks(t, core.ListTasks(string(filter)))
+	if len(tasks) != 1 || tasks[0].ID != evening.ID {
+		t.Fatalf("expected LA evening call on the next day in Tokyo, got %+v", tasks)
+	}
+
+	if out := core.CreateTask(`{"title":"Bad","due_time":"09:00"}`); !hasError(out) {
+		t.Fatalf("expected error for time without date, got %s", out)
+	}
+}
+
+func TestTodayUsesDeviceZone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bind.db")
+	cfgJSON, _ := json.Marshal(Config{StoragePath: "file:" + path, TimeZone: "Pacific/Kiritimati"})
+	core := NewCore(string(cfgJSON))
+	if errStr := core.Open(); errStr != "" {
+		t.Fatalf("open: %s", errStr)
+	}
+	defer core.Close()
+	if errStr := core.InitKeys("passphrase"); errStr != "" {
+		t.Fatalf("init keys: %s", errStr)
+	}
+
+	kiritimati, _ := time.LoadLocation("Pacific/Kiritimati")
+	want := time.Now().In(kiritimati).Format("2006-01-02")
+	task := createTask(t, core, TaskDTO{Title: "Today", DueDate: "today"})
+	if task.DueDate != want {
+		t.Fatalf("expected today in device zone %s, got %s", want, task.DueDate)
+	}
+}
@@ -0,0 +1,158 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// TaskVersionDTO is one logged snapshot of a task. Changes lists the fields
+// that differ from the previous version (from the empty task for the first).
+type TaskVersionDTO struct {
+	EventID  string           `json:"event_id"`
+	DeviceID string           `json:"device_id"`
+	Local    bool             `json:"local"`
+	Seq      int64            `json:"seq"`
+	TS       string           `json:"ts"`
+	Type     string           `json:"type"`
+	Task     TaskDTO          `json:"task"`
+	Changes  []FieldChangeDTO `json:"changes"`
+}
+
+// FieldChangeDTO is one changed TaskDTO field, named by its JSON key.
+type FieldChangeDTO struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// historyIgnoredFields change on every write or are computed on output.
+var historyIgnoredFields = map[string]bool{
+	"updated_at": true,
+	"blocked":    true,
+}
+
+// TaskHistory returns JSON-encoded TaskVersionDTO for every logged version
+// of a task, oldest first, including versions synced from other devices.
+func (c *Core) TaskHistory(taskID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return errorJSON("keys not unlocked")
+	}
+	if taskID == "" {
+		return errorJSON("missing id")
+	}
+	log, err := c.taskEventLog()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	entries := log.byTask[taskID]
+	if len(entries) == 0 {
+		return errorJSON("task not found")
+	}
+	localID, err := c.localDeviceID()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	out := make([]TaskVersionDTO, 0, len(entries))
+	var previous TaskDTO
+	for _, entry := range entries {
+		changes, err := diffTaskDTO(previous, entry.dto)
+		if err != nil {
+			return errorJSON(fmt.Sprintf("diff versions: %v", err))
+		}
+		out = append(out, TaskVersionDTO{
+			EventID:  entry.id,
+			DeviceID: entry.deviceID,
+			Local:    entry.deviceID == localID,
+			Seq:      entry.seq,
+			TS:       formatTime(entry.ts),
+			Type:     entry.kind,
+			Task:     entry.dto,
+			Changes:  changes,
+		})
+		previous = entry.dto
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode history: %v", err))
+	}
+	return string(data)
+}
+
+// RestoreTaskVersion makes a logged version the current task again, as a
+// "restore" event. It also brings back a deleted task and checklist items
+// removed since that version. Returns empty string on success.
+func (c *Core) RestoreTaskVersion(taskID string, eventID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return errorJSON("keys not unlocked")
+	}
+	if taskID == "" || eventID == "" {
+		return errorJSON("missing id")
+	}
+	log, err := c.taskEventLog()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	entry, ok := log.byID[eventID]
+	if !ok || entry.taskID != taskID {
+		return errorJSON("version not found")
+	}
+	if entry.kind == "delete" {
+		return errorJSON("cannot restore a deletion")
+	}
+	if err := c.restoreTask(entry.dto, "restore", time.Now().UTC()); err != nil {
+		return errorJSON(err.Error())
+	}
+	return ""
+}
+
+// diffTaskDTO compares two snapshots field by field through their JSON form,
+// so the field names match the API.
+func diffTaskDTO(from, to TaskDTO) ([]FieldChangeDTO, error) {
+	before, err := dtoFields(from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := dtoFields(to)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(after))
+	for key := range after {
+		if !historyIgnoredFields[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	changes := make([]FieldChangeDTO, 0)
+	for _, key := range keys {
+		if !reflect.DeepEqual(before[key], after[key]) {
+			changes = append(changes, FieldChangeDTO{Field: key, From: before[key], To: after[key]})
+		}
+	}
+	return changes, nil
+}
+
+func dtoFields(dto TaskDTO) (map[string]any, error) {
+	data, err := json.Marshal(dto)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]any)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,109 @@
+package bind
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func decodeHistory(t *testing.T, out string) []TaskVersionDTO {
+	t.Helper()
+	var versions []TaskVersionDTO
+	if err := json.Unmarshal([]byte(out), &versions); err != nil {
+		t.Fatalf("decode history: %v (%s)", err, out)
+	}
+	return versions
+}
+
+func TestTaskHistoryDiffsAndRestore(t *testing.T) {
+	core := newTestCore(t)
+	task := createTask(t, core, TaskDTO{Title: "Draft", Priority: "low"})
+	task.Title = "Final"
+	task.Priority = "high"
+	payload, _ := json.Marshal(task)
+	if out := core.UpdateTask(string(payload)); hasError(out) {
+		t.Fatalf("update: %s", out)
+	}
+
+	versions := decodeHistory(t, core.TaskHistory(task.ID))
+	if len(versions) != 2 || versions[0].Type != "create" || !versions[1].Local {
+		t.Fatalf("unexpected versions: %+v", versions)
+	}
+	changes := versions[1].Changes
+	if len(changes) != 2 || changes[0].Field != "priority" || changes[1].Field != "title" ||
+		changes[1].From != "Draft" || changes[1].To != "Final" {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+
+	if errStr := core.RestoreTaskVersion(task.ID, versions[0].EventID); errStr != "" {
+		t.Fatalf("restore: %s", errStr)
+	}
+	if got, _ := taskByID(t, core, task.ID); got.Title != "Draft" || got.Priority != "low" {
+		t.Fatalf("expected first version restored, got %+v", got)
+	}
+	versions = decodeHistory(t, core.TaskHistory(task.ID))
+	if len(versions) != 3 || versions[2].Type != "restore" {
+		t.Fatalf("expected restore to be logged, got %+v", versions)
+	}
+	if !hasError(core.RestoreTaskVersion("other", versions[0].EventID)) {
+		t.Fatalf("expected version of another task to be rejected")
+	}
+}
+
+func TestRestoreDeletedTask(t *testing.T) {
+	core := newTestCore(t)
+	task := createTask(t, core, TaskDTO{Title: "Keep me"})
+	if errStr := core.DeleteTask(task.ID); errStr != "" {
+		t.Fatalf("delete: %s", errStr)
+	}
+	versions := decodeHistory(t, core.TaskHistory(task.ID))
+	if len(versions) != 2 || versions[1].Type != "delete" {
+		t.Fatalf("expected history to survive the delete, got %+v", versions)
+	}
+	if !hasError(core.RestoreTaskVersion(task.ID, versions[1].EventID)) {
+		t.Fatalf("expected restoring the deletion itself to fail")
+	}
+	if errStr := core.RestoreTaskVersion(task.ID, versions[0].EventID); errStr != "" {
+		t.Fatalf("restore: %s", errStr)
+	}
+	if _, ok := taskByID(t, core, task.ID); !ok {
+		t.Fatalf("expected deleted task to come back")
+	}
+}
+
+func TestRestoreVersionWithLaterRemovedChecklistItem(t *testing.T) {
+	core := newTestCore(t)
+	task := createTask(t, core, TaskDTO{Title: "Pack"})
+	var item ChecklistItemDTO
+	if err := json.Unmarshal([]byte(core.AddChecklistItem(task.ID, "Socks")), &item); err != nil {
+		t.Fatalf("add item: %v", err)
+	}
+	if errStr := core.ToggleChecklistItem(task.ID, item.ID); errStr != "" {
+		t.Fatalf("toggle: %s", errStr)
+	}
+	if errStr := core.RemoveChecklistItem(task.ID, item.ID); errStr != "" {
+		t.Fatalf("remove item: %s", errStr)
+	}
+
+	versions := decodeHistory(t, core.TaskHistory(task.ID))
+	if len(versions) != 4 || versions[2].Type != "checklist_toggle" {
+		t.Fatalf("unexpected versions: %+v", versions)
+	}
+	if errStr := core.RestoreTaskVersion(task.ID, versions[2].EventID); errStr != "" {
+		t.Fatalf("restore: %s", errStr)
+	}
+	got, _ := taskByID(t, core, task.ID)
+	if len(got.Checklist) != 1 || got.Checklist[0].ID != item.ID || !got.Checklist[0].Checked {
+		t.Fatalf("expected the removed item to come back ticked, got %+v", got.Checklist)
+	}
+	if len(got.ChecklistRemoved) != 0 {
+		t.Fatalf("expected the tombstone to be cleared, got %v", got.ChecklistRemoved)
+	}
+
+	// Restoring the version before the item existed removes it again.
+	if errStr := core.RestoreTaskVersion(task.ID, versions[0].EventID); errStr != "" {
+		t.Fatalf("restore first version: %s", errStr)
+	}
+	if got, _ := taskByID(t, core, task.ID); len(got.Checklist) != 0 || len(got.ChecklistRemoved) != 1 {
+		t.Fatalf("expected the item removed again, got %+v", got)
+	}
+}
@@ -0,0 +1,232 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"taskpp/core/model"
+	"taskpp/core/sync"
+)
+
+// LANStatusDTO describes the running LAN sync server.
+type LANStatusDTO struct {
+	DeviceID         string `json:"device_id"`
+	Address          string `json:"address"`
+	DiscoveryAddress string `json:"discovery_address"`
+}
+
+// PeerDTO is a device found on the local network. Name comes from the
+// device registry and is empty for devices not announced yet.
+type PeerDTO struct {
+	DeviceID string `json:"device_id"`
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+}
+
+// StartLANSync accepts sync sessions from devices of the same vault on
+// listenAddr (e.g. ":0" for any free port) and answers their discovery
+// queries. Sessions import events while the app keeps running. Returns
+// JSON-encoded LANStatusDTO.
+func (c *Core) StartLANSync(listenAddr string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return errorJSON("keys not unlocked")
+	}
+	if c.lan != nil {
+		return errorJSON("LAN sync already running")
+	}
+	authKey, err := c.lanAuthKey()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	localID, err := c.localDeviceID()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	if listenAddr == "" {
+		listenAddr = ":0"
+	}
+	server := sync.NewLANServer(authKey, localID, lanStore{c})
+	if err := server.Start(listenAddr, c.discoveryAddr); err != nil {
+		return errorJSON(fmt.Sprintf("start LAN sync: %v", err))
+	}
+	c.lan = server
+	data, err := json.Marshal(LANStatusDTO{
+		DeviceID:         localID,
+		Address:          server.Addr(),
+		DiscoveryAddress: server.DiscoveryAddr(),
+	})
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode status: %v", err))
+	}
+	return string(data)
+}
+
+// StopLANSync stops the LAN sync server. Returns empty string on success.
+func (c *Core) StopLANSync() string {
+	c.mu.Lock()
+	server := c.lan
+	c.lan = nil
+	c.mu.Unlock()
+	if server == nil {
+		return errorJSON("LAN sync not running")
+	}
+	// Running sessions take the lock to import, so wait for them without it.
+	if err := server.Close(); err != nil {
+		return errorJSON(fmt.Sprintf("stop LAN sync: %v", err))
+	}
+	return ""
+}
+
+// DiscoverPeers asks the local network for devices of the same vault and
+// waits up to timeoutMillis for answers. Returns JSON-encoded PeerDTO list.
+func (c *Core) DiscoverPeers(timeoutMillis int64) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return errorJSON("keys not unlocked")
+	}
+	if timeoutMillis <= 0 {
+		return errorJSON("timeout must be positive")
+	}
+	authKey, err := c.lanAuthKey()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	peers, err := sync.DiscoverPeers(c.discoveryAddr, authKey, time.Duration(timeoutMillis)*time.Millisecond)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("discover peers: %v", err))
+	}
+	localID, err := c.localDeviceID()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	out := make([]PeerDTO, 0, len(peers))
+	for _, peer := range peers {
+		if peer.DeviceID == localID {
+			continue
+		}
+		device, err := c.store.GetDevice(peer.DeviceID)
+		if err != nil {
+			return errorJSON(fmt.Sprintf("get device: %v", err))
+		}
+		if device.Revoked {
+			continue
+		}
+		out = append(out, PeerDTO{DeviceID: peer.DeviceID, Name: device.Name, Address: peer.Addr})
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode peers: %v", err))
+	}
+	return string(data)
+}
+
+// SyncWithPeer exchanges missing events with the LAN peer at address, in
+// both directions. Returns JSON-encoded SyncResultDTO.
+func (c *Core) SyncWithPeer(address string) string {
+	c.mu.Lock()
+	authKey, localID, errStr := c.peerSession(address)
+	c.mu.Unlock()
+	if errStr != "" {
+		return errStr
+	}
+	// The session takes the lock per store call, so the peer can sync with
+	// this device at the same time.
+	result, err := sync.SyncWithPeer(address, authKey, localID, lanStore{c})
+	if err != nil {
+		return errorJSON(fmt.Sprintf("sync with peer: %v", err))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	state, err := c.store.GetSyncState()
+	if err != nil {
+		return errorJSON(fmt.Sprintf("get sync state: %v", err))
+	}
+	state.LastSync = time.Now().UTC()
+	if err := c.store.SaveSyncState(state); err != nil {
+		return errorJSON(fmt.Sprintf("save sync state: %v", err))
+	}
+	cursor, err := c.syncCursor()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	data, err := json.Marshal(SyncResultDTO{Pushed: result.Sent, Pulled: result.Received, Cursor: cursor})
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode sync result: %v", err))
+	}
+	return string(data)
+}
+
+// peerSession checks that this device can sync with address and returns the
+// key and device id to authenticate with, or an error JSON.
+func (c *Core) peerSession(address string) ([]byte, string, string) {
+	if c.store == nil {
+		return nil, "", errorJSON("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return nil, "", errorJSON("keys not unlocked")
+	}
+	if address == "" {
+		return nil, "", errorJSON("missing address")
+	}
+	authKey, err := c.lanAuthKey()
+	if err != nil {
+		return nil, "", errorJSON(err.Error())
+	}
+	localID, err := c.localDeviceID()
+	if err != nil {
+		return nil, "", errorJSON(err.Error())
+	}
+	return authKey, localID, ""
+}
+
+// lanAuthKey is the vault subkey LAN peers authenticate each other with.
+func (c *Core) lanAuthKey() ([]byte, error) {
+	return c.keys.Subkey("taskpp lan auth v1", 32)
+}
+
+// lanStore serves a LAN session from the local event log. Sessions run on
+// their own goroutines, so every call takes the Core lock.
+type lanStore struct {
+	c *Core
+}
+
+func (s lanStore) Cursor() (model.Cursor, error) {
+	s.c.mu.Lock()
+	defer s.c.mu.Unlock()
+	return s.c.syncCursor()
+}
+
+func (s lanStore) EventsAfter(cursor model.Cursor) ([]model.Event, error) {
+	s.c.mu.Lock()
+	defer s.c.mu.Unlock()
+	events, err := s.c.store.ListEventsSince(0)
+	if err != nil {
+		return nil, fmt.Errorf("list events: %w", err)
+	}
+	out := make([]model.Event, 0)
+	for _, event := range events {
+		if !sync.Covers(cursor, event) {
+			out = append(out, event)
+		}
+	}
+	return out, nil
+}
+
+func (s lanStore) Import(events []model.Event) error {
+	s.c.mu.Lock()
+	defer s.c.mu.Unlock()
+	return s.c.importEvents(events)
+}
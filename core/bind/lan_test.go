@@ -0,0 +1,115 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func newLANCore(t *testing.T, discoveryAddr string) *Core {
+	t.Helper()
+	cfgJSON, _ := json.Marshal(Config{
+		StorageDriver: "sqlite",
+		StoragePath:   "file:" + filepath.Join(t.TempDir(), "bind.db"),
+		DiscoveryAddr: discoveryAddr,
+	})
+	core := NewCore(string(cfgJSON))
+	if errStr := core.Open(); errStr != "" {
+		t.Fatalf("open: %s", errStr)
+	}
+	t.Cleanup(func() { core.Close() })
+	return core
+}
+
+func TestLANSyncOnLoopback(t *testing.T) {
+	phone := newLANCore(t, "127.0.0.1:0")
+	if errStr := phone.InitKeys("passphrase"); errStr != "" {
+		t.Fatalf("init keys: %s", errStr)
+	}
+	fromPhone := createTask(t, phone, TaskDTO{Title: "From phone"})
+	var status LANStatusDTO
+	if out := phone.StartLANSync("127.0.0.1:0"); json.Unmarshal([]byte(out), &status) != nil || hasError(out) {
+		t.Fatalf("start: %s", out)
+	}
+	if !hasError(phone.StartLANSync("127.0.0.1:0")) {
+		t.Fatalf("expected a second start to fail")
+	}
+
+	laptop := newLANCore(t, status.DiscoveryAddress)
+	shareKeys(t, phone, laptop)
+	fromLaptop := createTask(t, laptop, TaskDTO{Title: "From laptop"})
+
+	var peers []PeerDTO
+	if out := laptop.DiscoverPeers(500); json.Unmarshal([]byte(out), &peers) != nil {
+		t.Fatalf("discover: %s", out)
+	}
+	if len(peers) != 1 || peers[0].DeviceID != status.DeviceID || peers[0].Address != status.Address {
+		t.Fatalf("unexpected peers: %+v (want %+v)", peers, status)
+	}
+
+	var result SyncResultDTO
+	if out := laptop.SyncWithPeer(peers[0].Address); json.Unmarshal([]byte(out), &result) != nil || hasError(out) {
+		t.Fatalf("sync: %s", out)
+	}
+	if result.Pushed != 1 || result.Pulled != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if _, ok := taskByID(t, laptop, fromPhone.ID); !ok {
+		t.Fatalf("expected the phone's task on the laptop")
+	}
+	if _, ok := taskByID(t, phone, fromLaptop.ID); !ok {
+		t.Fatalf("expected the laptop's task on the phone")
+	}
+
+	if errStr := phone.StopLANSync(); errStr != "" {
+		t.Fatalf("stop: %s", errStr)
+	}
+	if !hasError(laptop.SyncWithPeer(status.Address)) {
+		t.Fatalf("expected sync with a stopped peer to fail")
+	}
+}
+
+func TestLANImportDuringLocalWrites(t *testing.T) {
+	phone := newLANCore(t, "127.0.0.1:0")
+	if errStr := phone.InitKeys("passphrase"); errStr != "" {
+		t.Fatalf("init keys: %s", errStr)
+	}
+	var status LANStatusDTO
+	if out := phone.StartLANSync("127.0.0.1:0"); json.Unmarshal([]byte(out), &status) != nil || hasError(out) {
+		t.Fatalf("start: %s", out)
+	}
+	laptop := newLANCore(t, status.DiscoveryAddress)
+	shareKeys(t, phone, laptop)
+
+	const rounds = 5
+	done := make(chan string)
+	go func() {
+		for i := 0; i < rounds; i++ {
+			if out := laptop.CreateTask(fmt.Sprintf(`{"title":"Laptop %d"}`, i)); hasError(out) {
+				done <- out
+				return
+			}
+			if out := laptop.SyncWithPeer(status.Address); hasError(out) {
+				done <- out
+				return
+			}
+		}
+		done <- ""
+	}()
+	for i := 0; i < rounds; i++ {
+		createTask(t, phone, TaskDTO{Title: fmt.Sprintf("Phone %d", i)})
+	}
+	if errStr := <-done; errStr != "" {
+		t.Fatalf("sync: %s", errStr)
+	}
+
+	if out := laptop.SyncWithPeer(status.Address); hasError(out) {
+		t.Fatalf("final sync: %s", out)
+	}
+	for name, core := range map[string]*Core{"phone": phone, "laptop": laptop} {
+		if tasks := decodeTasks(t, core.ListTasks("")); len(tasks) != 2*rounds {
+			t.Fatalf("expected %d tasks on the %s, got %d", 2*rounds, name, len(tasks))
+		}
+	}
+}
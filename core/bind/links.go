@@ -0,0 +1,188 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"taskpp/core/logic"
+	"taskpp/core/model"
+)
+
+// LinkDTO is a bind-safe task link. Type is "url", "tel", "mailto" or "file".
+type LinkDTO struct {
+	Type  string `json:"type"`
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// LaunchLinkDTO is a link ready to render: Href is the URI the platform
+// opens, and Detected marks links found in the title or description rather
+// than stored on the task.
+type LaunchLinkDTO struct {
+	Type     string `json:"type"`
+	Label    string `json:"label"`
+	Value    string `json:"value"`
+	Href     string `json:"href"`
+	Detected bool   `json:"detected"`
+}
+
+// TaskLinks returns JSON-encoded LaunchLinkDTO for a task: its stored links
+// first, then links detected in its title and description.
+func (c *Core) TaskLinks(taskID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	task, err := c.store.GetTask(taskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load task: %v", err))
+	}
+	if task.ID == "" {
+		return errorJSON("task not found")
+	}
+	out := make([]LaunchLinkDTO, 0, len(task.Links))
+	seen := make(map[string]bool)
+	for _, link := range task.Links {
+		href := logic.LinkHref(link)
+		seen[href] = true
+		out = append(out, launchLink(link, false))
+	}
+	for _, link := range logic.ExtractLinks(task.Title + "\n" + task.Description) {
+		if !seen[logic.LinkHref(link)] {
+			out = append(out, launchLink(link, true))
+		}
+	}
+	return encodeLaunchLinks(out)
+}
+
+// ExtractLinks returns JSON-encoded LaunchLinkDTO for links found in text,
+// so editors can preview them before saving.
+func (c *Core) ExtractLinks(text string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	links := logic.ExtractLinks(text)
+	out := make([]LaunchLinkDTO, 0, len(links))
+	for _, link := range links {
+		out = append(out, launchLink(link, true))
+	}
+	return encodeLaunchLinks(out)
+}
+
+// AddLink stores LinkDTO JSON on a task. Returns empty string on success.
+func (c *Core) AddLink(taskID string, linkJSON string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var dto LinkDTO
+	if err := json.Unmarshal([]byte(linkJSON), &dto); err != nil {
+		return errorJSON(fmt.Sprintf("decode link: %v", err))
+	}
+	link := dtoToLinks([]LinkDTO{dto})[0]
+	if err := logic.ValidateLink(link); err != nil {
+		return errorJSON(fmt.Sprintf("validate link: %v", err))
+	}
+	return c.editLinks(taskID, "link_add", func(task *model.Task) error {
+		href := logic.LinkHref(link)
+		for _, existing := range task.Links {
+			if logic.LinkHref(existing) == href {
+				return fmt.Errorf("link already on task")
+			}
+		}
+		task.Links = append(task.Links, link)
+		return nil
+	})
+}
+
+// RemoveLink drops the stored link with the given value. Returns empty string
+// on success.
+func (c *Core) RemoveLink(taskID string, value string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.editLinks(taskID, "link_remove", func(task *model.Task) error {
+		for i, link := range task.Links {
+			if link.Value == strings.TrimSpace(value) {
+				task.Links = append(task.Links[:i], task.Links[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("link not found")
+	})
+}
+
+func (c *Core) editLinks(taskID string, eventType string, mutate func(task *model.Task) error) string {
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if taskID == "" {
+		return errorJSON("missing id")
+	}
+	task, err := c.store.GetTask(taskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load task: %v", err))
+	}
+	if task.ID == "" {
+		return errorJSON("task not found")
+	}
+	if err := mutate(&task); err != nil {
+		return errorJSON(fmt.Sprintf("%s: %v", eventType, err))
+	}
+	task.UpdatedAt = time.Now().UTC()
+	if err := c.store.UpsertTask(task); err != nil {
+		return errorJSON(fmt.Sprintf("update task: %v", err))
+	}
+	if err := c.appendEvent(eventType, task); err != nil {
+		return errorJSON(fmt.Sprintf("event %s: %v", eventType, err))
+	}
+	return ""
+}
+
+func validateLinks(links []model.Link) error {
+	for _, link := range links {
+		if err := logic.ValidateLink(link); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func launchLink(link model.Link, detected bool) LaunchLinkDTO {
+	return LaunchLinkDTO{
+		Type:     link.Type,
+		Label:    link.Label,
+		Value:    link.Value,
+		Href:     logic.LinkHref(link),
+		Detected: detected,
+	}
+}
+
+func encodeLaunchLinks(links []LaunchLinkDTO) string {
+	data, err := json.Marshal(links)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode links: %v", err))
+	}
+	return string(data)
+}
+
+func linksToDTO(links []model.Link) []LinkDTO {
+	if len(links) == 0 {
+		return nil
+	}
+	out := make([]LinkDTO, 0, len(links))
+	for _, link := range links {
+		out = append(out, LinkDTO{Type: link.Type, Label: link.Label, Value: link.Value})
+	}
+	return out
+}
+
+func dtoToLinks(dtos []LinkDTO) []model.Link {
+	if len(dtos) == 0 {
+		return nil
+	}
+	out := make([]model.Link, 0, len(dtos))
+	for _, dto := range dtos {
+		out = append(out, model.Link{Type: dto.Type, Label: strings.TrimSpace(dto.Label), Value: strings.TrimSpace(dto.Value)})
+	}
+	return out
+}
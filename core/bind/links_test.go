@@ -0,0 +1,45 @@
+package bind
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTaskLinksCombineStoredAndDetected(t *testing.T) {
+	core := newTestCore(t)
+	task := createTask(t, core, TaskDTO{
+		Title:       "Call plumber +1 555 010 7788",
+		Description: "Quote at https://example.com/quote",
+		Links:       []LinkDTO{{Type: "url", Label: "Quote", Value: "https://example.com/quote"}},
+	})
+	if !hasError(core.AddLink(task.ID, `{"type":"url","value":"ftp://example.com"}`)) {
+		t.Fatalf("expected invalid link to be rejected")
+	}
+	if errStr := core.AddLink(task.ID, `{"type":"mailto","label":"Office","value":"office@example.com"}`); errStr != "" {
+		t.Fatalf("add link: %s", errStr)
+	}
+
+	var links []LaunchLinkDTO
+	if err := json.Unmarshal([]byte(core.TaskLinks(task.ID)), &links); err != nil {
+		t.Fatalf("decode links: %v", err)
+	}
+	if len(links) != 3 {
+		t.Fatalf("expected 2 stored + 1 detected link, got %+v", links)
+	}
+	if links[0].Label != "Quote" || links[0].Detected {
+		t.Fatalf("expected stored link first, got %+v", links[0])
+	}
+	if links[1].Href != "mailto:office@example.com" {
+		t.Fatalf("unexpected mailto href: %+v", links[1])
+	}
+	if !links[2].Detected || links[2].Href != "tel:+15550107788" {
+		t.Fatalf("expected detected phone link, got %+v", links[2])
+	}
+
+	if errStr := core.RemoveLink(task.ID, "office@example.com"); errStr != "" {
+		t.Fatalf("remove link: %s", errStr)
+	}
+	if tasks := decodeTasks(t, core.ListTasks("")); len(tasks[0].Links) != 1 {
+		t.Fatalf("expected one stored link left, got %+v", tasks[0].Links)
+	}
+}
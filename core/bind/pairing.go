@@ -0,0 +1,273 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"taskpp/core/logic"
+	"taskpp/core/model"
+	"taskpp/core/sync"
+)
+
+// PairingDTO is a pairing offer to show on a device already in the vault:
+// the code to type, or the payload to put in a QR code.
+type PairingDTO struct {
+	Code      string `json:"code"`
+	Address   string `json:"address"`
+	Payload   string `json:"payload"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// PairingStatusDTO reports the latest pairing offer. State is "none",
+// "waiting", "paired" or "failed"; DeviceID and Name describe the device
+// that joined.
+type PairingStatusDTO struct {
+	State    string `json:"state"`
+	DeviceID string `json:"device_id"`
+	Name     string `json:"name"`
+	Error    string `json:"error"`
+}
+
+// pairingGrant is what a joining device receives: the key state, the vault
+// key wrapped under the pairing session key and a snapshot to start from.
+type pairingGrant struct {
+	KDF        string      `json:"kdf"`
+	Salt       []byte      `json:"salt"`
+	WrappedKey []byte      `json:"wrapped_key"`
+	Snapshot   SnapshotDTO `json:"snapshot"`
+}
+
+// StartPairing offers the vault to one new device for a few minutes. The
+// new device calls CompletePairing with the payload (or address and code).
+// Returns JSON-encoded PairingDTO.
+func (c *Core) StartPairing() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return errorJSON("keys not unlocked")
+	}
+	if c.pairing != nil {
+		select {
+		case <-c.pairing.Done():
+		default:
+			return errorJSON("pairing already running")
+		}
+	}
+	localID, err := c.localDeviceID()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	code, err := sync.NewPairingCode()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	host, err := sync.StartPairingHost(":0", code, localID, c.grantPairing)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("start pairing: %v", err))
+	}
+	c.pairing = host
+	data, err := json.Marshal(PairingDTO{
+		Code:      sync.FormatPairingCode(code),
+		Address:   host.Addr(),
+		Payload:   sync.PairingPayload(host.Addr(), code),
+		ExpiresAt: formatTime(host.Expires().UTC()),
+	})
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode pairing: %v", err))
+	}
+	return string(data)
+}
+
+// PairingStatus reports whether the latest offer is still waiting, or how
+// it ended. Returns JSON-encoded PairingStatusDTO.
+func (c *Core) PairingStatus() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status := PairingStatusDTO{State: "none"}
+	if c.pairing != nil {
+		select {
+		case <-c.pairing.Done():
+			joiner, err := c.pairing.Result()
+			status = PairingStatusDTO{State: "paired", DeviceID: joiner.DeviceID, Name: joiner.Name}
+			if err != nil {
+				status = PairingStatusDTO{State: "failed", Error: err.Error()}
+			}
+		default:
+			status.State = "waiting"
+		}
+	}
+	data, err := json.Marshal(status)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode pairing status: %v", err))
+	}
+	return string(data)
+}
+
+// CancelPairing withdraws a waiting offer. Returns empty string on success.
+func (c *Core) CancelPairing() string {
+	c.mu.Lock()
+	host := c.pairing
+	c.mu.Unlock()
+	if host == nil {
+		return errorJSON("no pairing running")
+	}
+	// A running session takes the lock to grant, so wait for it without it.
+	host.Close()
+	return ""
+}
+
+// CompletePairing joins the vault offered by StartPairing on another device.
+// payload is the offer's payload, or "<address> <code>" when typed in. Only
+// a new device can join: it receives the vault key and a snapshot of the
+// vault, and unlocks with the vault passphrase from then on. Returns empty
+// string on success.
+func (c *Core) CompletePairing(payload string) string {
+	c.mu.Lock()
+	joiner, errStr := c.pairingJoiner()
+	c.mu.Unlock()
+	if errStr != "" {
+		return errStr
+	}
+	if fields := strings.Fields(payload); len(fields) == 2 {
+		payload = sync.PairingPayload(fields[0], fields[1])
+	}
+	addr, code, err := sync.ParsePairingPayload(payload)
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	// The exchange waits for the other device, so it runs without the lock.
+	data, wrapKey, err := sync.JoinPairing(addr, code, joiner)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("pairing: %v", err))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Another call may have set up keys or written events meanwhile.
+	if _, errStr := c.pairingJoiner(); errStr != "" {
+		return errStr
+	}
+	var grant pairingGrant
+	if err := json.Unmarshal(data, &grant); err != nil {
+		return errorJSON(fmt.Sprintf("decode grant: %v", err))
+	}
+	if err := c.keys.UnwrapKey(wrapKey, grant.WrappedKey); err != nil {
+		return errorJSON(err.Error())
+	}
+	if err := c.importSnapshot(grant.Snapshot); err != nil {
+		return errorJSON(err.Error())
+	}
+	keyState := model.KeyState{Salt: grant.Salt, KDF: grant.KDF, UpdatedAt: time.Now().UTC()}
+	if err := c.store.SaveKeyState(keyState); err != nil {
+		return errorJSON(fmt.Sprintf("save key state: %v", err))
+	}
+	return ""
+}
+
+// pairingJoiner checks that this is a new device that can join a vault and
+// describes it to the host, or returns an error JSON.
+func (c *Core) pairingJoiner() (sync.PairingJoiner, string) {
+	if c.store == nil || c.keys == nil {
+		return sync.PairingJoiner{}, errorJSON("storage not initialized")
+	}
+	state, err := c.store.GetKeyState()
+	if err != nil {
+		return sync.PairingJoiner{}, errorJSON(fmt.Sprintf("get key state: %v", err))
+	}
+	if len(state.Salt) > 0 {
+		return sync.PairingJoiner{}, errorJSON("keys already initialized; pairing needs a new device")
+	}
+	events, err := c.store.ListEventsSince(0)
+	if err != nil {
+		return sync.PairingJoiner{}, errorJSON(fmt.Sprintf("list events: %v", err))
+	}
+	if len(events) > 0 {
+		return sync.PairingJoiner{}, errorJSON("pairing needs a new device")
+	}
+	localID, err := c.localDeviceID()
+	if err != nil {
+		return sync.PairingJoiner{}, errorJSON(err.Error())
+	}
+	return sync.PairingJoiner{DeviceID: localID, Name: c.deviceName, Platform: c.platform}, ""
+}
+
+// grantPairing registers the joining device and builds its grant. The
+// snapshot is sealed after the registration, so the new device starts out
+// knowing itself and this device. It runs on the pairing host's goroutine,
+// so it takes the Core lock.
+func (c *Core) grantPairing(joiner sync.PairingJoiner, wrapKey []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state, err := c.store.GetKeyState()
+	if err != nil {
+		return nil, fmt.Errorf("get key state: %w", err)
+	}
+	wrapped, err := c.keys.WrapKey(wrapKey)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := c.syncCursor()
+	if err != nil {
+		return nil, err
+	}
+	localID, err := c.localDeviceID()
+	if err != nil {
+		return nil, err
+	}
+	stored, err := c.store.GetDevice(localID)
+	if err != nil {
+		return nil, fmt.Errorf("get device: %w", err)
+	}
+	if stored.ID == "" {
+		local, err := c.localDevice()
+		if err != nil {
+			return nil, err
+		}
+		local.Acked = cursor
+		if err := c.saveDevice(local, "device_update"); err != nil {
+			return nil, err
+		}
+	}
+	existing, err := c.store.GetDevice(joiner.DeviceID)
+	if err != nil {
+		return nil, fmt.Errorf("get device: %w", err)
+	}
+	if existing.Revoked {
+		return nil, fmt.Errorf("device %s was revoked", joiner.DeviceID)
+	}
+	now := time.Now().UTC()
+	device := model.Device{ID: joiner.DeviceID, CreatedAt: now, UpdatedAt: now, Platform: joiner.Platform, Acked: cursor}
+	if name := strings.TrimSpace(joiner.Name); logic.ValidateDeviceName(name) == nil {
+		device.Name = name
+	}
+	if err := c.saveDevice(device, "device_update"); err != nil {
+		return nil, err
+	}
+
+	events, err := c.store.ListEventsSince(0)
+	if err != nil {
+		return nil, fmt.Errorf("list events: %w", err)
+	}
+	snapshot, _, err := c.sealSnapshot(events)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(pairingGrant{
+		KDF:        state.KDF,
+		Salt:       state.Salt,
+		WrappedKey: wrapped,
+		Snapshot: SnapshotDTO{
+			Cursor:    snapshot.Cursor,
+			CreatedAt: formatTime(snapshot.CreatedAt),
+			Payload:   snapshot.Payload,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode grant: %w", err)
+	}
+	return data, nil
+}
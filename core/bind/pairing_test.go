@@ -0,0 +1,139 @@
+package bind
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodePairing(t *testing.T, out string) PairingDTO {
+	t.Helper()
+	var offer PairingDTO
+	if err := json.Unmarshal([]byte(out), &offer); err != nil || offer.Payload == "" {
+		t.Fatalf("decode pairing: %v (%s)", err, out)
+	}
+	return offer
+}
+
+func pairingStatus(t *testing.T, core *Core) PairingStatusDTO {
+	t.Helper()
+	var status PairingStatusDTO
+	if err := json.Unmarshal([]byte(core.PairingStatus()), &status); err != nil {
+		t.Fatalf("decode pairing status: %v", err)
+	}
+	return status
+}
+
+func TestPairingBringsNewDeviceIntoVault(t *testing.T) {
+	phone := newTestCore(t)
+	task := createTask(t, phone, TaskDTO{Title: "Before pairing"})
+	if got := pairingStatus(t, phone); got.State != "none" {
+		t.Fatalf("expected no pairing yet, got %+v", got)
+	}
+
+	offer := decodePairing(t, phone.StartPairing())
+	if !hasError(phone.StartPairing()) {
+		t.Fatalf("expected a second offer to fail while one is waiting")
+	}
+	tablet := newLANCore(t, "")
+	wrong := strings.Replace(offer.Payload, strings.ReplaceAll(offer.Code, "-", ""), "00000000", 1)
+	if offer.Code == "0000-0000" {
+		wrong = strings.Replace(offer.Payload, "00000000", "11111111", 1)
+	}
+	if !hasError(tablet.CompletePairing(wrong)) {
+		t.Fatalf("expected a wrong code to fail")
+	}
+	<-phone.pairing.Done()
+	if got := pairingStatus(t, phone); got.State != "failed" {
+		t.Fatalf("expected the wrong code to end the offer, got %+v", got)
+	}
+
+	offer = decodePairing(t, phone.StartPairing())
+	if errStr := tablet.CompletePairing(offer.Address + " " + offer.Code); errStr != "" {
+		t.Fatalf("complete pairing: %s", errStr)
+	}
+	tabletID, _ := tablet.localDeviceID()
+	if got := pairingStatus(t, phone); got.State != "paired" || got.DeviceID != tabletID {
+		t.Fatalf("expected the tablet to be paired, got %+v", got)
+	}
+	if _, ok := taskByID(t, tablet, task.ID); !ok {
+		t.Fatalf("expected the snapshot on the tablet")
+	}
+	if _, ok := deviceByID(decodeDevices(t, tablet.ListDevices()), tabletID); !ok {
+		t.Fatalf("expected the tablet to know itself")
+	}
+	if _, ok := deviceByID(decodeDevices(t, phone.ListDevices()), tabletID); !ok {
+		t.Fatalf("expected the phone to register the tablet")
+	}
+
+	// The vault passphrase unlocks the tablet from now on.
+	if errStr := tablet.UnlockKeys("passphrase"); errStr != "" {
+		t.Fatalf("unlock: %s", errStr)
+	}
+	fromTablet := createTask(t, tablet, TaskDTO{Title: "From tablet"})
+	if errStr := phone.ImportEvents(tablet.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import: %s", errStr)
+	}
+	if _, ok := taskByID(t, phone, fromTablet.ID); !ok {
+		t.Fatalf("expected the tablet's task on the phone")
+	}
+	if !hasError(tablet.CompletePairing(offer.Payload)) {
+		t.Fatalf("expected pairing an initialized device to fail")
+	}
+}
+
+func TestPairingGrantDuringLocalWrites(t *testing.T) {
+	phone := newTestCore(t)
+	offer := decodePairing(t, phone.StartPairing())
+	tablet := newLANCore(t, "")
+	done := make(chan string)
+	go func() { done <- tablet.CompletePairing(offer.Payload) }()
+	for i := 0; i < 5; i++ {
+		createTask(t, phone, TaskDTO{Title: "While pairing"})
+	}
+	if errStr := <-done; errStr != "" {
+		t.Fatalf("complete pairing: %s", errStr)
+	}
+	if got := pairingStatus(t, phone); got.State != "paired" {
+		t.Fatalf("expected the tablet to be paired, got %+v", got)
+	}
+	if errStr := tablet.UnlockKeys("passphrase"); errStr != "" {
+		t.Fatalf("unlock: %s", errStr)
+	}
+	if errStr := tablet.ImportEvents(phone.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import: %s", errStr)
+	}
+	if tasks := decodeTasks(t, tablet.ListTasks("")); len(tasks) != 5 {
+		t.Fatalf("expected every task on the tablet, got %d", len(tasks))
+	}
+}
+
+func TestCompletePairingDoesNotHoldTheLock(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	tablet := newLANCore(t, "")
+	done := make(chan string, 1)
+	go func() { done <- tablet.CompletePairing(listener.Addr().String() + " 1234-5678") }()
+
+	// The host accepts and never answers, so the join waits.
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	status := make(chan string, 1)
+	go func() { status <- tablet.PairingStatus() }()
+	select {
+	case <-status:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the device to stay usable while joining")
+	}
+	conn.Close()
+	if !hasError(<-done) {
+		t.Fatalf("expected the silent host to fail the join")
+	}
+}
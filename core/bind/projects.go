@@ -0,0 +1,309 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"taskpp/core/logic"
+	"taskpp/core/model"
+	"taskpp/core/sync"
+)
+
+// ProjectDTO is a bind-safe project representation.
+type ProjectDTO struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	Order     int64  `json:"order"`
+	Archived  bool   `json:"archived"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// Project delete policies for the tasks inside a deleted project.
+const (
+	ProjectDeleteUnassign = "unassign"
+	ProjectDeleteTasks    = "delete"
+)
+
+// ListProjects returns a JSON-encoded list of ProjectDTO.
+func (c *Core) ListProjects(includeArchived bool) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	projects, err := c.store.ListProjects()
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list projects: %v", err))
+	}
+	out := make([]ProjectDTO, 0, len(projects))
+	for _, project := range projects {
+		if project.Archived && !includeArchived {
+			continue
+		}
+		out = append(out, projectToDTO(project))
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode projects: %v", err))
+	}
+	return string(data)
+}
+
+// CreateProject accepts ProjectDTO JSON and returns ProjectDTO JSON.
+func (c *Core) CreateProject(projectJSON string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	var dto ProjectDTO
+	if err := json.Unmarshal([]byte(projectJSON), &dto); err != nil {
+		return errorJSON(fmt.Sprintf("decode project: %v", err))
+	}
+	if dto.ID == "" {
+		dto.ID = uuid.NewString()
+	}
+	now := time.Now().UTC()
+	if dto.CreatedAt == "" {
+		dto.CreatedAt = now.Format(time.RFC3339Nano)
+	}
+	dto.UpdatedAt = now.Format(time.RFC3339Nano)
+	project, err := dtoToProject(dto)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("convert project: %v", err))
+	}
+	if err := logic.ValidateProject(project.Name, project.Color); err != nil {
+		return errorJSON(fmt.Sprintf("validate project: %v", err))
+	}
+	return c.saveProject("project_create", project)
+}
+
+// UpdateProject accepts ProjectDTO JSON and returns ProjectDTO JSON.
+func (c *Core) UpdateProject(projectJSON string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	var dto ProjectDTO
+	if err := json.Unmarshal([]byte(projectJSON), &dto); err != nil {
+		return errorJSON(fmt.Sprintf("decode project: %v", err))
+	}
+	if dto.ID == "" {
+		return errorJSON("missing id")
+	}
+	existing, err := c.store.GetProject(dto.ID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load project: %v", err))
+	}
+	if existing.ID == "" {
+		return errorJSON("project not found")
+	}
+	dto.CreatedAt = formatTime(existing.CreatedAt)
+	dto.UpdatedAt = time.Now().UTC().Format(time.RFC3339Nano)
+	project, err := dtoToProject(dto)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("convert project: %v", err))
+	}
+	if err := logic.ValidateProject(project.Name, project.Color); err != nil {
+		return errorJSON(fmt.Sprintf("validate project: %v", err))
+	}
+	return c.saveProject("project_update", project)
+}
+
+// RenameProject changes a project's name and returns ProjectDTO JSON.
+func (c *Core) RenameProject(projectID string, name string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if projectID == "" {
+		return errorJSON("missing id")
+	}
+	project, err := c.store.GetProject(projectID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load project: %v", err))
+	}
+	if project.ID == "" {
+		return errorJSON("project not found")
+	}
+	if err := logic.ValidateProject(name, project.Color); err != nil {
+		return errorJSON(fmt.Sprintf("validate project: %v", err))
+	}
+	project.Name = name
+	project.UpdatedAt = time.Now().UTC()
+	return c.saveProject("project_rename", project)
+}
+
+// DeleteProject deletes a project. The policy decides what happens to its
+// tasks: "unassign" (default) moves them out of the project, "delete"
+// deletes them. Returns empty string on success.
+func (c *Core) DeleteProject(projectID string, policy string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	defer c.undoGroup()()
+	if projectID == "" {
+		return errorJSON("missing id")
+	}
+	if policy == "" {
+		policy = ProjectDeleteUnassign
+	}
+	if policy != ProjectDeleteUnassign && policy != ProjectDeleteTasks {
+		return errorJSON(fmt.Sprintf("invalid delete policy: %s", policy))
+	}
+	project, err := c.store.GetProject(projectID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load project: %v", err))
+	}
+	if project.ID == "" {
+		return errorJSON("project not found")
+	}
+	tasks, err := c.store.ListTasks(model.TaskFilter{ProjectID: &projectID})
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list project tasks: %v", err))
+	}
+	for _, task := range tasks {
+		if policy == ProjectDeleteTasks {
+			// Subtasks go with their parent, even outside the project; skip
+			// tasks already removed that way.
+			current, err := c.store.GetTask(task.ID)
+			if err != nil {
+				return errorJSON(fmt.Sprintf("load task: %v", err))
+			}
+			if current.ID == "" {
+				continue
+			}
+			if err := c.deleteDescendants(task.ID); err != nil {
+				return errorJSON(fmt.Sprintf("delete subtasks: %v", err))
+			}
+			if err := c.store.DeleteTask(task.ID); err != nil {
+				return errorJSON(fmt.Sprintf("delete task: %v", err))
+			}
+			if err := c.store.DeleteTaskComments(task.ID); err != nil {
+				return errorJSON(fmt.Sprintf("delete comments: %v", err))
+			}
+			if err := c.store.DeleteTaskTimeEntries(task.ID); err != nil {
+				return errorJSON(fmt.Sprintf("delete time entries: %v", err))
+			}
+			if err := c.appendEvent("delete", task); err != nil {
+				return errorJSON(fmt.Sprintf("event delete: %v", err))
+			}
+			continue
+		}
+		task.ProjectID = ""
+		task.UpdatedAt = time.Now().UTC()
+		if err := c.store.UpsertTask(task); err != nil {
+			return errorJSON(fmt.Sprintf("unassign task: %v", err))
+		}
+		if err := c.appendEvent("update", task); err != nil {
+			return errorJSON(fmt.Sprintf("event update: %v", err))
+		}
+	}
+	project.UpdatedAt = time.Now().UTC()
+	if err := c.store.DeleteProject(projectID); err != nil {
+		return errorJSON(fmt.Sprintf("delete project: %v", err))
+	}
+	if err := c.appendPayloadEvent("project_delete", projectToDTO(project)); err != nil {
+		return errorJSON(fmt.Sprintf("event project delete: %v", err))
+	}
+	return ""
+}
+
+func (c *Core) saveProject(eventType string, project model.Project) string {
+	if err := c.store.UpsertProject(project); err != nil {
+		return errorJSON(fmt.Sprintf("save project: %v", err))
+	}
+	if err := c.appendPayloadEvent(eventType, projectToDTO(project)); err != nil {
+		return errorJSON(fmt.Sprintf("event %s: %v", eventType, err))
+	}
+	out, err := json.Marshal(projectToDTO(project))
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode project: %v", err))
+	}
+	return string(out)
+}
+
+// checkProjectRef ensures a task only points at an existing project.
+func (c *Core) checkProjectRef(projectID string) error {
+	if projectID == "" {
+		return nil
+	}
+	project, err := c.store.GetProject(projectID)
+	if err != nil {
+		return fmt.Errorf("load project: %w", err)
+	}
+	if project.ID == "" {
+		return fmt.Errorf("project not found: %s", projectID)
+	}
+	return nil
+}
+
+func (c *Core) applyProjectEvent(event model.Event) error {
+	var ref ProjectDTO
+	_ = json.Unmarshal(event.Payload, &ref)
+	if ref.ID == "" {
+		return fmt.Errorf("missing project id in payload")
+	}
+	project, err := c.store.GetProject(ref.ID)
+	if err != nil {
+		return fmt.Errorf("get project: %w", err)
+	}
+	updated, changed, _, err := sync.ApplyProjectEvent(project, event)
+	if err != nil {
+		return fmt.Errorf("apply project event: %w", err)
+	}
+	if !changed {
+		return nil
+	}
+	if event.Type == "project_delete" {
+		if err := c.store.DeleteProject(ref.ID); err != nil {
+			return fmt.Errorf("delete project: %w", err)
+		}
+		return nil
+	}
+	if err := c.store.UpsertProject(updated); err != nil {
+		return fmt.Errorf("upsert project: %w", err)
+	}
+	return nil
+}
+
+func projectToDTO(project model.Project) ProjectDTO {
+	return ProjectDTO{
+		ID:        project.ID,
+		Name:      project.Name,
+		Color:     project.Color,
+		Order:     project.Order,
+		Archived:  project.Archived,
+		CreatedAt: formatTime(project.CreatedAt),
+		UpdatedAt: formatTime(project.UpdatedAt),
+	}
+}
+
+func dtoToProject(dto ProjectDTO) (model.Project, error) {
+	createdAt, err := parseTime(dto.CreatedAt)
+	if err != nil {
+		return model.Project{}, err
+	}
+	updatedAt, err := parseTime(dto.UpdatedAt)
+	if err != nil {
+		return model.Project{}, err
+	}
+	return model.Project{
+		ID:        dto.ID,
+		Name:      dto.Name,
+		Color:     dto.Color,
+		Order:     dto.Order,
+		Archived:  dto.Archived,
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+	}, nil
+}
@@ -0,0 +1,138 @@
+package bind
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectLifecycle(t *testing.T) {
+	core := newTestCore(t)
+
+	created := core.CreateProject(`{"name":"Work","color":"#336699"}`)
+	if hasError(created) {
+		t.Fatalf("create project: %s", created)
+	}
+	var project ProjectDTO
+	if err := json.Unmarshal([]byte(created), &project); err != nil {
+		t.Fatalf("decode project: %v", err)
+	}
+
+	taskJSON, _ := json.Marshal(TaskDTO{Title: "Ship", ProjectID: project.ID})
+	if out := core.CreateTask(string(taskJSON)); hasError(out) {
+		t.Fatalf("create task: %s", out)
+	}
+	if out := core.CreateTask(`{"title":"Loose"}`); hasError(out) {
+		t.Fatalf("create loose task: %s", out)
+	}
+	if out := core.CreateTask(`{"title":"Bad","project_id":"missing"}`); !hasError(out) {
+		t.Fatalf("expected unknown project error, got %s", out)
+	}
+
+	filter, _ := json.Marshal(TaskFilterDTO{ProjectID: &project.ID})
+	tasks := decodeTasks(t, core.ListTasks(string(filter)))
+	if len(tasks) != 1 || tasks[0].Title != "Ship" {
+		t.Fatalf("unexpected project tasks: %+v", tasks)
+	}
+	inbox := ""
+	filter, _ = json.Marshal(TaskFilterDTO{ProjectID: &inbox})
+	tasks = decodeTasks(t, core.ListTasks(string(filter)))
+	if len(tasks) != 1 || tasks[0].Title != "Loose" {
+		t.Fatalf("unexpected inbox tasks: %+v", tasks)
+	}
+
+	if out := core.RenameProject(project.ID, "Office"); hasError(out) {
+		t.Fatalf("rename: %s", out)
+	}
+
+	if errStr := core.DeleteProject(project.ID, ""); errStr != "" {
+		t.Fatalf("delete project: %s", errStr)
+	}
+	var projects []ProjectDTO
+	if err := json.Unmarshal([]byte(core.ListProjects(true)), &projects); err != nil {
+		t.Fatalf("decode projects: %v", err)
+	}
+	if len(projects) != 0 {
+		t.Fatalf("expected no projects, got %+v", projects)
+	}
+	tasks = decodeTasks(t, core.ListTasks(""))
+	if len(tasks) != 2 {
+		t.Fatalf("expected tasks to survive unassign policy, got %d", len(tasks))
+	}
+	for _, task := range tasks {
+		if task.ProjectID != "" {
+			t.Fatalf("expected task to be unassigned: %+v", task)
+		}
+	}
+}
+
+func TestProjectEventsSync(t *testing.T) {
+	source := newTestCore(t)
+	created := source.CreateProject(`{"name":"Home"}`)
+	var project ProjectDTO
+	if err := json.Unmarshal([]byte(created), &project); err != nil {
+		t.Fatalf("decode project: %v", err)
+	}
+	taskJSON, _ := json.Marshal(TaskDTO{Title: "Paint", ProjectID: project.ID})
+	if out := source.CreateTask(string(taskJSON)); hasError(out) {
+		t.Fatalf("create task: %s", out)
+	}
+	if errStr := source.DeleteProject(project.ID, ProjectDeleteTasks); errStr != "" {
+		t.Fatalf("delete project: %s", errStr)
+	}
+
+	target := newTestCore(t)
+	shareKeys(t, source, target)
+	if errStr := target.ImportEvents(source.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import: %s", errStr)
+	}
+	if tasks := decodeTasks(t, target.ListTasks("")); len(tasks) != 0 {
+		t.Fatalf("expected deleted tasks to stay deleted, got %+v", tasks)
+	}
+	var projects []ProjectDTO
+	if err := json.Unmarshal([]byte(target.ListProjects(true)), &projects); err != nil {
+		t.Fatalf("decode projects: %v", err)
+	}
+	if len(projects) != 0 {
+		t.Fatalf("expected project delete to sync, got %+v", projects)
+	}
+}
+
+func newTestCore(t *testing.T) *Core {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bind.db")
+	cfgJSON, _ := json.Marshal(Config{StorageDriver: "sqlite", StoragePath: "file:" + path})
+	core := NewCore(string(cfgJSON))
+	if errStr := core.Open(); errStr != "" {
+		t.Fatalf("open: %s", errStr)
+	}
+	t.Cleanup(func() { core.Close() })
+	if errStr := core.InitKeys("passphrase"); errStr != "" {
+		t.Fatalf("init keys: %s", errStr)
+	}
+	return core
+}
+
+// shareKeys makes target use the same vault key as source.
+func shareKeys(t *testing.T, source, target *Core) {
+	t.Helper()
+	state, err := source.store.GetKeyState()
+	if err != nil {
+		t.Fatalf("get key state: %v", err)
+	}
+	if err := target.store.SaveKeyState(state); err != nil {
+		t.Fatalf("save key state: %v", err)
+	}
+	if errStr := target.UnlockKeys("passphrase"); errStr != "" {
+		t.Fatalf("unlock: %s", errStr)
+	}
+}
+
+func decodeTasks(t *testing.T, payload string) []TaskDTO {
+	t.Helper()
+	var tasks []TaskDTO
+	if err := json.Unmarshal([]byte(payload), &tasks); err != nil {
+		t.Fatalf("decode tasks %s: %v", payload, err)
+	}
+	return tasks
+}
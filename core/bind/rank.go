@@ -0,0 +1,172 @@
+package bind
+
+import (
+	"fmt"
+	"time"
+
+	"taskpp/core/logic"
+	"taskpp/core/model"
+)
+
+// MoveTaskBetween places a task directly below beforeID and above afterID
+// among its siblings (see siblings). Either may be empty for the top or
+// bottom of the list. Only the moved task gets a new rank key, in a single
+// "reorder" event; neighbours sharing a key are spread apart first, and the
+// list is rebalanced only when keys have grown too long. Returns empty string
+// on success.
+func (c *Core) MoveTaskBetween(taskID string, beforeID string, afterID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	defer c.undoGroup()()
+	if taskID == "" {
+		return errorJSON("missing id")
+	}
+	if taskID == beforeID || taskID == afterID {
+		return errorJSON("task cannot be its own neighbour")
+	}
+	task, err := c.store.GetTask(taskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load task: %v", err))
+	}
+	if task.ID == "" {
+		return errorJSON("task not found")
+	}
+	list, err := c.siblings(task)
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	beforeIndex, afterIndex := -1, len(list)
+	for i, other := range list {
+		switch other.ID {
+		case beforeID:
+			beforeIndex = i
+		case afterID:
+			afterIndex = i
+		}
+	}
+	if beforeID != "" && beforeIndex < 0 {
+		return errorJSON(fmt.Sprintf("task not found among siblings: %s", beforeID))
+	}
+	if afterID != "" && afterIndex == len(list) {
+		return errorJSON(fmt.Sprintf("task not found among siblings: %s", afterID))
+	}
+	if afterIndex <= beforeIndex {
+		return errorJSON("before must sort above after")
+	}
+
+	now := time.Now().UTC()
+	rank, err := c.rankSlot(list, beforeIndex+1, now)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("rebalance: %v", err))
+	}
+	task.Rank = rank
+	task.UpdatedAt = now
+	if err := c.store.UpsertTask(task); err != nil {
+		return errorJSON(fmt.Sprintf("reorder task: %v", err))
+	}
+	if err := c.appendEvent("reorder", task); err != nil {
+		return errorJSON(fmt.Sprintf("event reorder: %v", err))
+	}
+	return ""
+}
+
+// siblings returns the list task is ordered in, sorted and without task
+// itself: tasks with the same project and parent that are archived and done
+// exactly when task is.
+func (c *Core) siblings(task model.Task) ([]model.Task, error) {
+	tasks, err := c.store.ListTasks(model.TaskFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+	list := make([]model.Task, 0)
+	for _, other := range tasks {
+		if other.ID != task.ID && other.ProjectID == task.ProjectID && other.ParentID == task.ParentID &&
+			other.Archived == task.Archived && logic.IsDone(other) == logic.IsDone(task) {
+			list = append(list, other)
+		}
+	}
+	logic.SortSiblings(list)
+	return list, nil
+}
+
+// appendRank returns a key below every sibling of a new task. The siblings
+// are only rebalanced once appended keys have grown too long.
+func (c *Core) appendRank(task model.Task, now time.Time) (string, error) {
+	list, err := c.siblings(task)
+	if err != nil {
+		return "", err
+	}
+	return c.rankSlot(list, len(list), now)
+}
+
+// rankSlot returns a key for position in a sorted list that does not hold
+// the task being placed. When the neighbours share a key (tasks from older
+// clients, or two devices inserting at once) only that run of tasks gets new
+// keys; the whole list is rebalanced when keys have grown too long.
+func (c *Core) rankSlot(list []model.Task, position int, now time.Time) (string, error) {
+	if rank, ok := logic.RankAt(list, position); ok {
+		return rank, nil
+	}
+	if position > 0 && position < len(list) {
+		key := logic.TaskRank(list[position])
+		lo, hi := position, position
+		for lo > 0 && logic.TaskRank(list[lo-1]) == key {
+			lo--
+		}
+		for hi < len(list) && logic.TaskRank(list[hi]) == key {
+			hi++
+		}
+		var before, after string
+		if lo > 0 {
+			before = logic.TaskRank(list[lo-1])
+		}
+		if hi < len(list) {
+			after = logic.TaskRank(list[hi])
+		}
+		ranks, ok := logic.RanksBetween(before, after, hi-lo+1)
+		if ok && !logic.NeedsRebalance(ranks[len(ranks)-1]) {
+			return c.assignRanks(list[lo:hi], ranks, position-lo, now)
+		}
+	}
+	return c.rebalanceRanks(list, position, now)
+}
+
+// rebalanceRanks gives a sorted list evenly spaced rank keys, leaving a free
+// slot at position, and returns the key for that slot. Tasks whose key
+// changes are saved with a "reorder" event.
+func (c *Core) rebalanceRanks(list []model.Task, position int, now time.Time) (string, error) {
+	if position < 0 {
+		position = 0
+	}
+	if position > len(list) {
+		position = len(list)
+	}
+	return c.assignRanks(list, logic.RankSequence(len(list)+1), position, now)
+}
+
+// assignRanks gives list the keys of ranks, skipping the slot at position,
+// and returns the key for that slot. Tasks whose key changes are saved with
+// a "reorder" event.
+func (c *Core) assignRanks(list []model.Task, ranks []string, position int, now time.Time) (string, error) {
+	for i, task := range list {
+		slot := i
+		if i >= position {
+			slot = i + 1
+		}
+		if task.Rank == ranks[slot] {
+			continue
+		}
+		task.Rank = ranks[slot]
+		task.UpdatedAt = now
+		if err := c.store.UpsertTask(task); err != nil {
+			return "", fmt.Errorf("upsert task: %w", err)
+		}
+		if err := c.appendEvent("reorder", task); err != nil {
+			return "", fmt.Errorf("event reorder: %w", err)
+		}
+	}
+	return ranks[position], nil
+}
@@ -0,0 +1,134 @@
+package bind
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"taskpp/core/model"
+)
+
+func listIDs(t *testing.T, core *Core) []string {
+	t.Helper()
+	var ids []string
+	for _, task := range decodeTasks(t, core.ListTasks("")) {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func TestMoveTaskBetweenTouchesOnlyMovedTask(t *testing.T) {
+	core := newTestCore(t)
+	a := createTask(t, core, TaskDTO{Title: "A"})
+	b := createTask(t, core, TaskDTO{Title: "B"})
+	c := createTask(t, core, TaskDTO{Title: "C"})
+	events, _ := core.store.ListEventsSince(0)
+	seq := events[len(events)-1].Seq
+
+	if errStr := core.MoveTaskBetween(c.ID, "", a.ID); errStr != "" {
+		t.Fatalf("move to top: %s", errStr)
+	}
+	if got := listIDs(t, core); got[0] != c.ID || got[1] != a.ID || got[2] != b.ID {
+		t.Fatalf("expected C, A, B, got %v", got)
+	}
+	if events, _ := core.store.ListEventsSince(seq); len(events) != 1 || events[0].Type != "reorder" {
+		t.Fatalf("expected a single reorder event, got %+v", events)
+	}
+
+	if errStr := core.MoveTaskBetween(a.ID, b.ID, ""); errStr != "" {
+		t.Fatalf("move to bottom: %s", errStr)
+	}
+	if got := listIDs(t, core); got[0] != c.ID || got[1] != b.ID || got[2] != a.ID {
+		t.Fatalf("expected C, B, A, got %v", got)
+	}
+	if !hasError(core.MoveTaskBetween(a.ID, a.ID, "")) || !hasError(core.MoveTaskBetween(a.ID, b.ID, c.ID)) {
+		t.Fatalf("expected invalid neighbours to be rejected")
+	}
+}
+
+func TestMoveTaskBetweenRebalancesDuplicateRanks(t *testing.T) {
+	core := newTestCore(t)
+	// Two devices inserting at the same spot concurrently can produce equal keys.
+	a := createTask(t, core, TaskDTO{Title: "A", Rank: "i"})
+	b := createTask(t, core, TaskDTO{Title: "B", Rank: "i"})
+	c := createTask(t, core, TaskDTO{Title: "C", Rank: "r"})
+
+	if errStr := core.MoveTaskBetween(c.ID, a.ID, b.ID); errStr != "" {
+		t.Fatalf("move between duplicates: %s", errStr)
+	}
+	tasks := decodeTasks(t, core.ListTasks(""))
+	if tasks[0].ID != a.ID || tasks[1].ID != c.ID || tasks[2].ID != b.ID {
+		t.Fatalf("expected A, C, B, got %+v", tasks)
+	}
+	if tasks[0].Rank >= tasks[1].Rank || tasks[1].Rank >= tasks[2].Rank {
+		t.Fatalf("expected distinct ascending ranks, got %q %q %q", tasks[0].Rank, tasks[1].Rank, tasks[2].Rank)
+	}
+	if out := core.CreateTask(`{"title":"Bad","rank":"a0"}`); !hasError(out) {
+		t.Fatalf("expected invalid rank to be rejected, got %s", out)
+	}
+}
+
+func TestMoveTaskBetweenSpreadsOnlyCollidingLegacyTasks(t *testing.T) {
+	core := newTestCore(t)
+	ranked := createTask(t, core, TaskDTO{Title: "Ranked"})
+	// Tasks from older clients carry no rank and share the key of Order 0.
+	base := time.Now().UTC()
+	var legacy []string
+	for i := 0; i < 4; i++ {
+		task := model.Task{ID: string(rune('a'+i)) + "-legacy", Title: "Legacy", Status: "active", CreatedAt: base.Add(time.Duration(i) * time.Second)}
+		if err := core.store.UpsertTask(task); err != nil {
+			t.Fatalf("seed legacy task: %v", err)
+		}
+		legacy = append(legacy, task.ID)
+	}
+	var project ProjectDTO
+	if err := json.Unmarshal([]byte(core.CreateProject(`{"name":"Work"}`)), &project); err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	other := createTask(t, core, TaskDTO{Title: "Other project", ProjectID: project.ID})
+	events, _ := core.store.ListEventsSince(0)
+	seq := events[len(events)-1].Seq
+
+	if errStr := core.MoveTaskBetween(ranked.ID, legacy[0], legacy[1]); errStr != "" {
+		t.Fatalf("move between legacy tasks: %s", errStr)
+	}
+	want := []string{legacy[0], ranked.ID, legacy[1], legacy[2], legacy[3]}
+	var got []string
+	for _, id := range listIDs(t, core) {
+		if id != other.ID {
+			got = append(got, id)
+		}
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	// The four legacy tasks get keys once; the other project is untouched.
+	if events, _ := core.store.ListEventsSince(seq); len(events) != 5 {
+		t.Fatalf("expected 4 seeded keys and the move, got %d events", len(events))
+	}
+	if !hasError(core.MoveTaskBetween(ranked.ID, other.ID, "")) {
+		t.Fatalf("expected a neighbour from another list to be rejected")
+	}
+}
+
+func TestCreateTaskAppendsRank(t *testing.T) {
+	core := newTestCore(t)
+	var tasks []TaskDTO
+	for i := 0; i < 6; i++ {
+		tasks = append(tasks, createTask(t, core, TaskDTO{Title: "Fresh"}))
+	}
+	for i := 1; i < len(tasks); i++ {
+		if tasks[i].Rank == "" || tasks[i].Rank <= tasks[i-1].Rank {
+			t.Fatalf("expected ascending ranks, got %q after %q", tasks[i].Rank, tasks[i-1].Rank)
+		}
+	}
+	events, _ := core.store.ListEventsSince(0)
+	seq := events[len(events)-1].Seq
+	if errStr := core.MoveTaskBetween(tasks[5].ID, tasks[0].ID, tasks[1].ID); errStr != "" {
+		t.Fatalf("move: %s", errStr)
+	}
+	if events, _ := core.store.ListEventsSince(seq); len(events) != 1 {
+		t.Fatalf("expected a single reorder event, got %d", len(events))
+	}
+}
@@ -0,0 +1,80 @@
+package bind
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"taskpp/core/logic"
+	"taskpp/core/model"
+)
+
+// seriesNamespace scopes deterministic instance ids for recurring tasks.
+var seriesNamespace = uuid.MustParse("6f7c0c8e-3a4b-4f38-9a57-1d3f0f4f2b11")
+
+// spawnNextInstance creates the follow-up instance of a completed recurring
+// task. The instance id is derived from the series and due date, so two
+// devices completing the same instance offline converge on one task.
+func (c *Core) spawnNextInstance(task model.Task) error {
+	if !logic.IsRecurring(task.Recurrence) {
+		return nil
+	}
+	// Moving an instance does not move the series: the next rule day is
+	// counted from the day the rule gave this instance.
+	next, ok, err := logic.NextDue(task.Recurrence, logic.OccurrenceDate(task), task.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("next due: %w", err)
+	}
+	if !ok {
+		return nil
+	}
+	instance := task
+	instance.ID = instanceID(task.SeriesID, next)
+	existing, err := c.store.GetTask(instance.ID)
+	if err != nil {
+		return fmt.Errorf("load instance: %w", err)
+	}
+	if existing.ID != "" {
+		return nil
+	}
+	wf, err := c.workflow()
+	if err != nil {
+		return err
+	}
+	instance.Status = "active"
+	instance.State = logic.StateOf(instance, wf)
+	instance.DueDate = next
+	instance.OccurrenceDate = time.Time{}
+	if exception, ok := logic.ExceptionFor(task, next); ok {
+		instance.DueDate = exception.MoveTo
+		instance.DueTime = exception.DueTime
+		if exception.DueTime == "" {
+			instance.TimeZone = ""
+		}
+		instance.OccurrenceDate = next
+	}
+	instance.Exceptions = logic.PruneExceptions(task.Exceptions, next)
+	shiftDays := 0
+	if !task.DueDate.IsZero() {
+		shiftDays = int(instance.DueDate.Sub(task.DueDate).Hours() / 24)
+	}
+	instance.Reminders = resetReminders(task.Reminders, shiftDays)
+	instance.Checklist = resetChecklist(task.Checklist, task.UpdatedAt)
+	instance.ChecklistRemoved = nil
+	instance.ChecklistRemovedAt = nil
+	instance.CompletedAt = time.Time{}
+	instance.CreatedAt = task.UpdatedAt
+	instance.UpdatedAt = task.UpdatedAt
+	if err := c.store.UpsertTask(instance); err != nil {
+		return fmt.Errorf("create instance: %w", err)
+	}
+	if err := c.appendEvent("recur", instance); err != nil {
+		return fmt.Errorf("event recur: %w", err)
+	}
+	return nil
+}
+
+func instanceID(seriesID string, due time.Time) string {
+	return uuid.NewSHA1(seriesNamespace, []byte(seriesID+"/"+formatDate(due))).String()
+}
@@ -0,0 +1,65 @@
+package bind
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCompletingRecurringTaskSpawnsNextInstance(t *testing.T) {
+	core := newTestCore(t)
+	today := time.Now().UTC().Format("2006-01-02")
+	task := createTask(t, core, TaskDTO{Title: "Water plants", DueDate: today, Recurrence: "daily", Tags: []string{"home"}})
+
+	if errStr := core.SetCompleted(task.ID, true); errStr != "" {
+		t.Fatalf("complete: %s", errStr)
+	}
+	tasks := decodeTasks(t, core.ListTasks(`{"status":"active"}`))
+	if len(tasks) != 1 {
+		t.Fatalf("expected one new active instance, got %+v", tasks)
+	}
+	next := tasks[0]
+	wantDue := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	if next.DueDate != wantDue || next.SeriesID != task.ID || next.Recurrence != "daily" {
+		t.Fatalf("unexpected next instance: %+v", next)
+	}
+	if len(next.Tags) != 1 || next.CompletedAt != "" {
+		t.Fatalf("expected instance to copy fields and reset completion: %+v", next)
+	}
+
+	// Re-completing the same instance after reopening must not duplicate the next one.
+	if errStr := core.SetCompleted(task.ID, false); errStr != "" {
+		t.Fatalf("reopen: %s", errStr)
+	}
+	if errStr := core.SetCompleted(task.ID, true); errStr != "" {
+		t.Fatalf("complete again: %s", errStr)
+	}
+	if tasks := decodeTasks(t, core.ListTasks("")); len(tasks) != 2 {
+		t.Fatalf("expected instance id to be deterministic, got %d tasks", len(tasks))
+	}
+}
+
+func TestUpdateToDoneSpawnsNextInstance(t *testing.T) {
+	core := newTestCore(t)
+	today := time.Now().UTC().Format("2006-01-02")
+	task := createTask(t, core, TaskDTO{Title: "Water plants", DueDate: today, Recurrence: "daily"})
+
+	task.Status = "done"
+	task.CompletedAt = time.Now().UTC().Format(time.RFC3339Nano)
+	payload, _ := json.Marshal(task)
+	if out := core.UpdateTask(string(payload)); hasError(out) {
+		t.Fatalf("update: %s", out)
+	}
+	tasks := decodeTasks(t, core.ListTasks(`{"status":"active"}`))
+	wantDue := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	if len(tasks) != 1 || tasks[0].DueDate != wantDue || tasks[0].SeriesID != task.ID {
+		t.Fatalf("expected the next instance after completing by update, got %+v", tasks)
+	}
+}
+
+func TestInvalidRecurrenceRejected(t *testing.T) {
+	core := newTestCore(t)
+	if out := core.CreateTask(`{"title":"Bad","recurrence":"hourly"}`); !hasError(out) {
+		t.Fatalf("expected validation error, got %s", out)
+	}
+}
@@ -0,0 +1,289 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"taskpp/core/logic"
+	"taskpp/core/model"
+)
+
+// ReminderDTO is a bind-safe reminder representation. Set either at
+// (RFC3339) or offset_minutes before the task's due time.
+type ReminderDTO struct {
+	ID             string `json:"id"`
+	At             string `json:"at"`
+	OffsetMinutes  int64  `json:"offset_minutes"`
+	AcknowledgedAt string `json:"acknowledged_at"`
+	SnoozedUntil   string `json:"snoozed_until"`
+}
+
+// FiringDTO is a reminder that is due to go off.
+type FiringDTO struct {
+	TaskID     string `json:"task_id"`
+	ReminderID string `json:"reminder_id"`
+	Title      string `json:"title"`
+	FireAt     string `json:"fire_at"`
+}
+
+// AddReminder attaches ReminderDTO JSON to a task and returns the stored
+// ReminderDTO JSON.
+func (c *Core) AddReminder(taskID string, reminderJSON string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var dto ReminderDTO
+	if err := json.Unmarshal([]byte(reminderJSON), &dto); err != nil {
+		return errorJSON(fmt.Sprintf("decode reminder: %v", err))
+	}
+	dto.ID = uuid.NewString()
+	reminders, err := dtoToReminders([]ReminderDTO{dto})
+	if err != nil {
+		return errorJSON(fmt.Sprintf("convert reminder: %v", err))
+	}
+	reminder := reminders[0]
+	if errStr := c.editReminders(taskID, "reminder_add", func(task *model.Task) error {
+		if err := logic.ValidateReminder(*task, reminder); err != nil {
+			return err
+		}
+		task.Reminders = append(task.Reminders, reminder)
+		return nil
+	}); errStr != "" {
+		return errStr
+	}
+	out, err := json.Marshal(remindersToDTO([]model.Reminder{reminder})[0])
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode reminder: %v", err))
+	}
+	return string(out)
+}
+
+// RemoveReminder drops a reminder from a task. Returns empty string on success.
+func (c *Core) RemoveReminder(taskID string, reminderID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.editReminder(taskID, reminderID, "reminder_remove", func(task *model.Task, index int) {
+		task.Reminders = append(task.Reminders[:index], task.Reminders[index+1:]...)
+	})
+}
+
+// AcknowledgeReminder marks a fired reminder as handled so it stops firing.
+// Returns empty string on success.
+func (c *Core) AcknowledgeReminder(taskID string, reminderID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now().UTC()
+	return c.editReminder(taskID, reminderID, "reminder_ack", func(task *model.Task, index int) {
+		task.Reminders[index].AcknowledgedAt = now
+	})
+}
+
+// SnoozeReminder re-arms a reminder to fire again after the given number of
+// minutes. Returns empty string on success.
+func (c *Core) SnoozeReminder(taskID string, reminderID string, minutes int64) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if minutes <= 0 {
+		return errorJSON("snooze minutes must be positive")
+	}
+	until := time.Now().UTC().Add(time.Duration(minutes) * time.Minute)
+	return c.editReminder(taskID, reminderID, "reminder_snooze", func(task *model.Task, index int) {
+		task.Reminders[index].AcknowledgedAt = time.Time{}
+		task.Reminders[index].SnoozedUntil = until
+	})
+}
+
+// PendingReminders returns JSON-encoded FiringDTO for every unacknowledged
+// reminder due at or before now (RFC3339; empty means the current time).
+func (c *Core) PendingReminders(now string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	at, tasks, errStr := c.reminderTasks(now)
+	if errStr != "" {
+		return errStr
+	}
+	return encodeFirings(logic.PendingReminders(tasks, at, c.location))
+}
+
+// NextReminder returns FiringDTO JSON for the first reminder due after now
+// (RFC3339; empty means the current time), or an empty object when none is
+// scheduled. Platforms use it to arm a single local alarm.
+func (c *Core) NextReminder(now string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	at, tasks, errStr := c.reminderTasks(now)
+	if errStr != "" {
+		return errStr
+	}
+	var out FiringDTO
+	if firing, ok := logic.NextReminder(tasks, at, c.location); ok {
+		out = firingToDTO(firing)
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode reminder: %v", err))
+	}
+	return string(data)
+}
+
+func (c *Core) reminderTasks(now string) (time.Time, []model.Task, string) {
+	if c.store == nil {
+		return time.Time{}, nil, errorJSON("storage not initialized")
+	}
+	at := time.Now().UTC()
+	if now != "" {
+		parsed, err := parseTime(now)
+		if err != nil {
+			return time.Time{}, nil, errorJSON(fmt.Sprintf("parse now: %v", err))
+		}
+		at = parsed
+	}
+	tasks, err := c.store.ListTasks(model.TaskFilter{})
+	if err != nil {
+		return time.Time{}, nil, errorJSON(fmt.Sprintf("list tasks: %v", err))
+	}
+	return at, tasks, ""
+}
+
+func (c *Core) editReminder(taskID string, reminderID string, eventType string, mutate func(task *model.Task, index int)) string {
+	if reminderID == "" {
+		return errorJSON("missing reminder id")
+	}
+	return c.editReminders(taskID, eventType, func(task *model.Task) error {
+		for i, reminder := range task.Reminders {
+			if reminder.ID == reminderID {
+				mutate(task, i)
+				return nil
+			}
+		}
+		return fmt.Errorf("reminder not found")
+	})
+}
+
+func (c *Core) editReminders(taskID string, eventType string, mutate func(task *model.Task) error) string {
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if taskID == "" {
+		return errorJSON("missing id")
+	}
+	task, err := c.store.GetTask(taskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load task: %v", err))
+	}
+	if task.ID == "" {
+		return errorJSON("task not found")
+	}
+	if err := mutate(&task); err != nil {
+		return errorJSON(fmt.Sprintf("%s: %v", eventType, err))
+	}
+	task.UpdatedAt = time.Now().UTC()
+	if err := c.store.UpsertTask(task); err != nil {
+		return errorJSON(fmt.Sprintf("update task: %v", err))
+	}
+	if err := c.appendEvent(eventType, task); err != nil {
+		return errorJSON(fmt.Sprintf("event %s: %v", eventType, err))
+	}
+	return ""
+}
+
+// validateReminders checks reminders supplied on a whole-task write.
+func validateReminders(task model.Task) error {
+	for _, reminder := range task.Reminders {
+		if err := logic.ValidateReminder(task, reminder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resetReminders re-arms reminders for the next instance of a recurring task.
+// Absolute reminders move by the same number of days as the due date.
+func resetReminders(reminders []model.Reminder, shiftDays int) []model.Reminder {
+	if len(reminders) == 0 {
+		return nil
+	}
+	out := make([]model.Reminder, len(reminders))
+	for i, reminder := range reminders {
+		reminder.AcknowledgedAt = time.Time{}
+		reminder.SnoozedUntil = time.Time{}
+		if !reminder.At.IsZero() {
+			reminder.At = reminder.At.AddDate(0, 0, shiftDays)
+		}
+		out[i] = reminder
+	}
+	return out
+}
+
+func encodeFirings(firings []logic.Firing) string {
+	out := make([]FiringDTO, 0, len(firings))
+	for _, firing := range firings {
+		out = append(out, firingToDTO(firing))
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode reminders: %v", err))
+	}
+	return string(data)
+}
+
+func firingToDTO(firing logic.Firing) FiringDTO {
+	return FiringDTO{
+		TaskID:     firing.TaskID,
+		ReminderID: firing.ReminderID,
+		Title:      firing.Title,
+		FireAt:     formatTime(firing.FireAt),
+	}
+}
+
+func remindersToDTO(reminders []model.Reminder) []ReminderDTO {
+	if len(reminders) == 0 {
+		return nil
+	}
+	out := make([]ReminderDTO, 0, len(reminders))
+	for _, reminder := range reminders {
+		out = append(out, ReminderDTO{
+			ID:             reminder.ID,
+			At:             formatTime(reminder.At),
+			OffsetMinutes:  reminder.OffsetMinutes,
+			AcknowledgedAt: formatTime(reminder.AcknowledgedAt),
+			SnoozedUntil:   formatTime(reminder.SnoozedUntil),
+		})
+	}
+	return out
+}
+
+func dtoToReminders(dtos []ReminderDTO) ([]model.Reminder, error) {
+	if len(dtos) == 0 {
+		return nil, nil
+	}
+	out := make([]model.Reminder, 0, len(dtos))
+	for _, dto := range dtos {
+		at, err := parseTime(dto.At)
+		if err != nil {
+			return nil, fmt.Errorf("reminder at: %w", err)
+		}
+		acknowledgedAt, err := parseTime(dto.AcknowledgedAt)
+		if err != nil {
+			return nil, fmt.Errorf("reminder acknowledged_at: %w", err)
+		}
+		snoozedUntil, err := parseTime(dto.SnoozedUntil)
+		if err != nil {
+			return nil, fmt.Errorf("reminder snoozed_until: %w", err)
+		}
+		id := dto.ID
+		if id == "" {
+			id = uuid.NewString()
+		}
+		out = append(out, model.Reminder{
+			ID:             id,
+			At:             at,
+			OffsetMinutes:  dto.OffsetMinutes,
+			AcknowledgedAt: acknowledgedAt,
+			SnoozedUntil:   snoozedUntil,
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,75 @@
+package bind
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestReminderAckSnoozeAndRecurrence(t *testing.T) {
+	core := newTestCore(t)
+
+	task := createTask(t, core, TaskDTO{
+		Title:      "Stand-up",
+		DueDate:    "2026-03-02",
+		DueTime:    "10:00",
+		Recurrence: "daily",
+		Reminders:  []ReminderDTO{{OffsetMinutes: 30}},
+	})
+	if len(task.Reminders) != 1 || task.Reminders[0].ID == "" {
+		t.Fatalf("expected reminder with generated id, got %+v", task.Reminders)
+	}
+	reminderID := task.Reminders[0].ID
+
+	var pending []FiringDTO
+	if err := json.Unmarshal([]byte(core.PendingReminders("2026-03-02T09:45:00Z")), &pending); err != nil {
+		t.Fatalf("decode pending: %v", err)
+	}
+	if len(pending) != 1 || pending[0].FireAt != "2026-03-02T09:30:00Z" {
+		t.Fatalf("expected reminder at 09:30, got %+v", pending)
+	}
+
+	if errStr := core.SnoozeReminder(task.ID, reminderID, 10); errStr != "" {
+		t.Fatalf("snooze: %s", errStr)
+	}
+	var next FiringDTO
+	if err := json.Unmarshal([]byte(core.NextReminder("")), &next); err != nil {
+		t.Fatalf("decode next: %v", err)
+	}
+	fireAt, _ := time.Parse(time.RFC3339Nano, next.FireAt)
+	if next.ReminderID != reminderID || time.Until(fireAt) <= 0 {
+		t.Fatalf("expected snoozed reminder to be upcoming, got %+v", next)
+	}
+
+	if errStr := core.AcknowledgeReminder(task.ID, reminderID); errStr != "" {
+		t.Fatalf("ack: %s", errStr)
+	}
+	if got := core.PendingReminders(""); got != "[]" {
+		t.Fatalf("expected no pending reminders after ack, got %s", got)
+	}
+
+	if errStr := core.SetCompleted(task.ID, true); errStr != "" {
+		t.Fatalf("complete: %s", errStr)
+	}
+	for _, candidate := range decodeTasks(t, core.ListTasks("")) {
+		if candidate.ID == task.ID {
+			continue
+		}
+		if len(candidate.Reminders) != 1 || candidate.Reminders[0].AcknowledgedAt != "" {
+			t.Fatalf("expected next instance to re-arm reminder, got %+v", candidate.Reminders)
+		}
+		return
+	}
+	t.Fatalf("expected next instance")
+}
+
+func TestRelativeReminderRequiresDueDate(t *testing.T) {
+	core := newTestCore(t)
+	task := createTask(t, core, TaskDTO{Title: "Floating"})
+	if !hasError(core.AddReminder(task.ID, `{"offset_minutes":15}`)) {
+		t.Fatalf("expected error for relative reminder without due date")
+	}
+	if hasError(core.AddReminder(task.ID, `{"at":"2026-03-02T09:00:00Z"}`)) {
+		t.Fatalf("expected absolute reminder to be accepted")
+	}
+}
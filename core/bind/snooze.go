@@ -0,0 +1,40 @@
+package bind
+
+import (
+	"fmt"
+	"time"
+)
+
+// SnoozeTask defers a task until a date ("YYYY-MM-DD", "tomorrow" or
+// "next_week"), hiding it from default listings until then. An empty until
+// clears the start date. Returns empty string on success.
+func (c *Core) SnoozeTask(taskID string, until string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if taskID == "" {
+		return errorJSON("missing id")
+	}
+	startDate, err := parseDate(c.resolveDate(until))
+	if err != nil {
+		return errorJSON(fmt.Sprintf("parse until: %v", err))
+	}
+	task, err := c.store.GetTask(taskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load task: %v", err))
+	}
+	if task.ID == "" {
+		return errorJSON("task not found")
+	}
+	task.StartDate = startDate
+	task.UpdatedAt = time.Now().UTC()
+	if err := c.store.UpsertTask(task); err != nil {
+		return errorJSON(fmt.Sprintf("snooze task: %v", err))
+	}
+	if err := c.appendEvent("snooze", task); err != nil {
+		return errorJSON(fmt.Sprintf("event snooze: %v", err))
+	}
+	return ""
+}
@@ -0,0 +1,38 @@
+package bind
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSnoozeHidesTaskUntilStartDate(t *testing.T) {
+	core := newTestCore(t)
+
+	task := createTask(t, core, TaskDTO{Title: "Later"})
+	createTask(t, core, TaskDTO{Title: "Now"})
+	createTask(t, core, TaskDTO{Title: "Started", StartDate: "today"})
+
+	if errStr := core.SnoozeTask(task.ID, "next_week"); errStr != "" {
+		t.Fatalf("snooze: %s", errStr)
+	}
+	if tasks := decodeTasks(t, core.ListTasks("")); len(tasks) != 2 {
+		t.Fatalf("expected snoozed task hidden, got %+v", tasks)
+	}
+	filter, _ := json.Marshal(TaskFilterDTO{IncludeDeferred: true})
+	tasks := decodeTasks(t, core.ListTasks(string(filter)))
+	if len(tasks) != 3 {
+		t.Fatalf("expected deferred task with include_deferred, got %+v", tasks)
+	}
+	for _, listed := range tasks {
+		if listed.ID == task.ID && listed.StartDate == "" {
+			t.Fatalf("expected start_date to be set")
+		}
+	}
+
+	if errStr := core.SnoozeTask(task.ID, ""); errStr != "" {
+		t.Fatalf("unsnooze: %s", errStr)
+	}
+	if tasks := decodeTasks(t, core.ListTasks("")); len(tasks) != 3 {
+		t.Fatalf("expected cleared start date to show task, got %+v", tasks)
+	}
+}
@@ -0,0 +1,188 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"taskpp/core/logic"
+	"taskpp/core/model"
+)
+
+// TaskNodeDTO is a bind-safe task tree node.
+type TaskNodeDTO struct {
+	Task     TaskDTO       `json:"task"`
+	Children []TaskNodeDTO `json:"children"`
+}
+
+// ListTaskTree returns a JSON-encoded forest of TaskNodeDTO. Tasks whose
+// parent is filtered out are returned as roots. Siblings are ordered by rank.
+func (c *Core) ListTaskTree(filterJSON string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	filter, err := c.decodeTaskFilter(filterJSON)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("decode filter: %v", err))
+	}
+	tasks, err := c.store.ListTasks(filter)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list tasks: %v", err))
+	}
+	data, err := json.Marshal(buildTaskTree(tasks, c.blockerLookup()))
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode tree: %v", err))
+	}
+	return string(data)
+}
+
+func buildTaskTree(tasks []model.Task, lookup func(id string) (model.Task, bool)) []TaskNodeDTO {
+	present := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		present[task.ID] = true
+	}
+	children := make(map[string][]model.Task)
+	roots := make([]model.Task, 0)
+	for _, task := range tasks {
+		if task.ParentID != "" && present[task.ParentID] {
+			children[task.ParentID] = append(children[task.ParentID], task)
+			continue
+		}
+		roots = append(roots, task)
+	}
+	placed := make(map[string]bool, len(tasks))
+	var build func(level []model.Task) []TaskNodeDTO
+	build = func(level []model.Task) []TaskNodeDTO {
+		logic.SortSiblings(level)
+		out := make([]TaskNodeDTO, 0, len(level))
+		for _, task := range level {
+			if placed[task.ID] {
+				continue
+			}
+			placed[task.ID] = true
+			out = append(out, TaskNodeDTO{Task: blockedTaskDTO(task, lookup), Children: build(children[task.ID])})
+		}
+		return out
+	}
+	forest := build(roots)
+	// Tasks caught in a cycle (possible after concurrent edits on two devices)
+	// never hang off a root; surface them at the top level instead of dropping them.
+	for _, task := range tasks {
+		if !placed[task.ID] {
+			forest = append(forest, build([]model.Task{task})...)
+		}
+	}
+	return forest
+}
+
+// checkParentRef validates a new or changed parent against the stored
+// hierarchy. An unchanged parent is not checked again, so a subtask whose
+// parent was deleted on another device can still be edited or re-parented.
+func (c *Core) checkParentRef(task, previous model.Task) error {
+	if task.ParentID == "" || (previous.ID != "" && task.ParentID == previous.ParentID) {
+		return nil
+	}
+	tasks, err := c.store.ListTasks(model.TaskFilter{})
+	if err != nil {
+		return fmt.Errorf("list tasks: %w", err)
+	}
+	parents := make(map[string]string, len(tasks)+1)
+	for _, existing := range tasks {
+		parents[existing.ID] = existing.ParentID
+	}
+	parents[task.ID] = ""
+	return logic.ValidateParent(task.ID, task.ParentID, parents)
+}
+
+// cascadeParentChange propagates archiving of a parent to its subtasks after
+// an update. Completion goes through afterCompleted.
+func (c *Core) cascadeParentChange(previous, task model.Task) error {
+	if !task.Archived || previous.Archived {
+		return nil
+	}
+	return c.cascadeDescendants(task.ID, "update", func(child *model.Task) bool {
+		if child.Archived {
+			return false
+		}
+		child.Archived = true
+		return true
+	})
+}
+
+func (c *Core) completeDescendants(taskID string) error {
+	wf, err := c.workflow()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	return c.cascadeDescendants(taskID, "set_completed", func(child *model.Task) bool {
+		if logic.IsDone(*child) {
+			return false
+		}
+		child.Status = "done"
+		child.CompletedAt = now
+		child.State = logic.StateOf(*child, wf)
+		return true
+	})
+}
+
+// deleteDescendants removes every subtask of taskID, deepest first.
+func (c *Core) deleteDescendants(taskID string) error {
+	tasks, err := c.store.ListTasks(model.TaskFilter{})
+	if err != nil {
+		return fmt.Errorf("list tasks: %w", err)
+	}
+	byID := indexTasks(tasks)
+	ids := logic.Descendants(tasks, taskID)
+	for i := len(ids) - 1; i >= 0; i-- {
+		child := byID[ids[i]]
+		if err := c.store.DeleteTask(child.ID); err != nil {
+			return fmt.Errorf("delete task: %w", err)
+		}
+		if err := c.store.DeleteTaskComments(child.ID); err != nil {
+			return fmt.Errorf("delete comments: %w", err)
+		}
+		if err := c.store.DeleteTaskTimeEntries(child.ID); err != nil {
+			return fmt.Errorf("delete time entries: %w", err)
+		}
+		if err := c.appendEvent("delete", child); err != nil {
+			return fmt.Errorf("event delete: %w", err)
+		}
+	}
+	return nil
+}
+
+// cascadeDescendants applies mutate to every subtask of taskID and logs an
+// event for each one it reports as changed.
+func (c *Core) cascadeDescendants(taskID, eventType string, mutate func(*model.Task) bool) error {
+	tasks, err := c.store.ListTasks(model.TaskFilter{})
+	if err != nil {
+		return fmt.Errorf("list tasks: %w", err)
+	}
+	byID := indexTasks(tasks)
+	now := time.Now().UTC()
+	for _, id := range logic.Descendants(tasks, taskID) {
+		child := byID[id]
+		if !mutate(&child) {
+			continue
+		}
+		child.UpdatedAt = now
+		if err := c.store.UpsertTask(child); err != nil {
+			return fmt.Errorf("upsert task: %w", err)
+		}
+		if err := c.appendEvent(eventType, child); err != nil {
+			return fmt.Errorf("event %s: %w", eventType, err)
+		}
+	}
+	return nil
+}
+
+func indexTasks(tasks []model.Task) map[string]model.Task {
+	byID := make(map[string]model.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+	return byID
+}
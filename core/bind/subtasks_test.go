@@ -0,0 +1,133 @@
+package bind
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTaskTreeAndCascades(t *testing.T) {
+	core := newTestCore(t)
+
+	parent := createTask(t, core, TaskDTO{Title: "Trip"})
+	second := createTask(t, core, TaskDTO{Title: "Book hotel", ParentID: parent.ID, Order: 2})
+	first := createTask(t, core, TaskDTO{Title: "Book flight", ParentID: parent.ID, Order: 1})
+	leaf := createTask(t, core, TaskDTO{Title: "Pick seat", ParentID: first.ID})
+
+	var forest []TaskNodeDTO
+	if err := json.Unmarshal([]byte(core.ListTaskTree("")), &forest); err != nil {
+		t.Fatalf("decode tree: %v", err)
+	}
+	if len(forest) != 1 || len(forest[0].Children) != 2 {
+		t.Fatalf("unexpected tree shape: %+v", forest)
+	}
+	if forest[0].Children[0].Task.ID != first.ID || forest[0].Children[1].Task.ID != second.ID {
+		t.Fatalf("expected siblings ordered by order field: %+v", forest[0].Children)
+	}
+	if len(forest[0].Children[0].Children) != 1 || forest[0].Children[0].Children[0].Task.ID != leaf.ID {
+		t.Fatalf("expected grandchild under first child: %+v", forest[0].Children[0])
+	}
+
+	cyclic := parent
+	cyclic.ParentID = leaf.ID
+	payload, _ := json.Marshal(cyclic)
+	if out := core.UpdateTask(string(payload)); !hasError(out) {
+		t.Fatalf("expected cycle to be rejected, got %s", out)
+	}
+
+	if errStr := core.SetCompleted(parent.ID, true); errStr != "" {
+		t.Fatalf("complete parent: %s", errStr)
+	}
+	for _, task := range decodeTasks(t, core.ListTasks("")) {
+		if task.Status != "done" || task.CompletedAt == "" {
+			t.Fatalf("expected cascade completion, got %+v", task)
+		}
+	}
+
+	if errStr := core.DeleteTask(first.ID); errStr != "" {
+		t.Fatalf("delete: %s", errStr)
+	}
+	tasks := decodeTasks(t, core.ListTasks(""))
+	if len(tasks) != 2 {
+		t.Fatalf("expected subtree delete to leave 2 tasks, got %+v", tasks)
+	}
+}
+
+func TestArchiveParentCascades(t *testing.T) {
+	core := newTestCore(t)
+	parent := createTask(t, core, TaskDTO{Title: "Parent"})
+	createTask(t, core, TaskDTO{Title: "Child", ParentID: parent.ID})
+
+	parent.Archived = true
+	payload, _ := json.Marshal(parent)
+	if out := core.UpdateTask(string(payload)); hasError(out) {
+		t.Fatalf("archive parent: %s", out)
+	}
+	for _, task := range decodeTasks(t, core.ListTasks("")) {
+		if !task.Archived {
+			t.Fatalf("expected archived cascade, got %+v", task)
+		}
+	}
+}
+
+func createTask(t *testing.T, core *Core, dto TaskDTO) TaskDTO {
+	t.Helper()
+	payload, _ := json.Marshal(dto)
+	out := core.CreateTask(string(payload))
+	if hasError(out) {
+		t.Fatalf("create task: %s", out)
+	}
+	var created TaskDTO
+	if err := json.Unmarshal([]byte(out), &created); err != nil {
+		t.Fatalf("decode task: %v", err)
+	}
+	return created
+}
+
+func TestDeleteProjectTakesSubtasksAlong(t *testing.T) {
+	core := newTestCore(t)
+	var project ProjectDTO
+	if err := json.Unmarshal([]byte(core.CreateProject(`{"name":"Work"}`)), &project); err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	parent := createTask(t, core, TaskDTO{Title: "Parent", ProjectID: project.ID})
+	child := createTask(t, core, TaskDTO{Title: "Inbox child", ParentID: parent.ID})
+	createTask(t, core, TaskDTO{Title: "Project child", ProjectID: project.ID, ParentID: parent.ID})
+	keep := createTask(t, core, TaskDTO{Title: "Unrelated"})
+
+	if errStr := core.DeleteProject(project.ID, ProjectDeleteTasks); errStr != "" {
+		t.Fatalf("delete project: %s", errStr)
+	}
+	tasks := decodeTasks(t, core.ListTasks(""))
+	if len(tasks) != 1 || tasks[0].ID != keep.ID {
+		t.Fatalf("expected only the unrelated task left, got %+v", tasks)
+	}
+	if _, ok := taskByID(t, core, child.ID); ok {
+		t.Fatalf("expected the subtask outside the project to be deleted")
+	}
+}
+
+func TestOrphanedSubtaskStaysEditable(t *testing.T) {
+	core := newTestCore(t)
+	parent := createTask(t, core, TaskDTO{Title: "Parent"})
+	child := createTask(t, core, TaskDTO{Title: "Child", ParentID: parent.ID})
+	// A delete synced from another device removes only the parent row.
+	if err := core.store.DeleteTask(parent.ID); err != nil {
+		t.Fatalf("delete parent row: %v", err)
+	}
+
+	child.Title = "Still editable"
+	payload, _ := json.Marshal(child)
+	if out := core.UpdateTask(string(payload)); hasError(out) {
+		t.Fatalf("update orphan: %s", out)
+	}
+	child.ParentID = ""
+	payload, _ = json.Marshal(child)
+	if out := core.UpdateTask(string(payload)); hasError(out) {
+		t.Fatalf("re-parent orphan: %s", out)
+	}
+	child.ParentID = parent.ID
+	payload, _ = json.Marshal(child)
+	if !hasError(core.UpdateTask(string(payload))) {
+		t.Fatalf("expected moving under a missing parent to be rejected")
+	}
+}
@@ -0,0 +1,170 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"taskpp/core/logic"
+	"taskpp/core/model"
+)
+
+// TagDTO is a bind-safe tag summary.
+type TagDTO struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// ListTags returns a JSON-encoded list of TagDTO across all tasks.
+func (c *Core) ListTags() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	tasks, err := c.store.ListTasks(model.TaskFilter{})
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list tasks: %v", err))
+	}
+	counts := make(map[string]int64)
+	for _, task := range tasks {
+		for _, tag := range task.Tags {
+			counts[tag]++
+		}
+	}
+	out := make([]TagDTO, 0, len(counts))
+	for name, count := range counts {
+		out = append(out, TagDTO{Name: name, Count: count})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	data, err := json.Marshal(out)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode tags: %v", err))
+	}
+	return string(data)
+}
+
+// AddTag adds a tag to a task. Returns empty string on success.
+func (c *Core) AddTag(taskID string, tag string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.editTaskTags(taskID, tag, "tag_add", func(tags []string, tag string) []string {
+		return append(tags, tag)
+	})
+}
+
+// RemoveTag removes a tag from a task. Returns empty string on success.
+func (c *Core) RemoveTag(taskID string, tag string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.editTaskTags(taskID, tag, "tag_remove", func(tags []string, tag string) []string {
+		return withoutTags(tags, map[string]bool{tag: true})
+	})
+}
+
+// RenameTag renames a tag on every task carrying it. Renaming onto an existing
+// tag merges the two. Returns empty string on success.
+func (c *Core) RenameTag(from string, to string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fromJSON, _ := json.Marshal([]string{from})
+	return c.retag(string(fromJSON), to, "tag_rename")
+}
+
+// MergeTags replaces every tag in tagsJSON (a JSON string array) with into.
+// Returns empty string on success.
+func (c *Core) MergeTags(tagsJSON string, into string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.retag(tagsJSON, into, "tag_merge")
+}
+
+func (c *Core) retag(tagsJSON string, into string, eventType string) string {
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	defer c.undoGroup()()
+	var raw []string
+	if err := json.Unmarshal([]byte(tagsJSON), &raw); err != nil {
+		return errorJSON(fmt.Sprintf("decode tags: %v", err))
+	}
+	sources, err := logic.NormalizeTags(raw)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("validate tags: %v", err))
+	}
+	if len(sources) == 0 {
+		return errorJSON("tags are required")
+	}
+	target, err := logic.NormalizeTag(into)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("validate tag: %v", err))
+	}
+	replace := make(map[string]bool, len(sources))
+	for _, tag := range sources {
+		replace[tag] = true
+	}
+	tasks, err := c.store.ListTasks(model.TaskFilter{TagsAny: sources})
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list tasks: %v", err))
+	}
+	now := time.Now().UTC()
+	for _, task := range tasks {
+		tags, err := logic.NormalizeTags(append(withoutTags(task.Tags, replace), target))
+		if err != nil {
+			return errorJSON(fmt.Sprintf("validate tags: %v", err))
+		}
+		task.Tags = tags
+		task.UpdatedAt = now
+		if err := c.store.UpsertTask(task); err != nil {
+			return errorJSON(fmt.Sprintf("retag task: %v", err))
+		}
+		if err := c.appendEvent(eventType, task); err != nil {
+			return errorJSON(fmt.Sprintf("event %s: %v", eventType, err))
+		}
+	}
+	return ""
+}
+
+func (c *Core) editTaskTags(taskID, tag, eventType string, edit func([]string, string) []string) string {
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if taskID == "" {
+		return errorJSON("missing id")
+	}
+	normalized, err := logic.NormalizeTag(tag)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("validate tag: %v", err))
+	}
+	task, err := c.store.GetTask(taskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load task: %v", err))
+	}
+	if task.ID == "" {
+		return errorJSON("task not found")
+	}
+	tags, err := logic.NormalizeTags(edit(task.Tags, normalized))
+	if err != nil {
+		return errorJSON(fmt.Sprintf("validate tags: %v", err))
+	}
+	task.Tags = tags
+	task.UpdatedAt = time.Now().UTC()
+	if err := c.store.UpsertTask(task); err != nil {
+		return errorJSON(fmt.Sprintf("save task: %v", err))
+	}
+	if err := c.appendEvent(eventType, task); err != nil {
+		return errorJSON(fmt.Sprintf("event %s: %v", eventType, err))
+	}
+	return ""
+}
+
+func withoutTags(tags []string, drop map[string]bool) []string {
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if !drop[tag] {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package bind
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTagsFilterAndRename(t *testing.T) {
+	core := newTestCore(t)
+
+	a := createTask(t, core, TaskDTO{Title: "A", Tags: []string{"#Home", "errands"}})
+	createTask(t, core, TaskDTO{Title: "B", Tags: []string{"work"}})
+	createTask(t, core, TaskDTO{Title: "C"})
+	if len(a.Tags) != 2 || a.Tags[0] != "errands" || a.Tags[1] != "home" {
+		t.Fatalf("expected normalized tags, got %v", a.Tags)
+	}
+
+	filter, _ := json.Marshal(TaskFilterDTO{TagsAny: []string{"home", "work"}})
+	if tasks := decodeTasks(t, core.ListTasks(string(filter))); len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks for any filter, got %+v", tasks)
+	}
+	filter, _ = json.Marshal(TaskFilterDTO{TagsAll: []string{"home", "errands"}})
+	if tasks := decodeTasks(t, core.ListTasks(string(filter))); len(tasks) != 1 {
+		t.Fatalf("expected 1 task for all filter, got %+v", tasks)
+	}
+	filter, _ = json.Marshal(TaskFilterDTO{TagsNone: []string{"home"}})
+	if tasks := decodeTasks(t, core.ListTasks(string(filter))); len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks for none filter, got %+v", tasks)
+	}
+
+	if errStr := core.RenameTag("work", "Home"); errStr != "" {
+		t.Fatalf("rename: %s", errStr)
+	}
+	var tags []TagDTO
+	if err := json.Unmarshal([]byte(core.ListTags()), &tags); err != nil {
+		t.Fatalf("decode tags: %v", err)
+	}
+	if len(tags) != 2 || tags[1].Name != "home" || tags[1].Count != 2 {
+		t.Fatalf("expected rename to merge into home, got %+v", tags)
+	}
+
+	if errStr := core.RemoveTag(a.ID, "home"); errStr != "" {
+		t.Fatalf("remove tag: %s", errStr)
+	}
+	if errStr := core.AddTag(a.ID, "Later"); errStr != "" {
+		t.Fatalf("add tag: %s", errStr)
+	}
+
+	var events []EventDTO
+	if err := json.Unmarshal([]byte(core.ExportEvents(0)), &events); err != nil {
+		t.Fatalf("decode events: %v", err)
+	}
+	renames := 0
+	for _, event := range events {
+		if event.Type == "tag_rename" {
+			renames++
+		}
+	}
+	if renames != 1 {
+		t.Fatalf("expected one tag_rename event, got %d", renames)
+	}
+}
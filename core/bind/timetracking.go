@@ -0,0 +1,384 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"taskpp/core/logic"
+	"taskpp/core/model"
+	"taskpp/core/sync"
+)
+
+// TimeEntryDTO is a bind-safe time entry. End is "" while the timer runs.
+type TimeEntryDTO struct {
+	ID        string `json:"id"`
+	TaskID    string `json:"task_id"`
+	DeviceID  string `json:"device_id"`
+	Start     string `json:"start"`
+	End       string `json:"end"`
+	Note      string `json:"note"`
+	UpdatedAt string `json:"updated_at"`
+	Deleted   bool   `json:"deleted"`
+}
+
+// TimeTotalDTO is one row of a time report. Label is the task title, project
+// name or tag, and "" for unassigned time.
+type TimeTotalDTO struct {
+	Key             string `json:"key"`
+	Label           string `json:"label"`
+	SpentMinutes    int64  `json:"spent_minutes"`
+	EstimateMinutes int64  `json:"estimate_minutes"`
+	Entries         int    `json:"entries"`
+}
+
+// StartTimer starts timing a task on this device and returns TimeEntryDTO
+// JSON. A timer already running on another task is stopped first, so each
+// device has at most one running timer.
+func (c *Core) StartTimer(taskID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if taskID == "" {
+		return errorJSON("missing id")
+	}
+	task, err := c.store.GetTask(taskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load task: %v", err))
+	}
+	if task.ID == "" {
+		return errorJSON("task not found")
+	}
+	running, errStr := c.runningTimer()
+	if errStr != "" {
+		return errStr
+	}
+	if running.ID != "" && running.TaskID == taskID {
+		return encodeTimeEntry(running)
+	}
+	now := time.Now().UTC()
+	if running.ID != "" {
+		running.End = now
+		running.UpdatedAt = now
+		if errStr := c.saveTimeEntry(running, "time_stop"); errStr != "" {
+			return errStr
+		}
+	}
+	deviceID, err := c.localDeviceID()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	entry := model.TimeEntry{
+		ID:        uuid.NewString(),
+		TaskID:    taskID,
+		DeviceID:  deviceID,
+		Start:     now,
+		UpdatedAt: now,
+	}
+	if errStr := c.saveTimeEntry(entry, "time_start"); errStr != "" {
+		return errStr
+	}
+	return encodeTimeEntry(entry)
+}
+
+// StopTimer stops this device's running timer and returns the finished
+// TimeEntryDTO JSON.
+func (c *Core) StopTimer() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	running, errStr := c.runningTimer()
+	if errStr != "" {
+		return errStr
+	}
+	if running.ID == "" {
+		return errorJSON("no running timer")
+	}
+	now := time.Now().UTC()
+	running.End = now
+	running.UpdatedAt = now
+	if errStr := c.saveTimeEntry(running, "time_stop"); errStr != "" {
+		return errStr
+	}
+	return encodeTimeEntry(running)
+}
+
+// RunningTimer returns this device's running TimeEntryDTO JSON, or an empty
+// object when no timer runs.
+func (c *Core) RunningTimer() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	running, errStr := c.runningTimer()
+	if errStr != "" {
+		return errStr
+	}
+	if running.ID == "" {
+		return "{}"
+	}
+	return encodeTimeEntry(running)
+}
+
+// ListTimeEntries returns JSON-encoded TimeEntryDTO for a task in start
+// order, or for every task when taskID is empty. Deleted entries are left out.
+func (c *Core) ListTimeEntries(taskID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	entries, err := c.store.ListTimeEntries(taskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list time entries: %v", err))
+	}
+	out := make([]TimeEntryDTO, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Deleted {
+			out = append(out, timeEntryToDTO(entry))
+		}
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode time entries: %v", err))
+	}
+	return string(data)
+}
+
+// AddTimeEntry logs a finished span of work from TimeEntryDTO JSON (task_id,
+// start, end and an optional note) and returns the stored TimeEntryDTO JSON.
+func (c *Core) AddTimeEntry(entryJSON string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	var dto TimeEntryDTO
+	if err := json.Unmarshal([]byte(entryJSON), &dto); err != nil {
+		return errorJSON(fmt.Sprintf("decode time entry: %v", err))
+	}
+	start, err := parseTime(dto.Start)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("parse start: %v", err))
+	}
+	end, err := parseTime(dto.End)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("parse end: %v", err))
+	}
+	if end.IsZero() {
+		return errorJSON("end is required; use StartTimer for running entries")
+	}
+	deviceID, err := c.localDeviceID()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	entry := model.TimeEntry{
+		ID:        uuid.NewString(),
+		TaskID:    dto.TaskID,
+		DeviceID:  deviceID,
+		Start:     start.UTC(),
+		End:       end.UTC(),
+		Note:      strings.TrimSpace(dto.Note),
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := logic.ValidateTimeEntry(entry); err != nil {
+		return errorJSON(fmt.Sprintf("validate time entry: %v", err))
+	}
+	task, err := c.store.GetTask(entry.TaskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load task: %v", err))
+	}
+	if task.ID == "" {
+		return errorJSON("task not found")
+	}
+	if errStr := c.saveTimeEntry(entry, "time_add"); errStr != "" {
+		return errStr
+	}
+	return encodeTimeEntry(entry)
+}
+
+// DeleteTimeEntry removes a time entry. Returns empty string on success.
+func (c *Core) DeleteTimeEntry(entryID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if entryID == "" {
+		return errorJSON("missing id")
+	}
+	entry, err := c.store.GetTimeEntry(entryID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load time entry: %v", err))
+	}
+	if entry.ID == "" || entry.Deleted {
+		return errorJSON("time entry not found")
+	}
+	entry.Deleted = true
+	entry.UpdatedAt = time.Now().UTC()
+	return c.saveTimeEntry(entry, "time_delete")
+}
+
+// TimeReport returns JSON-encoded TimeTotalDTO for time spent between the
+// from and to dates (inclusive, "YYYY-MM-DD" in the device zone; "" leaves
+// that end open), grouped by "task", "project" or "tag".
+func (c *Core) TimeReport(from string, to string, groupBy string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if groupBy == "" {
+		groupBy = logic.GroupByTask
+	}
+	start, err := c.localDayStart(c.resolveDate(from), 0)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("parse from: %v", err))
+	}
+	end, err := c.localDayStart(c.resolveDate(to), 1)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("parse to: %v", err))
+	}
+	entries, err := c.store.ListTimeEntries("")
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list time entries: %v", err))
+	}
+	tasks, err := c.store.ListTasks(model.TaskFilter{})
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list tasks: %v", err))
+	}
+	totals, err := logic.SummarizeTime(entries, tasks, groupBy, start, end, time.Now().UTC())
+	if err != nil {
+		return errorJSON(fmt.Sprintf("time report: %v", err))
+	}
+	labels, errStr := c.reportLabels(tasks, groupBy)
+	if errStr != "" {
+		return errStr
+	}
+	out := make([]TimeTotalDTO, 0, len(totals))
+	for _, total := range totals {
+		out = append(out, TimeTotalDTO{
+			Key:             total.Key,
+			Label:           labels(total.Key),
+			SpentMinutes:    int64(total.Spent.Round(time.Minute) / time.Minute),
+			EstimateMinutes: total.EstimateMinutes,
+			Entries:         total.Entries,
+		})
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode time report: %v", err))
+	}
+	return string(data)
+}
+
+func (c *Core) reportLabels(tasks []model.Task, groupBy string) (func(key string) string, string) {
+	names := make(map[string]string)
+	switch groupBy {
+	case logic.GroupByTask:
+		for _, task := range tasks {
+			names[task.ID] = task.Title
+		}
+	case logic.GroupByProject:
+		projects, err := c.store.ListProjects()
+		if err != nil {
+			return nil, errorJSON(fmt.Sprintf("list projects: %v", err))
+		}
+		for _, project := range projects {
+			names[project.ID] = project.Name
+		}
+	default:
+		return func(key string) string { return key }, ""
+	}
+	return func(key string) string { return names[key] }, ""
+}
+
+// localDayStart returns midnight in the device zone of a "YYYY-MM-DD" date
+// plus addDays, or the zero time for an empty date.
+func (c *Core) localDayStart(date string, addDays int) (time.Time, error) {
+	day, err := parseDate(date)
+	if err != nil || day.IsZero() {
+		return time.Time{}, err
+	}
+	year, month, dom := day.AddDate(0, 0, addDays).Date()
+	return time.Date(year, month, dom, 0, 0, 0, 0, c.location), nil
+}
+
+func (c *Core) runningTimer() (model.TimeEntry, string) {
+	deviceID, err := c.localDeviceID()
+	if err != nil {
+		return model.TimeEntry{}, errorJSON(err.Error())
+	}
+	entries, err := c.store.ListTimeEntries("")
+	if err != nil {
+		return model.TimeEntry{}, errorJSON(fmt.Sprintf("list time entries: %v", err))
+	}
+	for _, entry := range entries {
+		if entry.DeviceID == deviceID && entry.End.IsZero() && !entry.Deleted {
+			return entry, ""
+		}
+	}
+	return model.TimeEntry{}, ""
+}
+
+func (c *Core) saveTimeEntry(entry model.TimeEntry, eventType string) string {
+	if err := c.store.UpsertTimeEntry(entry); err != nil {
+		return errorJSON(fmt.Sprintf("save time entry: %v", err))
+	}
+	if err := c.appendPayloadEvent(eventType, timeEntryToDTO(entry)); err != nil {
+		return errorJSON(fmt.Sprintf("event %s: %v", eventType, err))
+	}
+	return ""
+}
+
+func (c *Core) applyTimeEntryEvent(event model.Event) error {
+	var ref TimeEntryDTO
+	_ = json.Unmarshal(event.Payload, &ref)
+	if ref.ID == "" {
+		return fmt.Errorf("missing time entry id in payload")
+	}
+	entry, err := c.store.GetTimeEntry(ref.ID)
+	if err != nil {
+		return fmt.Errorf("get time entry: %w", err)
+	}
+	updated, changed, _, err := sync.ApplyTimeEntryEvent(entry, event)
+	if err != nil {
+		return fmt.Errorf("apply time entry event: %w", err)
+	}
+	if !changed {
+		return nil
+	}
+	if err := c.store.UpsertTimeEntry(updated); err != nil {
+		return fmt.Errorf("upsert time entry: %w", err)
+	}
+	return nil
+}
+
+func encodeTimeEntry(entry model.TimeEntry) string {
+	data, err := json.Marshal(timeEntryToDTO(entry))
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode time entry: %v", err))
+	}
+	return string(data)
+}
+
+func timeEntryToDTO(entry model.TimeEntry) TimeEntryDTO {
+	return TimeEntryDTO{
+		ID:        entry.ID,
+		TaskID:    entry.TaskID,
+		DeviceID:  entry.DeviceID,
+		Start:     formatTime(entry.Start),
+		End:       formatTime(entry.End),
+		Note:      entry.Note,
+		UpdatedAt: formatTime(entry.UpdatedAt),
+		Deleted:   entry.Deleted,
+	}
+}
@@ -0,0 +1,94 @@
+package bind
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimersOnePerDeviceAndSync(t *testing.T) {
+	phone := newTestCore(t)
+	write := createTask(t, phone, TaskDTO{Title: "Write report", EstimateMinutes: 90})
+	review := createTask(t, phone, TaskDTO{Title: "Review PR"})
+
+	var first TimeEntryDTO
+	if err := json.Unmarshal([]byte(phone.StartTimer(write.ID)), &first); err != nil {
+		t.Fatalf("decode entry: %v", err)
+	}
+	if hasError(phone.StartTimer(review.ID)) {
+		t.Fatalf("start second timer failed")
+	}
+	var entries []TimeEntryDTO
+	if err := json.Unmarshal([]byte(phone.ListTimeEntries(write.ID)), &entries); err != nil {
+		t.Fatalf("decode entries: %v", err)
+	}
+	if len(entries) != 1 || entries[0].End == "" {
+		t.Fatalf("expected starting another timer to stop the first, got %+v", entries)
+	}
+	var running TimeEntryDTO
+	_ = json.Unmarshal([]byte(phone.RunningTimer()), &running)
+	if running.TaskID != review.ID {
+		t.Fatalf("expected review timer running, got %+v", running)
+	}
+	if hasError(phone.StopTimer()) {
+		t.Fatalf("stop timer failed")
+	}
+	if !hasError(phone.StopTimer()) {
+		t.Fatalf("expected stop without a running timer to fail")
+	}
+
+	laptop := newTestCore(t)
+	shareKeys(t, phone, laptop)
+	if errStr := laptop.ImportEvents(phone.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import: %s", errStr)
+	}
+	if err := json.Unmarshal([]byte(laptop.ListTimeEntries("")), &entries); err != nil {
+		t.Fatalf("decode entries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected both entries synced, got %+v", entries)
+	}
+	if laptop.RunningTimer() != "{}" {
+		t.Fatalf("expected no running timer on the laptop")
+	}
+}
+
+func TestTimeReportAgainstEstimates(t *testing.T) {
+	core := newTestCore(t)
+	task := createTask(t, core, TaskDTO{Title: "Write report", EstimateMinutes: 90, Tags: []string{"work"}})
+	if !hasError(core.UpdateTask(`{"id":"` + task.ID + `","title":"Write report","estimate_minutes":-5}`)) {
+		t.Fatalf("expected negative estimate to be rejected")
+	}
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	add := func(from time.Time, minutes int) {
+		payload, _ := json.Marshal(TimeEntryDTO{
+			TaskID: task.ID,
+			Start:  formatTime(from),
+			End:    formatTime(from.Add(time.Duration(minutes) * time.Minute)),
+		})
+		if result := core.AddTimeEntry(string(payload)); hasError(result) {
+			t.Fatalf("add entry: %s", result)
+		}
+	}
+	add(start, 60)
+	add(start.Add(3*time.Hour), 45)
+	add(start.AddDate(0, 0, 5), 30)
+	if !hasError(core.AddTimeEntry(`{"task_id":"` + task.ID + `","start":"2026-03-02T10:00:00Z","end":"2026-03-02T09:00:00Z"}`)) {
+		t.Fatalf("expected end before start to be rejected")
+	}
+
+	var totals []TimeTotalDTO
+	if err := json.Unmarshal([]byte(core.TimeReport("2026-03-02", "2026-03-03", "task")), &totals); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if len(totals) != 1 || totals[0].SpentMinutes != 105 || totals[0].EstimateMinutes != 90 || totals[0].Label != "Write report" {
+		t.Fatalf("unexpected task report: %+v", totals)
+	}
+	if err := json.Unmarshal([]byte(core.TimeReport("", "", "tag")), &totals); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if len(totals) != 1 || totals[0].Key != "work" || totals[0].SpentMinutes != 135 || totals[0].Entries != 3 {
+		t.Fatalf("unexpected tag report: %+v", totals)
+	}
+}
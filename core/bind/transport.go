@@ -0,0 +1,89 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"taskpp/core/model"
+	"taskpp/core/sync"
+)
+
+// SyncResultDTO reports what Sync exchanged and the cursor afterwards.
+type SyncResultDTO struct {
+	Pushed int          `json:"pushed"`
+	Pulled int          `json:"pulled"`
+	Cursor model.Cursor `json:"cursor"`
+}
+
+// Sync pushes this device's new events through the configured transport
+// (Config.SyncTransport) and imports what other devices published since
+// the local cursor. Returns JSON-encoded SyncResultDTO.
+func (c *Core) Sync() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return errorJSON("keys not unlocked")
+	}
+	transport, err := c.transport()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	events, err := c.store.ListEventsSince(0)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("list events: %v", err))
+	}
+	pushed, err := transport.Push(events)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("push: %v", err))
+	}
+	cursor, err := c.syncCursor()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	pulled, err := transport.Pull(cursor)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("pull: %v", err))
+	}
+	if err := c.importEvents(pulled); err != nil {
+		return errorJSON(err.Error())
+	}
+	state, err := c.store.GetSyncState()
+	if err != nil {
+		return errorJSON(fmt.Sprintf("get sync state: %v", err))
+	}
+	state.LastSync = time.Now().UTC()
+	if err := c.store.SaveSyncState(state); err != nil {
+		return errorJSON(fmt.Sprintf("save sync state: %v", err))
+	}
+	if cursor, err = c.syncCursor(); err != nil {
+		return errorJSON(err.Error())
+	}
+	data, err := json.Marshal(SyncResultDTO{Pushed: pushed, Pulled: len(pulled), Cursor: cursor})
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode sync result: %v", err))
+	}
+	return string(data)
+}
+
+// transport builds the transport Config.SyncTransport selects.
+func (c *Core) transport() (sync.Transport, error) {
+	localID, err := c.localDeviceID()
+	if err != nil {
+		return nil, err
+	}
+	switch c.syncTransport {
+	case "folder":
+		if c.syncFolder == "" {
+			return nil, fmt.Errorf("sync_folder is required for the folder transport")
+		}
+		return sync.NewFolderTransport(c.syncFolder, localID), nil
+	case "":
+		return nil, fmt.Errorf("no sync transport configured")
+	default:
+		return nil, fmt.Errorf("unknown sync transport: %s", c.syncTransport)
+	}
+}
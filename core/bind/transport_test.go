@@ -0,0 +1,72 @@
+package bind
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func newFolderCore(t *testing.T, folder string) *Core {
+	t.Helper()
+	cfgJSON, _ := json.Marshal(Config{
+		StorageDriver: "sqlite",
+		StoragePath:   "file:" + filepath.Join(t.TempDir(), "bind.db"),
+		SyncTransport: "folder",
+		SyncFolder:    folder,
+	})
+	core := NewCore(string(cfgJSON))
+	if errStr := core.Open(); errStr != "" {
+		t.Fatalf("open: %s", errStr)
+	}
+	t.Cleanup(func() { core.Close() })
+	return core
+}
+
+func syncCore(t *testing.T, core *Core) SyncResultDTO {
+	t.Helper()
+	out := core.Sync()
+	var result SyncResultDTO
+	if err := json.Unmarshal([]byte(out), &result); err != nil || hasError(out) {
+		t.Fatalf("sync: %v (%s)", err, out)
+	}
+	return result
+}
+
+func TestSyncThroughFolder(t *testing.T) {
+	folder := t.TempDir()
+	phone := newFolderCore(t, folder)
+	if errStr := phone.InitKeys("passphrase"); errStr != "" {
+		t.Fatalf("init keys: %s", errStr)
+	}
+	laptop := newFolderCore(t, folder)
+	shareKeys(t, phone, laptop)
+
+	task := createTask(t, phone, TaskDTO{Title: "Shared"})
+	if result := syncCore(t, phone); result.Pushed != 1 || result.Pulled != 0 {
+		t.Fatalf("unexpected phone sync: %+v", result)
+	}
+	if result := syncCore(t, laptop); result.Pulled != 1 {
+		t.Fatalf("unexpected laptop sync: %+v", result)
+	}
+	if _, ok := taskByID(t, laptop, task.ID); !ok {
+		t.Fatalf("expected the task on the laptop")
+	}
+
+	if errStr := laptop.SetCompleted(task.ID, true); errStr != "" {
+		t.Fatalf("complete: %s", errStr)
+	}
+	syncCore(t, laptop)
+	if result := syncCore(t, phone); result.Pulled != 1 || result.Pushed != 0 {
+		t.Fatalf("unexpected second phone sync: %+v", result)
+	}
+	if got, _ := taskByID(t, phone, task.ID); got.Status != "done" {
+		t.Fatalf("expected completion to reach the phone, got %+v", got)
+	}
+	if result := syncCore(t, phone); result.Pulled != 0 {
+		t.Fatalf("expected nothing new, got %+v", result)
+	}
+
+	if !hasError(newTestCore(t).Sync()) {
+		t.Fatalf("expected sync without a transport to fail")
+	}
+}
@@ -0,0 +1,431 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"taskpp/core/logic"
+	"taskpp/core/model"
+	"taskpp/core/storage"
+)
+
+// MaxUndoDepth bounds the undo stack; older steps are dropped.
+const MaxUndoDepth = 50
+
+// undoStep is one user action: the task changes it logged, in the order
+// they were appended. changedElsewhere names a task another device has
+// changed since, which makes the step unsafe to replay.
+type undoStep struct {
+	changes          []undoChange
+	changedElsewhere string
+}
+
+// undoChange is one logged task event with the snapshots on either side of
+// it. before is nil when the event created the task and after is nil when
+// it deleted it; comments and entries are what the delete removed.
+type undoChange struct {
+	taskID   string
+	before   *TaskDTO
+	after    *TaskDTO
+	comments []model.Comment
+	entries  []model.TimeEntry
+}
+
+// Undo reverts the most recent action of this session by restoring every
+// task it touched to the snapshot it had before. The restores are logged
+// as "undo" events (or "delete" for tasks the action created), so the undo
+// syncs like any other edit. An action whose tasks were changed on another
+// device since is refused and dropped. Returns empty string on success.
+func (c *Core) Undo() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.replay(true)
+}
+
+// Redo reapplies the most recently undone action from the snapshots its
+// own events logged, as "redo" events. Any new action clears the redo
+// stack. Returns empty string on success.
+func (c *Core) Redo() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.replay(false)
+}
+
+func (c *Core) replay(undo bool) string {
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	if c.keys == nil || !c.keys.IsUnlocked() {
+		return errorJSON("keys not unlocked")
+	}
+	from, to := &c.undoStack, &c.redoStack
+	if !undo {
+		from, to = to, from
+	}
+	if len(*from) == 0 {
+		if undo {
+			return errorJSON("nothing to undo")
+		}
+		return errorJSON("nothing to redo")
+	}
+	step := (*from)[len(*from)-1]
+	if step.changedElsewhere != "" {
+		*from = (*from)[:len(*from)-1]
+		return errorJSON(fmt.Sprintf("task %s was changed on another device", step.changedElsewhere))
+	}
+
+	c.replaying = true
+	defer func() { c.replaying = false }()
+	now := time.Now().UTC()
+	for i := range step.changes {
+		change := step.changes[i]
+		snapshot := change.after
+		if undo {
+			change = step.changes[len(step.changes)-1-i]
+			snapshot = change.before
+		}
+		var err error
+		if snapshot == nil {
+			err = c.removeTask(change.taskID)
+		} else {
+			err = c.restoreTask(*snapshot, undoEventType(undo), now)
+		}
+		if err == nil && undo && change.after == nil && change.before != nil {
+			err = c.restoreTaskRecords(change, now)
+		}
+		if err != nil {
+			return errorJSON(err.Error())
+		}
+	}
+
+	*from = (*from)[:len(*from)-1]
+	*to = append(*to, step)
+	return ""
+}
+
+func undoEventType(undo bool) string {
+	if undo {
+		return "undo"
+	}
+	return "redo"
+}
+
+// undoGroup makes every task event logged until the returned function runs
+// part of one undo step. Actions that cascade (completion, deletes of a
+// subtree, rebalancing) call it so one Undo reverts the whole action.
+func (c *Core) undoGroup() func() {
+	c.undoGroupDepth++
+	return func() {
+		c.undoGroupDepth--
+		if c.undoGroupDepth == 0 {
+			c.undoGroupOpen = false
+		}
+	}
+}
+
+// recordUndo pushes a freshly logged task event onto the undo stack and
+// clears the redo stack. previous is what the task store kept from before
+// the write, nil when the event did not go through it.
+func (c *Core) recordUndo(eventType string, task model.Task, previous *taskRecord) {
+	if c.replaying {
+		return
+	}
+	after := taskToDTO(task)
+	change := undoChange{taskID: task.ID, after: &after}
+	if eventType == "delete" {
+		change.after = nil
+	}
+	switch {
+	case previous == nil:
+		before := after
+		change.before = &before
+	case previous.task != nil:
+		before := taskToDTO(*previous.task)
+		change.before = &before
+		change.comments, change.entries = previous.comments, previous.entries
+	}
+	if c.undoGroupOpen && len(c.undoStack) > 0 {
+		last := &c.undoStack[len(c.undoStack)-1]
+		last.changes = append(last.changes, change)
+	} else {
+		c.undoStack = append(c.undoStack, undoStep{changes: []undoChange{change}})
+		c.undoGroupOpen = c.undoGroupDepth > 0
+	}
+	if len(c.undoStack) > MaxUndoDepth {
+		c.undoStack = c.undoStack[len(c.undoStack)-MaxUndoDepth:]
+	}
+	c.redoStack = nil
+}
+
+// changedElsewhere marks the undo and redo steps touching taskID once an
+// imported event has changed the task, so replaying them cannot overwrite
+// the other device's edit.
+func (c *Core) changedElsewhere(taskID string) {
+	c.tasks.forget(taskID)
+	for _, steps := range [][]undoStep{c.undoStack, c.redoStack} {
+		for i := range steps {
+			for _, change := range steps[i].changes {
+				if change.taskID == taskID {
+					steps[i].changedElsewhere = taskID
+					break
+				}
+			}
+		}
+	}
+}
+
+// restoreTaskRecords brings back the comments and time entries deleted
+// together with a task, logged so other devices restore them too.
+func (c *Core) restoreTaskRecords(change undoChange, now time.Time) error {
+	for _, comment := range change.comments {
+		comment.UpdatedAt = now
+		if errStr := c.saveComment(comment, "comment_add"); errStr != "" {
+			return fmt.Errorf("restore comment %s: %s", comment.ID, errStr)
+		}
+	}
+	for _, entry := range change.entries {
+		entry.UpdatedAt = now
+		if errStr := c.saveTimeEntry(entry, "time_add"); errStr != "" {
+			return fmt.Errorf("restore time entry %s: %s", entry.ID, errStr)
+		}
+	}
+	return nil
+}
+
+// taskStore is the storage Core writes through. For each task written
+// since its last logged event it keeps the task as it was before, and the
+// comments and time entries a delete removed, for the undo step of that
+// event.
+type taskStore struct {
+	storage.Storage
+	pending map[string]*taskRecord
+}
+
+// taskRecord is a task as it was before a write; task is nil when it did
+// not exist.
+type taskRecord struct {
+	task     *model.Task
+	comments []model.Comment
+	entries  []model.TimeEntry
+}
+
+func newTaskStore(store storage.Storage) *taskStore {
+	return &taskStore{Storage: store, pending: make(map[string]*taskRecord)}
+}
+
+func (s *taskStore) UpsertTask(task model.Task) error {
+	if _, err := s.remember(task.ID); err != nil {
+		return err
+	}
+	return s.Storage.UpsertTask(task)
+}
+
+func (s *taskStore) DeleteTask(id string) error {
+	if _, err := s.remember(id); err != nil {
+		return err
+	}
+	return s.Storage.DeleteTask(id)
+}
+
+func (s *taskStore) DeleteTaskComments(taskID string) error {
+	record, err := s.remember(taskID)
+	if err != nil {
+		return err
+	}
+	comments, err := s.Storage.ListComments(taskID)
+	if err != nil {
+		return err
+	}
+	record.comments = append(record.comments, comments...)
+	return s.Storage.DeleteTaskComments(taskID)
+}
+
+func (s *taskStore) DeleteTaskTimeEntries(taskID string) error {
+	record, err := s.remember(taskID)
+	if err != nil {
+		return err
+	}
+	entries, err := s.Storage.ListTimeEntries(taskID)
+	if err != nil {
+		return err
+	}
+	record.entries = append(record.entries, entries...)
+	return s.Storage.DeleteTaskTimeEntries(taskID)
+}
+
+// remember returns the pending record of taskID, reading the task before
+// its first write.
+func (s *taskStore) remember(taskID string) (*taskRecord, error) {
+	if record, ok := s.pending[taskID]; ok {
+		return record, nil
+	}
+	task, err := s.Storage.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	record := &taskRecord{}
+	if task.ID != "" {
+		record.task = &task
+	}
+	s.pending[taskID] = record
+	return record, nil
+}
+
+// forget returns and drops the pending record of taskID.
+func (s *taskStore) forget(taskID string) *taskRecord {
+	record := s.pending[taskID]
+	delete(s.pending, taskID)
+	return record
+}
+
+// loggedTask is a decrypted task event.
+type loggedTask struct {
+	id       string
+	taskID   string
+	deviceID string
+	seq      int64
+	ts       time.Time
+	kind     string
+	dto      TaskDTO
+}
+
+type taskLog struct {
+	byID   map[string]loggedTask
+	byTask map[string][]loggedTask
+}
+
+// taskEventLog decrypts the task events in the log, indexed by event id and
+// by task in the order they were made.
+func (c *Core) taskEventLog() (taskLog, error) {
+	events, err := c.store.ListEventsSince(0)
+	if err != nil {
+		return taskLog{}, fmt.Errorf("list events: %w", err)
+	}
+	log := taskLog{byID: make(map[string]loggedTask), byTask: make(map[string][]loggedTask)}
+	for _, event := range events {
+		if !isTaskEvent(event.Type) {
+			continue
+		}
+		plaintext, err := c.keys.Decrypt(event.Payload)
+		if err != nil {
+			return taskLog{}, fmt.Errorf("decrypt event payload: %w", err)
+		}
+		var dto TaskDTO
+		if err := json.Unmarshal(plaintext, &dto); err != nil {
+			return taskLog{}, fmt.Errorf("decode event payload: %w", err)
+		}
+		entry := loggedTask{
+			id:       event.ID,
+			taskID:   dto.ID,
+			deviceID: event.DeviceID,
+			seq:      event.Seq,
+			ts:       event.TS,
+			kind:     event.Type,
+			dto:      dto,
+		}
+		log.byID[event.ID] = entry
+		log.byTask[dto.ID] = append(log.byTask[dto.ID], entry)
+	}
+	for _, entries := range log.byTask {
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].ts.Before(entries[j].ts) })
+	}
+	return log, nil
+}
+
+func isTaskEvent(eventType string) bool {
+	for _, prefix := range []string{"project_", "comment_", "time_", "workflow_", "device_"} {
+		if strings.HasPrefix(eventType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// restoreTask writes a logged snapshot back as the current task. Checklist
+// items the snapshot lacks are tombstoned so the merge on other devices
+// drops them too. Items it brings back are added again after their
+// tombstone, and restored text, ticks and order are stamped now so they win
+// the per-item merge.
+func (c *Core) restoreTask(dto TaskDTO, eventType string, now time.Time) error {
+	task, err := dtoToTask(dto)
+	if err != nil {
+		return fmt.Errorf("convert snapshot: %w", err)
+	}
+	current, err := c.store.GetTask(task.ID)
+	if err != nil {
+		return fmt.Errorf("load task: %w", err)
+	}
+	live := make(map[string]model.ChecklistItem, len(current.Checklist))
+	for _, item := range current.Checklist {
+		live[item.ID] = item
+	}
+	removed := make(map[string]time.Time, len(current.ChecklistRemoved))
+	for _, id := range current.ChecklistRemoved {
+		removed[id] = current.ChecklistRemovedAt[id]
+	}
+	for i := range task.Checklist {
+		item := &task.Checklist[i]
+		existing, ok := live[item.ID]
+		if !ok {
+			item.AddedAt, item.UpdatedAt, item.OrderedAt = now, now, now
+			delete(removed, item.ID)
+			continue
+		}
+		delete(live, item.ID)
+		item.AddedAt = existing.AddedAt
+		if item.Text != existing.Text || item.Checked != existing.Checked {
+			item.UpdatedAt = now
+		} else {
+			item.UpdatedAt = existing.UpdatedAt
+		}
+		if item.Order != existing.Order {
+			item.OrderedAt = now
+		} else {
+			item.OrderedAt = existing.OrderedAt
+		}
+	}
+	for id := range live {
+		removed[id] = now
+	}
+	task.ChecklistRemoved, task.ChecklistRemovedAt = logic.ChecklistTombstones(removed)
+	wf, err := c.workflow()
+	if err != nil {
+		return err
+	}
+	task.State = logic.StateOf(task, wf)
+	task.UpdatedAt = now
+	if err := c.store.UpsertTask(task); err != nil {
+		return fmt.Errorf("restore task: %w", err)
+	}
+	if err := c.appendEvent(eventType, task); err != nil {
+		return fmt.Errorf("event %s: %w", eventType, err)
+	}
+	return nil
+}
+
+// removeTask deletes a task an undone action had created.
+func (c *Core) removeTask(taskID string) error {
+	task, err := c.store.GetTask(taskID)
+	if err != nil {
+		return fmt.Errorf("load task: %w", err)
+	}
+	if task.ID == "" {
+		return nil
+	}
+	if err := c.store.DeleteTask(taskID); err != nil {
+		return fmt.Errorf("delete task: %w", err)
+	}
+	if err := c.store.DeleteTaskComments(taskID); err != nil {
+		return fmt.Errorf("delete comments: %w", err)
+	}
+	if err := c.store.DeleteTaskTimeEntries(taskID); err != nil {
+		return fmt.Errorf("delete time entries: %w", err)
+	}
+	if err := c.appendEvent("delete", task); err != nil {
+		return fmt.Errorf("event delete: %w", err)
+	}
+	return nil
+}
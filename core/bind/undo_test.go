@@ -0,0 +1,289 @@
+package bind
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func taskByID(t *testing.T, core *Core, id string) (TaskDTO, bool) {
+	t.Helper()
+	for _, task := range decodeTasks(t, core.ListTasks(`{"include_deferred":true}`)) {
+		if task.ID == id {
+			return task, true
+		}
+	}
+	return TaskDTO{}, false
+}
+
+func TestUndoRedoUpdateAndCreate(t *testing.T) {
+	core := newTestCore(t)
+	if !hasError(core.Undo()) {
+		t.Fatalf("expected nothing to undo")
+	}
+	task := createTask(t, core, TaskDTO{Title: "Draft"})
+	task.Title = "Final"
+	payload, _ := json.Marshal(task)
+	if out := core.UpdateTask(string(payload)); hasError(out) {
+		t.Fatalf("update: %s", out)
+	}
+
+	if errStr := core.Undo(); errStr != "" {
+		t.Fatalf("undo update: %s", errStr)
+	}
+	if got, _ := taskByID(t, core, task.ID); got.Title != "Draft" {
+		t.Fatalf("expected title restored, got %q", got.Title)
+	}
+	if errStr := core.Undo(); errStr != "" {
+		t.Fatalf("undo create: %s", errStr)
+	}
+	if _, ok := taskByID(t, core, task.ID); ok {
+		t.Fatalf("expected undoing the create to remove the task")
+	}
+
+	if errStr := core.Redo(); errStr != "" {
+		t.Fatalf("redo create: %s", errStr)
+	}
+	if errStr := core.Redo(); errStr != "" {
+		t.Fatalf("redo update: %s", errStr)
+	}
+	if got, _ := taskByID(t, core, task.ID); got.Title != "Final" {
+		t.Fatalf("expected redo to reapply the update, got %+v", got)
+	}
+	if !hasError(core.Redo()) {
+		t.Fatalf("expected nothing to redo")
+	}
+}
+
+func TestUndoCompletionRevertsWholeAction(t *testing.T) {
+	core := newTestCore(t)
+	today := time.Now().UTC().Format("2006-01-02")
+	task := createTask(t, core, TaskDTO{Title: "Water plants", DueDate: today, Recurrence: "daily"})
+	if errStr := core.SetCompleted(task.ID, true); errStr != "" {
+		t.Fatalf("complete: %s", errStr)
+	}
+	if tasks := decodeTasks(t, core.ListTasks("")); len(tasks) != 2 {
+		t.Fatalf("expected completed task and next instance, got %d", len(tasks))
+	}
+	if errStr := core.Undo(); errStr != "" {
+		t.Fatalf("undo: %s", errStr)
+	}
+	tasks := decodeTasks(t, core.ListTasks(""))
+	if len(tasks) != 1 || tasks[0].Status != "active" || tasks[0].CompletedAt != "" {
+		t.Fatalf("expected one reopened task, got %+v", tasks)
+	}
+}
+
+func TestUndoSyncsAndClearsRedo(t *testing.T) {
+	source := newTestCore(t)
+	target := newTestCore(t)
+	shareKeys(t, source, target)
+	task := createTask(t, source, TaskDTO{Title: "Draft"})
+	task.Title = "Final"
+	payload, _ := json.Marshal(task)
+	if out := source.UpdateTask(string(payload)); hasError(out) {
+		t.Fatalf("update: %s", out)
+	}
+	if errStr := source.Undo(); errStr != "" {
+		t.Fatalf("undo: %s", errStr)
+	}
+	if errStr := target.ImportEvents(source.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import: %s", errStr)
+	}
+	if got, _ := taskByID(t, target, task.ID); got.Title != "Draft" {
+		t.Fatalf("expected undo to sync, got %+v", got)
+	}
+
+	createTask(t, source, TaskDTO{Title: "Other"})
+	if !hasError(source.Redo()) {
+		t.Fatalf("expected a new action to clear the redo stack")
+	}
+}
+
+func TestUndoStackEndsWithSession(t *testing.T) {
+	cfgJSON, _ := json.Marshal(Config{StoragePath: "file:" + filepath.Join(t.TempDir(), "bind.db")})
+	first := NewCore(string(cfgJSON))
+	if errStr := first.Open(); errStr != "" {
+		t.Fatalf("open: %s", errStr)
+	}
+	if errStr := first.InitKeys("passphrase"); errStr != "" {
+		t.Fatalf("init keys: %s", errStr)
+	}
+	task := createTask(t, first, TaskDTO{Title: "Draft"})
+	first.Close()
+
+	second := NewCore(string(cfgJSON))
+	if errStr := second.Open(); errStr != "" {
+		t.Fatalf("reopen: %s", errStr)
+	}
+	t.Cleanup(func() { second.Close() })
+	if errStr := second.UnlockKeys("passphrase"); errStr != "" {
+		t.Fatalf("unlock: %s", errStr)
+	}
+	if !hasError(second.Undo()) {
+		t.Fatalf("expected an earlier session's actions to be out of reach")
+	}
+	if _, ok := taskByID(t, second, task.ID); !ok {
+		t.Fatalf("expected the task to stay")
+	}
+}
+
+func TestUndoRestoresSnapshotVersion(t *testing.T) {
+	phone := newTestCore(t)
+	tablet := newTestCore(t)
+	shareKeys(t, phone, tablet)
+	task := createTask(t, phone, TaskDTO{Title: "Draft"})
+	if out := phone.Compact(); hasError(out) {
+		t.Fatalf("compact: %s", out)
+	}
+	if errStr := tablet.ImportSnapshot(phone.ExportSnapshot()); errStr != "" {
+		t.Fatalf("bootstrap: %s", errStr)
+	}
+
+	// The tablet's log starts with its own update; the create only lives in
+	// the snapshot.
+	task.Title = "Final"
+	payload, _ := json.Marshal(task)
+	if out := tablet.UpdateTask(string(payload)); hasError(out) {
+		t.Fatalf("update: %s", out)
+	}
+	if errStr := tablet.Undo(); errStr != "" {
+		t.Fatalf("undo: %s", errStr)
+	}
+	if got, ok := taskByID(t, tablet, task.ID); !ok || got.Title != "Draft" {
+		t.Fatalf("expected the snapshot version back, got %+v", got)
+	}
+}
+
+func TestUndoRedoChecklistItems(t *testing.T) {
+	source := newTestCore(t)
+	target := newTestCore(t)
+	shareKeys(t, source, target)
+	task := createTask(t, source, TaskDTO{Title: "Pack"})
+	var item ChecklistItemDTO
+	if err := json.Unmarshal([]byte(source.AddChecklistItem(task.ID, "Socks")), &item); err != nil {
+		t.Fatalf("add item: %v", err)
+	}
+	checklist := func(core *Core) []ChecklistItemDTO {
+		got, _ := taskByID(t, core, task.ID)
+		return got.Checklist
+	}
+
+	if errStr := source.Undo(); errStr != "" {
+		t.Fatalf("undo add: %s", errStr)
+	}
+	if items := checklist(source); len(items) != 0 {
+		t.Fatalf("expected undo to remove the item, got %+v", items)
+	}
+	if errStr := source.Redo(); errStr != "" {
+		t.Fatalf("redo add: %s", errStr)
+	}
+	if items := checklist(source); len(items) != 1 || items[0].ID != item.ID {
+		t.Fatalf("expected redo to bring the item back, got %+v", items)
+	}
+
+	if errStr := source.RemoveChecklistItem(task.ID, item.ID); errStr != "" {
+		t.Fatalf("remove item: %s", errStr)
+	}
+	if errStr := target.ImportEvents(source.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import: %s", errStr)
+	}
+	if items := checklist(target); len(items) != 0 {
+		t.Fatalf("expected the removal to sync, got %+v", items)
+	}
+	if errStr := source.Undo(); errStr != "" {
+		t.Fatalf("undo remove: %s", errStr)
+	}
+	if items := checklist(source); len(items) != 1 {
+		t.Fatalf("expected undo to bring the item back, got %+v", items)
+	}
+	if errStr := target.ImportEvents(source.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import undo: %s", errStr)
+	}
+	if items := checklist(target); len(items) != 1 || items[0].Text != "Socks" {
+		t.Fatalf("expected the item to come back on the other device, got %+v", items)
+	}
+}
+
+func TestUndoRefusesAfterRemoteEdit(t *testing.T) {
+	phone := newTestCore(t)
+	laptop := newTestCore(t)
+	shareKeys(t, phone, laptop)
+	task := createTask(t, phone, TaskDTO{Title: "Draft"})
+	task.Title = "Phone"
+	payload, _ := json.Marshal(task)
+	if out := phone.UpdateTask(string(payload)); hasError(out) {
+		t.Fatalf("update on phone: %s", out)
+	}
+	if errStr := laptop.ImportEvents(phone.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import on laptop: %s", errStr)
+	}
+	time.Sleep(2 * time.Millisecond)
+	task.Title = "Laptop"
+	payload, _ = json.Marshal(task)
+	if out := laptop.UpdateTask(string(payload)); hasError(out) {
+		t.Fatalf("update on laptop: %s", out)
+	}
+	if errStr := phone.ImportEvents(laptop.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import on phone: %s", errStr)
+	}
+
+	if !hasError(phone.Undo()) {
+		t.Fatalf("expected undo to refuse a task edited on another device")
+	}
+	if got, _ := taskByID(t, phone, task.ID); got.Title != "Laptop" {
+		t.Fatalf("expected the remote edit to stay, got %q", got.Title)
+	}
+	// Both phone steps touch the task, so neither is replayed.
+	if !hasError(phone.Undo()) {
+		t.Fatalf("expected the create to be refused too")
+	}
+	if out := phone.Undo(); !strings.Contains(out, "nothing to undo") {
+		t.Fatalf("expected refused steps to be dropped, got %s", out)
+	}
+	if _, ok := taskByID(t, phone, task.ID); !ok {
+		t.Fatalf("expected the task to stay")
+	}
+}
+
+func TestUndoDeleteRestoresCommentsAndTime(t *testing.T) {
+	source := newTestCore(t)
+	target := newTestCore(t)
+	shareKeys(t, source, target)
+	task := createTask(t, source, TaskDTO{Title: "Report"})
+	if out := source.AddComment(task.ID, "First draft"); hasError(out) {
+		t.Fatalf("comment: %s", out)
+	}
+	entry, _ := json.Marshal(TimeEntryDTO{TaskID: task.ID, Start: "2026-03-02T09:00:00Z", End: "2026-03-02T10:00:00Z"})
+	if out := source.AddTimeEntry(string(entry)); hasError(out) {
+		t.Fatalf("time entry: %s", out)
+	}
+	if errStr := source.DeleteTask(task.ID); errStr != "" {
+		t.Fatalf("delete: %s", errStr)
+	}
+	if errStr := target.ImportEvents(source.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import delete: %s", errStr)
+	}
+	if errStr := source.Undo(); errStr != "" {
+		t.Fatalf("undo delete: %s", errStr)
+	}
+	if errStr := target.ImportEvents(source.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import undo: %s", errStr)
+	}
+
+	for name, core := range map[string]*Core{"source": source, "target": target} {
+		if _, ok := taskByID(t, core, task.ID); !ok {
+			t.Fatalf("%s: expected the task back", name)
+		}
+		var comments []CommentDTO
+		if err := json.Unmarshal([]byte(core.ListComments(task.ID)), &comments); err != nil || len(comments) != 1 {
+			t.Fatalf("%s: expected the comment back, got %v %+v", name, err, comments)
+		}
+		var entries []TimeEntryDTO
+		if err := json.Unmarshal([]byte(core.ListTimeEntries(task.ID)), &entries); err != nil || len(entries) != 1 {
+			t.Fatalf("%s: expected the time entry back, got %v %+v", name, err, entries)
+		}
+	}
+}
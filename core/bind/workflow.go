@@ -0,0 +1,260 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"taskpp/core/logic"
+	"taskpp/core/model"
+	"taskpp/core/sync"
+)
+
+// WorkflowStateDTO is a bind-safe workflow state. Category is "open" or
+// "done".
+type WorkflowStateDTO struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+// WorkflowTransitionDTO allows moving a task from one state to another.
+type WorkflowTransitionDTO struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// WorkflowDTO is the bind-safe workflow configuration. An empty transitions
+// list allows every move.
+type WorkflowDTO struct {
+	States      []WorkflowStateDTO      `json:"states"`
+	Transitions []WorkflowTransitionDTO `json:"transitions"`
+	UpdatedAt   string                  `json:"updated_at"`
+}
+
+// GetWorkflow returns the current WorkflowDTO JSON.
+func (c *Core) GetWorkflow() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	wf, err := c.workflow()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	data, err := json.Marshal(workflowToDTO(wf))
+	if err != nil {
+		return errorJSON(fmt.Sprintf("encode workflow: %v", err))
+	}
+	return string(data)
+}
+
+// SetWorkflow replaces the workflow with WorkflowDTO JSON. Tasks in a state
+// that no longer exists move to the first state of their category. Returns
+// empty string on success.
+func (c *Core) SetWorkflow(workflowJSON string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	var dto WorkflowDTO
+	if err := json.Unmarshal([]byte(workflowJSON), &dto); err != nil {
+		return errorJSON(fmt.Sprintf("decode workflow: %v", err))
+	}
+	wf := dtoToWorkflow(dto)
+	if err := logic.ValidateWorkflow(wf); err != nil {
+		return errorJSON(fmt.Sprintf("validate workflow: %v", err))
+	}
+	wf.UpdatedAt = time.Now().UTC()
+	if err := c.store.SaveWorkflow(wf); err != nil {
+		return errorJSON(fmt.Sprintf("save workflow: %v", err))
+	}
+	if err := c.appendPayloadEvent("workflow_update", workflowToDTO(wf)); err != nil {
+		return errorJSON(fmt.Sprintf("event workflow_update: %v", err))
+	}
+	if err := c.normalizeTaskStates(wf); err != nil {
+		return errorJSON(err.Error())
+	}
+	return ""
+}
+
+// TransitionTask moves a task to a workflow state, if the workflow allows
+// the move from its current state. Entering a done state completes the task
+// the same way SetCompleted does. Returns empty string on success.
+func (c *Core) TransitionTask(taskID string, state string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		return errorJSON("storage not initialized")
+	}
+	defer c.undoGroup()()
+	if taskID == "" {
+		return errorJSON("missing id")
+	}
+	task, err := c.store.GetTask(taskID)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("load task: %v", err))
+	}
+	if task.ID == "" {
+		return errorJSON("task not found")
+	}
+	wf, err := c.workflow()
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	now := time.Now().UTC()
+	completed, err := moveToState(&task, wf, state, now)
+	if err != nil {
+		return errorJSON(fmt.Sprintf("transition: %v", err))
+	}
+	task.UpdatedAt = now
+	if err := c.store.UpsertTask(task); err != nil {
+		return errorJSON(fmt.Sprintf("transition: %v", err))
+	}
+	if err := c.appendEvent("set_state", task); err != nil {
+		return errorJSON(fmt.Sprintf("event set state: %v", err))
+	}
+	if completed {
+		if err := c.afterCompleted(task); err != nil {
+			return errorJSON(err.Error())
+		}
+	}
+	return ""
+}
+
+// moveToState applies an allowed transition and reports whether it
+// completed the task.
+func moveToState(task *model.Task, wf model.Workflow, state string, now time.Time) (bool, error) {
+	from := logic.StateOf(*task, wf)
+	if !logic.CanTransition(wf, from, state) {
+		return false, fmt.Errorf("%s -> %s not allowed", from, state)
+	}
+	wasDone := logic.IsDone(*task)
+	if err := logic.ApplyState(task, wf, state, now); err != nil {
+		return false, err
+	}
+	completed := logic.IsDone(*task) && !wasDone
+	if completed && logic.IsRecurring(task.Recurrence) && task.SeriesID == "" {
+		task.SeriesID = task.ID
+	}
+	return completed, nil
+}
+
+// afterCompleted runs the follow-ups of completing a task through a state
+// change: its subtasks complete and a recurring task spawns its next instance.
+func (c *Core) afterCompleted(task model.Task) error {
+	if err := c.completeDescendants(task.ID); err != nil {
+		return fmt.Errorf("complete subtasks: %w", err)
+	}
+	if err := c.spawnNextInstance(task); err != nil {
+		return fmt.Errorf("recur: %w", err)
+	}
+	return nil
+}
+
+// workflow returns the configured workflow, or the default one.
+func (c *Core) workflow() (model.Workflow, error) {
+	wf, err := c.store.GetWorkflow()
+	if err != nil {
+		return model.Workflow{}, fmt.Errorf("get workflow: %w", err)
+	}
+	if len(wf.States) == 0 {
+		return logic.DefaultWorkflow(), nil
+	}
+	return wf, nil
+}
+
+// resolveTaskState settles a created or updated task's workflow state. An
+// explicit state change sets the status; otherwise the state follows the
+// status, so clients that only know active/done keep working. Either way an
+// update must be an allowed transition.
+func (c *Core) resolveTaskState(task *model.Task, previous model.Task) error {
+	wf, err := c.workflow()
+	if err != nil {
+		return err
+	}
+	if task.State == "" || task.State == previous.State {
+		task.State = logic.StateOf(*task, wf)
+	} else if err := logic.ApplyState(task, wf, task.State, task.UpdatedAt); err != nil {
+		return err
+	}
+	if previous.ID != "" {
+		from := logic.StateOf(previous, wf)
+		if !logic.CanTransition(wf, from, task.State) {
+			return fmt.Errorf("transition %s -> %s not allowed", from, task.State)
+		}
+	}
+	return nil
+}
+
+// normalizeTaskStates moves every task whose state is unknown to wf, or
+// disagrees with its status, into the first state of its category. It only
+// touches local rows: every device derives the same states from the synced
+// workflow, so no events are logged.
+func (c *Core) normalizeTaskStates(wf model.Workflow) error {
+	tasks, err := c.store.ListTasks(model.TaskFilter{})
+	if err != nil {
+		return fmt.Errorf("list tasks: %w", err)
+	}
+	for _, task := range tasks {
+		state := logic.StateOf(task, wf)
+		if state == task.State {
+			continue
+		}
+		task.State = state
+		if err := c.store.UpsertTask(task); err != nil {
+			return fmt.Errorf("upsert task: %w", err)
+		}
+		c.tasks.forget(task.ID)
+	}
+	return nil
+}
+
+func (c *Core) applyWorkflowEvent(event model.Event) error {
+	current, err := c.store.GetWorkflow()
+	if err != nil {
+		return fmt.Errorf("get workflow: %w", err)
+	}
+	updated, changed, _, err := sync.ApplyWorkflowEvent(current, event)
+	if err != nil {
+		return fmt.Errorf("apply workflow event: %w", err)
+	}
+	if !changed {
+		return nil
+	}
+	if err := logic.ValidateWorkflow(updated); err != nil {
+		return fmt.Errorf("validate workflow: %w", err)
+	}
+	if err := c.store.SaveWorkflow(updated); err != nil {
+		return fmt.Errorf("save workflow: %w", err)
+	}
+	return c.normalizeTaskStates(updated)
+}
+
+func workflowToDTO(wf model.Workflow) WorkflowDTO {
+	dto := WorkflowDTO{
+		States:      make([]WorkflowStateDTO, 0, len(wf.States)),
+		Transitions: make([]WorkflowTransitionDTO, 0, len(wf.Transitions)),
+		UpdatedAt:   formatTime(wf.UpdatedAt),
+	}
+	for _, state := range wf.States {
+		dto.States = append(dto.States, WorkflowStateDTO{ID: state.ID, Name: state.Name, Category: state.Category})
+	}
+	for _, transition := range wf.Transitions {
+		dto.Transitions = append(dto.Transitions, WorkflowTransitionDTO{From: transition.From, To: transition.To})
+	}
+	return dto
+}
+
+func dtoToWorkflow(dto WorkflowDTO) model.Workflow {
+	var wf model.Workflow
+	for _, state := range dto.States {
+		wf.States = append(wf.States, model.WorkflowState{ID: state.ID, Name: state.Name, Category: state.Category})
+	}
+	for _, transition := range dto.Transitions {
+		wf.Transitions = append(wf.Transitions, model.WorkflowTransition{From: transition.From, To: transition.To})
+	}
+	return wf
+}
@@ -0,0 +1,150 @@
+package bind
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const reviewWorkflowJSON = `{
+	"states": [
+		{"id": "todo", "name": "To do", "category": "open"},
+		{"id": "doing", "name": "Doing", "category": "open"},
+		{"id": "review", "name": "Review", "category": "open"},
+		{"id": "shipped", "name": "Shipped", "category": "done"}
+	],
+	"transitions": [
+		{"from": "todo", "to": "doing"},
+		{"from": "doing", "to": "review"},
+		{"from": "review", "to": "shipped"}
+	]
+}`
+
+func TestWorkflowTransitionsKeepCompletionRules(t *testing.T) {
+	core := newTestCore(t)
+	task := createTask(t, core, TaskDTO{Title: "Ship release"})
+	if task.State != "todo" {
+		t.Fatalf("expected default workflow to start in todo, got %q", task.State)
+	}
+	if !hasError(core.SetWorkflow(`{"states":[{"id":"todo","name":"To do","category":"open"}]}`)) {
+		t.Fatalf("expected workflow without a done state to be rejected")
+	}
+	if errStr := core.SetWorkflow(reviewWorkflowJSON); errStr != "" {
+		t.Fatalf("set workflow: %s", errStr)
+	}
+
+	if !hasError(core.TransitionTask(task.ID, "shipped")) {
+		t.Fatalf("expected todo -> shipped to be rejected")
+	}
+	for _, state := range []string{"doing", "review"} {
+		if errStr := core.TransitionTask(task.ID, state); errStr != "" {
+			t.Fatalf("transition to %s: %s", state, errStr)
+		}
+	}
+	tasks := decodeTasks(t, core.ListTasks(`{"state":"review"}`))
+	if len(tasks) != 1 || tasks[0].Status != "active" || tasks[0].CompletedAt != "" {
+		t.Fatalf("expected open task in review, got %+v", tasks)
+	}
+	if errStr := core.TransitionTask(task.ID, "shipped"); errStr != "" {
+		t.Fatalf("transition to shipped: %s", errStr)
+	}
+	tasks = decodeTasks(t, core.ListTasks(""))
+	if tasks[0].Status != "done" || tasks[0].CompletedAt == "" || tasks[0].State != "shipped" {
+		t.Fatalf("expected done category to complete the task, got %+v", tasks[0])
+	}
+
+	if !hasError(core.SetCompleted(task.ID, false)) {
+		t.Fatalf("expected reopening shipped -> todo to be rejected")
+	}
+	reopenable := strings.Replace(reviewWorkflowJSON, `{"from": "review", "to": "shipped"}`,
+		`{"from": "review", "to": "shipped"}, {"from": "shipped", "to": "todo"}`, 1)
+	if errStr := core.SetWorkflow(reopenable); errStr != "" {
+		t.Fatalf("set workflow: %s", errStr)
+	}
+	if errStr := core.SetCompleted(task.ID, false); errStr != "" {
+		t.Fatalf("reopen: %s", errStr)
+	}
+	tasks = decodeTasks(t, core.ListTasks(""))
+	if tasks[0].State != "todo" {
+		t.Fatalf("expected reopened task back in the first open state, got %q", tasks[0].State)
+	}
+}
+
+func TestCompletionShortcutsFollowWorkflow(t *testing.T) {
+	core := newTestCore(t)
+	if errStr := core.SetWorkflow(reviewWorkflowJSON); errStr != "" {
+		t.Fatalf("set workflow: %s", errStr)
+	}
+	task := createTask(t, core, TaskDTO{Title: "Ship release"})
+	if errStr := core.TransitionTask(task.ID, "doing"); errStr != "" {
+		t.Fatalf("transition to doing: %s", errStr)
+	}
+
+	if !hasError(core.SetCompleted(task.ID, true)) {
+		t.Fatalf("expected completing from doing to skip review and be rejected")
+	}
+	task, _ = taskByID(t, core, task.ID)
+	task.Status = "done"
+	task.CompletedAt = task.UpdatedAt
+	payload, _ := json.Marshal(task)
+	if out := core.UpdateTask(string(payload)); !hasError(out) {
+		t.Fatalf("expected status-only update to done to be rejected, got %s", out)
+	}
+	if got, _ := taskByID(t, core, task.ID); got.State != "doing" || got.Status != "active" {
+		t.Fatalf("expected task to stay in doing, got %+v", got)
+	}
+
+	if errStr := core.TransitionTask(task.ID, "review"); errStr != "" {
+		t.Fatalf("transition to review: %s", errStr)
+	}
+	if errStr := core.SetCompleted(task.ID, true); errStr != "" {
+		t.Fatalf("complete from review: %s", errStr)
+	}
+	if got, _ := taskByID(t, core, task.ID); got.State != "shipped" {
+		t.Fatalf("expected completion to land in shipped, got %q", got.State)
+	}
+}
+
+func TestWorkflowSyncAndMigration(t *testing.T) {
+	phone := newTestCore(t)
+	task := createTask(t, phone, TaskDTO{Title: "Legacy task"})
+
+	// Simulate a task stored before workflows existed.
+	stored, err := phone.store.GetTask(task.ID)
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	stored.State = ""
+	if err := phone.store.UpsertTask(stored); err != nil {
+		t.Fatalf("upsert task: %v", err)
+	}
+	if errStr := phone.UnlockKeys("passphrase"); errStr != "" {
+		t.Fatalf("unlock: %s", errStr)
+	}
+	if tasks := decodeTasks(t, phone.ListTasks("")); tasks[0].State != "todo" {
+		t.Fatalf("expected migrated state todo, got %q", tasks[0].State)
+	}
+
+	if errStr := phone.SetWorkflow(reviewWorkflowJSON); errStr != "" {
+		t.Fatalf("set workflow: %s", errStr)
+	}
+	if errStr := phone.TransitionTask(task.ID, "doing"); errStr != "" {
+		t.Fatalf("transition: %s", errStr)
+	}
+
+	laptop := newTestCore(t)
+	shareKeys(t, phone, laptop)
+	if errStr := laptop.ImportEvents(phone.ExportEvents(0)); errStr != "" {
+		t.Fatalf("import: %s", errStr)
+	}
+	var wf WorkflowDTO
+	if err := json.Unmarshal([]byte(laptop.GetWorkflow()), &wf); err != nil {
+		t.Fatalf("decode workflow: %v", err)
+	}
+	if len(wf.States) != 4 || len(wf.Transitions) != 3 {
+		t.Fatalf("expected synced workflow, got %+v", wf)
+	}
+	if tasks := decodeTasks(t, laptop.ListTasks("")); tasks[0].State != "doing" {
+		t.Fatalf("expected synced state doing, got %q", tasks[0].State)
+	}
+}
@@ -1,7 +1,11 @@
 package crypto
 
 import (
+	"crypto/hkdf"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 
 	"golang.org/x/crypto/chacha20poly1305"
@@ -84,6 +88,62 @@ func (m *Manager) Decrypt(ciphertext []byte) ([]byte, error) {
 	return plaintext, nil
 }
 
+// Subkey derives a purpose-bound key from the vault key with HKDF-SHA256, so
+// the vault key itself is never used outside XChaCha20-Poly1305.
+func (m *Manager) Subkey(purpose string, size int) ([]byte, error) {
+	if !m.IsUnlocked() {
+		return nil, fmt.Errorf("keys not unlocked")
+	}
+	key, err := hkdf.Key(sha256.New, m.key, nil, purpose, size)
+	if err != nil {
+		return nil, fmt.Errorf("derive subkey: %w", err)
+	}
+	return key, nil
+}
+
+// WrapKey seals the vault key under wrappingKey, to hand it to a device
+// that is being paired.
+func (m *Manager) WrapKey(wrappingKey []byte) ([]byte, error) {
+	if !m.IsUnlocked() {
+		return nil, fmt.Errorf("keys not unlocked")
+	}
+	wrapper := &Manager{key: wrappingKey}
+	if !wrapper.IsUnlocked() {
+		return nil, fmt.Errorf("invalid wrapping key")
+	}
+	return wrapper.Encrypt(m.key)
+}
+
+// UnwrapKey loads a vault key sealed by WrapKey.
+func (m *Manager) UnwrapKey(wrappingKey, wrapped []byte) error {
+	wrapper := &Manager{key: wrappingKey}
+	if !wrapper.IsUnlocked() {
+		return fmt.Errorf("invalid wrapping key")
+	}
+	key, err := wrapper.Decrypt(wrapped)
+	if err != nil {
+		return fmt.Errorf("unwrap key: %w", err)
+	}
+	if len(key) != chacha20poly1305.KeySize {
+		return fmt.Errorf("unwrap key: invalid key size")
+	}
+	m.key = key
+	return nil
+}
+
+// ContentHash returns a keyed HMAC-SHA256 of data, hex encoded. Unlike a
+// plain hash it reveals nothing about the content to holders of the vault's
+// ciphertext, yet stays stable across devices sharing the vault key.
+func (m *Manager) ContentHash(data []byte) (string, error) {
+	key, err := m.Subkey("taskpp content hash v1", sha256.Size)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
 // NewSalt returns a random 16-byte salt.
 func NewSalt() ([]byte, error) {
 	salt := make([]byte, 16)
@@ -25,3 +25,69 @@ func TestEncryptDecrypt(t *testing.T) {
 		t.Fatalf("expected %q, got %q", plaintext, out)
 	}
 }
+
+func TestContentHashIsKeyed(t *testing.T) {
+	salt, err := NewSalt()
+	if err != nil {
+		t.Fatalf("salt: %v", err)
+	}
+	first := NewManager()
+	second := NewManager()
+	other := NewManager()
+	for _, manager := range []*Manager{first, second} {
+		if err := manager.DeriveKey("passphrase", salt); err != nil {
+			t.Fatalf("derive: %v", err)
+		}
+	}
+	if err := other.DeriveKey("different", salt); err != nil {
+		t.Fatalf("derive: %v", err)
+	}
+
+	data := []byte("invoice.pdf contents")
+	a, err := first.ContentHash(data)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	b, _ := second.ContentHash(data)
+	c, _ := other.ContentHash(data)
+	if a != b {
+		t.Fatalf("expected equal hashes for the same key")
+	}
+	if a == c {
+		t.Fatalf("expected different hashes for different keys")
+	}
+	if _, err := NewManager().ContentHash(data); err == nil {
+		t.Fatalf("expected error when locked")
+	}
+}
+
+func TestWrapKeyRoundTrip(t *testing.T) {
+	salt, err := NewSalt()
+	if err != nil {
+		t.Fatalf("salt: %v", err)
+	}
+	source := NewManager()
+	if err := source.DeriveKey("passphrase", salt); err != nil {
+		t.Fatalf("derive: %v", err)
+	}
+	wrappingKey := make([]byte, 32)
+	wrapped, err := source.WrapKey(wrappingKey)
+	if err != nil {
+		t.Fatalf("wrap: %v", err)
+	}
+	target := NewManager()
+	if err := target.UnwrapKey(make([]byte, 31), wrapped); err == nil {
+		t.Fatalf("expected a short wrapping key to fail")
+	}
+	wrongKey := append([]byte{1}, wrappingKey[1:]...)
+	if err := target.UnwrapKey(wrongKey, wrapped); err == nil || target.IsUnlocked() {
+		t.Fatalf("expected the wrong wrapping key to fail")
+	}
+	if err := target.UnwrapKey(wrappingKey, wrapped); err != nil {
+		t.Fatalf("unwrap: %v", err)
+	}
+	ciphertext, _ := source.Encrypt([]byte("shared"))
+	if out, err := target.Decrypt(ciphertext); err != nil || string(out) != "shared" {
+		t.Fatalf("expected the unwrapped key to decrypt, got %q %v", out, err)
+	}
+}
@@ -0,0 +1,130 @@
+package crypto
+
+import (
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// SPAKE2 over the 2048-bit MODP group of RFC 3526 (group 14). The generator
+// 2 spans the subgroup of prime order q = (p-1)/2; M and N are squares of
+// hashed labels, so nobody knows their discrete logarithms.
+var (
+	pakeP, _ = new(big.Int).SetString(
+		"FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD1"+
+			"29024E088A67CC74020BBEA63B139B22514A08798E3404DD"+
+			"EF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245"+
+			"E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7ED"+
+			"EE386BFB5A899FA5AE9F24117C4B1FE649286651ECE45B3D"+
+			"C2007CB8A163BF0598DA48361C55D39A69163FA8FD24CF5F"+
+			"83655D23DCA3AD961C62F356208552BB9ED529077096966D"+
+			"670C354E4ABC9804F1746C08CA18217C32905E462E36CE3B"+
+			"E39E772C180E86039B2783A2EC07A28FB5C55DF06F4C52C9"+
+			"DE2BCBF6955817183995497CEA956AE515D2261898FA0510"+
+			"15728E5A8AACAA68FFFFFFFFFFFFFFFF", 16)
+	pakeQ = new(big.Int).Rsh(pakeP, 1)
+	pakeG = big.NewInt(2)
+	pakeM = hashToGroup("taskpp spake2 M")
+	pakeN = hashToGroup("taskpp spake2 N")
+)
+
+const pakeElementSize = 256
+
+// PAKE is one side of a SPAKE2 exchange. Both sides derive the same key
+// only if they started from the same code, and an eavesdropper or a peer
+// with a wrong code learns nothing that allows testing other codes offline.
+type PAKE struct {
+	initiator bool
+	w         *big.Int
+	x         *big.Int
+	message   []byte
+}
+
+// NewPAKE starts an exchange for code. Exactly one side is the initiator.
+func NewPAKE(code string, initiator bool) (*PAKE, error) {
+	w, err := pakeScalar([]byte(code), "taskpp spake2 password")
+	if err != nil {
+		return nil, err
+	}
+	x, err := rand.Int(rand.Reader, new(big.Int).Sub(pakeQ, big.NewInt(1)))
+	if err != nil {
+		return nil, fmt.Errorf("pake scalar: %w", err)
+	}
+	x.Add(x, big.NewInt(1))
+	blind := pakeN
+	if initiator {
+		blind = pakeM
+	}
+	element := new(big.Int).Exp(pakeG, x, pakeP)
+	element.Mul(element, new(big.Int).Exp(blind, w, pakeP))
+	element.Mod(element, pakeP)
+	return &PAKE{initiator: initiator, w: w, x: x, message: encodeElement(element)}, nil
+}
+
+// Message returns the value to send to the peer.
+func (p *PAKE) Message() []byte {
+	return append([]byte(nil), p.message...)
+}
+
+// SharedKey returns the 32-byte key agreed with the peer that sent
+// peerMessage. A mismatched code yields a different key, which the caller
+// detects by confirming the key before use.
+func (p *PAKE) SharedKey(peerMessage []byte) ([]byte, error) {
+	if len(peerMessage) != pakeElementSize {
+		return nil, fmt.Errorf("invalid pake message")
+	}
+	peer := new(big.Int).SetBytes(peerMessage)
+	one := big.NewInt(1)
+	if peer.Cmp(one) <= 0 || peer.Cmp(new(big.Int).Sub(pakeP, one)) >= 0 {
+		return nil, fmt.Errorf("invalid pake message")
+	}
+	if new(big.Int).Exp(peer, pakeQ, pakeP).Cmp(one) != 0 {
+		return nil, fmt.Errorf("invalid pake message")
+	}
+	blind := pakeM
+	if p.initiator {
+		blind = pakeN
+	}
+	// Remove the peer's blinding: blind^-w = blind^(q-w) in the subgroup.
+	unblind := new(big.Int).Exp(blind, new(big.Int).Sub(pakeQ, p.w), pakeP)
+	shared := new(big.Int).Mul(peer, unblind)
+	shared.Mod(shared, pakeP)
+	shared.Exp(shared, p.x, pakeP)
+
+	first, second := p.message, peerMessage
+	if !p.initiator {
+		first, second = peerMessage, p.message
+	}
+	hash := sha256.New()
+	for _, part := range [][]byte{first, second, encodeElement(shared), p.w.Bytes()} {
+		var size [4]byte
+		binary.BigEndian.PutUint32(size[:], uint32(len(part)))
+		hash.Write(size[:])
+		hash.Write(part)
+	}
+	return hash.Sum(nil), nil
+}
+
+func pakeScalar(secret []byte, purpose string) (*big.Int, error) {
+	wide, err := hkdf.Key(sha256.New, secret, nil, purpose, 64)
+	if err != nil {
+		return nil, fmt.Errorf("derive pake scalar: %w", err)
+	}
+	return new(big.Int).Mod(new(big.Int).SetBytes(wide), pakeQ), nil
+}
+
+func hashToGroup(label string) *big.Int {
+	wide, err := hkdf.Key(sha256.New, []byte(label), nil, "taskpp spake2 element", pakeElementSize+16)
+	if err != nil {
+		panic(err)
+	}
+	element := new(big.Int).Mod(new(big.Int).SetBytes(wide), pakeP)
+	return element.Exp(element, big.NewInt(2), pakeP)
+}
+
+func encodeElement(element *big.Int) []byte {
+	return element.FillBytes(make([]byte, pakeElementSize))
+}
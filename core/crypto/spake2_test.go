@@ -0,0 +1,56 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func exchange(t *testing.T, codeA, codeB string) ([]byte, []byte) {
+	t.Helper()
+	a, err := NewPAKE(codeA, true)
+	if err != nil {
+		t.Fatalf("new pake: %v", err)
+	}
+	b, err := NewPAKE(codeB, false)
+	if err != nil {
+		t.Fatalf("new pake: %v", err)
+	}
+	keyA, err := a.SharedKey(b.Message())
+	if err != nil {
+		t.Fatalf("shared key a: %v", err)
+	}
+	keyB, err := b.SharedKey(a.Message())
+	if err != nil {
+		t.Fatalf("shared key b: %v", err)
+	}
+	return keyA, keyB
+}
+
+func TestPAKEAgreesOnlyOnSameCode(t *testing.T) {
+	keyA, keyB := exchange(t, "12345678", "12345678")
+	if len(keyA) != 32 || !bytes.Equal(keyA, keyB) {
+		t.Fatalf("expected matching keys")
+	}
+	again, _ := exchange(t, "12345678", "12345678")
+	if bytes.Equal(keyA, again) {
+		t.Fatalf("expected a fresh key per exchange")
+	}
+	keyA, keyB = exchange(t, "12345678", "12345679")
+	if bytes.Equal(keyA, keyB) {
+		t.Fatalf("expected different codes to disagree")
+	}
+}
+
+func TestPAKERejectsInvalidMessages(t *testing.T) {
+	pake, err := NewPAKE("12345678", true)
+	if err != nil {
+		t.Fatalf("new pake: %v", err)
+	}
+	one := make([]byte, pakeElementSize)
+	one[len(one)-1] = 1
+	for _, message := range [][]byte{nil, []byte("short"), one, pakeP.FillBytes(make([]byte, pakeElementSize))} {
+		if _, err := pake.SharedKey(message); err == nil {
+			t.Fatalf("expected %x to be rejected", message)
+		}
+	}
+}
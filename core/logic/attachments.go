@@ -0,0 +1,54 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"taskpp/core/model"
+)
+
+// MaxAttachmentSize caps a single attachment, in bytes.
+const MaxAttachmentSize = 64 << 20
+
+// ValidateAttachment enforces basic attachment rules.
+func ValidateAttachment(name string, size int64) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("attachment name is required")
+	}
+	if size > MaxAttachmentSize {
+		return fmt.Errorf("attachment larger than %d bytes", MaxAttachmentSize)
+	}
+	return nil
+}
+
+// ReferencedBlobs returns the sorted, distinct blob hashes attached to tasks.
+func ReferencedBlobs(tasks []model.Task) []string {
+	seen := make(map[string]bool)
+	out := make([]string, 0)
+	for _, task := range tasks {
+		for _, attachment := range task.Attachments {
+			if attachment.Hash != "" && !seen[attachment.Hash] {
+				seen[attachment.Hash] = true
+				out = append(out, attachment.Hash)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// OrphanedBlobs returns the stored hashes no task refers to any more.
+func OrphanedBlobs(stored []string, tasks []model.Task) []string {
+	referenced := make(map[string]bool)
+	for _, hash := range ReferencedBlobs(tasks) {
+		referenced[hash] = true
+	}
+	out := make([]string, 0)
+	for _, hash := range stored {
+		if !referenced[hash] {
+			out = append(out, hash)
+		}
+	}
+	return out
+}
@@ -0,0 +1,33 @@
+package logic
+
+import (
+	"testing"
+
+	"taskpp/core/model"
+)
+
+func TestOrphanedBlobs(t *testing.T) {
+	tasks := []model.Task{
+		{ID: "t1", Attachments: []model.Attachment{{ID: "a1", Hash: "h1"}, {ID: "a2", Hash: "h2"}}},
+		{ID: "t2", Attachments: []model.Attachment{{ID: "a3", Hash: "h1"}}},
+	}
+	if refs := ReferencedBlobs(tasks); len(refs) != 2 || refs[0] != "h1" || refs[1] != "h2" {
+		t.Fatalf("expected deduped references, got %v", refs)
+	}
+	orphans := OrphanedBlobs([]string{"h1", "h3"}, tasks)
+	if len(orphans) != 1 || orphans[0] != "h3" {
+		t.Fatalf("expected h3 orphaned, got %v", orphans)
+	}
+}
+
+func TestValidateAttachment(t *testing.T) {
+	if err := ValidateAttachment("scan.pdf", 1024); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ValidateAttachment(" ", 1); err == nil {
+		t.Fatalf("expected error for empty name")
+	}
+	if err := ValidateAttachment("huge.bin", MaxAttachmentSize+1); err == nil {
+		t.Fatalf("expected error for oversized attachment")
+	}
+}
@@ -0,0 +1,149 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"taskpp/core/model"
+)
+
+// Board groupings.
+const (
+	BoardByStatus   = "status"
+	BoardByPriority = "priority"
+	BoardByProject  = "project"
+	BoardByTag      = "tag"
+	BoardByDue      = "due"
+)
+
+// Due buckets used as board columns.
+const (
+	DueOverdue  = "overdue"
+	DueToday    = "today"
+	DueThisWeek = "this_week"
+	DueLater    = "later"
+	DueNone     = "none"
+)
+
+// BoardColumn is one kanban column with its tasks in manual order.
+type BoardColumn struct {
+	Key   string
+	Tasks []model.Task
+}
+
+// FixedColumns returns the columns a grouping always shows, in board order.
+// Project and tag columns depend on stored data and are supplied by callers.
+func FixedColumns(groupBy string, wf model.Workflow) []string {
+	switch groupBy {
+	case BoardByStatus:
+		out := make([]string, 0, len(wf.States))
+		for _, state := range wf.States {
+			out = append(out, state.ID)
+		}
+		return out
+	case BoardByPriority:
+		return []string{"high", "med", "low"}
+	case BoardByDue:
+		return []string{DueOverdue, DueToday, DueThisWeek, DueLater, DueNone}
+	}
+	return nil
+}
+
+// DueBucket places a task's due day, as seen from loc, relative to today (a
+// UTC-midnight date, see Today). This week runs up to the coming Monday.
+func DueBucket(task model.Task, today time.Time, loc *time.Location) string {
+	due := LocalDueDate(task, loc)
+	if due == "" {
+		return DueNone
+	}
+	day := today.Format("2006-01-02")
+	switch {
+	case due < day:
+		return DueOverdue
+	case due == day:
+		return DueToday
+	case due < NextWeek(today).Format("2006-01-02"):
+		return DueThisWeek
+	}
+	return DueLater
+}
+
+// BoardKeys returns the columns a task sits in. A task with several tags
+// appears in each tag's column; untagged tasks and tasks without a project
+// use the empty key.
+func BoardKeys(task model.Task, groupBy string, wf model.Workflow, today time.Time, loc *time.Location) ([]string, error) {
+	switch groupBy {
+	case BoardByStatus:
+		return []string{StateOf(task, wf)}, nil
+	case BoardByPriority:
+		return []string{task.Priority}, nil
+	case BoardByProject:
+		return []string{task.ProjectID}, nil
+	case BoardByTag:
+		if len(task.Tags) == 0 {
+			return []string{""}, nil
+		}
+		return task.Tags, nil
+	case BoardByDue:
+		return []string{DueBucket(task, today, loc)}, nil
+	}
+	return nil, fmt.Errorf("invalid group_by: %s", groupBy)
+}
+
+// GroupBoard distributes tasks into columns, each sorted by rank. Every key
+// in columns is returned, even when empty; keys found only on tasks are
+// appended in sorted order.
+func GroupBoard(tasks []model.Task, groupBy string, columns []string, wf model.Workflow, today time.Time, loc *time.Location) ([]BoardColumn, error) {
+	byKey := make(map[string][]model.Task)
+	extra := make([]string, 0)
+	known := make(map[string]bool, len(columns))
+	for _, key := range columns {
+		known[key] = true
+	}
+	for _, task := range tasks {
+		keys, err := BoardKeys(task, groupBy, wf, today, loc)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if !known[key] {
+				known[key] = true
+				extra = append(extra, key)
+			}
+			byKey[key] = append(byKey[key], task)
+		}
+	}
+	sort.Strings(extra)
+	out := make([]BoardColumn, 0, len(columns)+len(extra))
+	for _, key := range append(append([]string{}, columns...), extra...) {
+		column := byKey[key]
+		SortSiblings(column)
+		out = append(out, BoardColumn{Key: key, Tasks: column})
+	}
+	return out, nil
+}
+
+// RankAt returns the rank for a task dropped at position in a sorted column
+// that no longer contains it. ok is false when the neighbours share a key or
+// the new key would grow too long, and the column needs rebalancing first.
+func RankAt(column []model.Task, position int) (string, bool) {
+	if position < 0 {
+		position = 0
+	}
+	if position > len(column) {
+		position = len(column)
+	}
+	var before, after string
+	if position > 0 {
+		before = TaskRank(column[position-1])
+	}
+	if position < len(column) {
+		after = TaskRank(column[position])
+	}
+	rank, ok := RankBetween(before, after)
+	if !ok || NeedsRebalance(rank) {
+		return "", false
+	}
+	return rank, true
+}
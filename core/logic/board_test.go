@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"taskpp/core/model"
+)
+
+func TestDueBucket(t *testing.T) {
+	today := date(2026, 3, 4) // Wednesday
+	cases := map[string]time.Time{
+		DueOverdue:  date(2026, 3, 3),
+		DueToday:    today,
+		DueThisWeek: date(2026, 3, 8),
+		DueLater:    date(2026, 3, 9),
+		DueNone:     {},
+	}
+	for want, due := range cases {
+		if got := DueBucket(model.Task{DueDate: due}, today, time.UTC); got != want {
+			t.Fatalf("due %v: expected %s, got %s", due, want, got)
+		}
+	}
+}
+
+func TestGroupBoardKeepsEmptyColumnsAndOrder(t *testing.T) {
+	tasks := []model.Task{
+		{ID: "a", Priority: "high", Order: 2},
+		{ID: "b", Priority: "high", Order: 1},
+		{ID: "c", Priority: "urgent"},
+		{ID: "d", Tags: []string{"home", "work"}},
+	}
+	columns, err := GroupBoard(tasks, BoardByPriority, FixedColumns(BoardByPriority, DefaultWorkflow()), DefaultWorkflow(), date(2026, 3, 4), time.UTC)
+	if err != nil {
+		t.Fatalf("group: %v", err)
+	}
+	if len(columns) != 5 || columns[0].Key != "high" || columns[2].Key != "low" || columns[3].Key != "" || columns[4].Key != "urgent" {
+		t.Fatalf("unexpected columns: %+v", columns)
+	}
+	if columns[0].Tasks[0].ID != "b" || len(columns[2].Tasks) != 0 {
+		t.Fatalf("expected manual order and empty low column, got %+v", columns)
+	}
+
+	byTag, _ := GroupBoard(tasks, BoardByTag, nil, DefaultWorkflow(), date(2026, 3, 4), time.UTC)
+	if len(byTag) != 3 || byTag[1].Key != "home" || byTag[2].Tasks[0].ID != "d" {
+		t.Fatalf("expected multi-tag task in each tag column, got %+v", byTag)
+	}
+	if _, err := GroupBoard(tasks, "colour", nil, DefaultWorkflow(), date(2026, 3, 4), time.UTC); err == nil {
+		t.Fatalf("expected invalid grouping to fail")
+	}
+}
+
+func TestRankAt(t *testing.T) {
+	column := []model.Task{{Rank: "a"}, {Rank: "b"}, {Rank: "b"}}
+	if rank, ok := RankAt(column, 1); !ok || rank <= "a" || rank >= "b" {
+		t.Fatalf("expected rank between a and b, got %q %v", rank, ok)
+	}
+	if rank, ok := RankAt(column, 0); !ok || rank >= "a" {
+		t.Fatalf("expected rank before first, got %q %v", rank, ok)
+	}
+	if rank, ok := RankAt(column, 9); !ok || rank <= "b" {
+		t.Fatalf("expected rank after last, got %q %v", rank, ok)
+	}
+	if _, ok := RankAt(column, 2); ok {
+		t.Fatalf("expected no room between duplicate keys")
+	}
+}
@@ -0,0 +1,214 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"taskpp/core/model"
+)
+
+// MaxCalendarDays caps the span of one calendar query.
+const MaxCalendarDays = 366
+
+// MaxExceptionYears caps how far ahead of the current instance an occurrence
+// can be moved.
+const MaxExceptionYears = 10
+
+// Occurrence places a task on a calendar day. Virtual occurrences are future
+// instances of a recurring series that have not been created yet; their Task
+// is a copy of the current instance moved to the occurrence.
+type Occurrence struct {
+	Task model.Task
+	// Day is the calendar day in the viewer's zone (UTC midnight).
+	Day time.Time
+	// SeriesDate is the day the recurrence rule gives the occurrence.
+	SeriesDate time.Time
+	Virtual    bool
+}
+
+// OccurrenceDate returns the day the recurrence rule gave a task instance.
+func OccurrenceDate(task model.Task) time.Time {
+	if !task.OccurrenceDate.IsZero() {
+		return task.OccurrenceDate
+	}
+	return task.DueDate
+}
+
+// ExceptionFor returns the exception recorded for a series day.
+func ExceptionFor(task model.Task, date time.Time) (model.RecurrenceException, bool) {
+	for _, exception := range task.Exceptions {
+		if exception.Date.Equal(date) {
+			return exception, true
+		}
+	}
+	return model.RecurrenceException{}, false
+}
+
+// PruneExceptions drops exceptions for series days on or before date.
+func PruneExceptions(exceptions []model.RecurrenceException, date time.Time) []model.RecurrenceException {
+	var out []model.RecurrenceException
+	for _, exception := range exceptions {
+		if exception.Date.After(date) {
+			out = append(out, exception)
+		}
+	}
+	return out
+}
+
+// SeriesDates returns the rule days after the task's own occurrence, up to
+// and including until. Series that repeat from completion have no
+// predictable future days.
+func SeriesDates(task model.Task, until time.Time) []time.Time {
+	return seriesDatesAfter(task, OccurrenceDate(task), until)
+}
+
+// seriesDatesAfter returns the rule days of the task's series in (after,
+// until].
+func seriesDatesAfter(task model.Task, after, until time.Time) []time.Time {
+	rule, err := ParseRecurrence(task.Recurrence)
+	if err != nil || rule == nil || rule.FromCompletion {
+		return nil
+	}
+	anchor := truncateDay(OccurrenceDate(task))
+	if anchor.IsZero() {
+		return nil
+	}
+	day := truncateDay(after)
+	if day.Before(anchor) {
+		day = anchor
+	}
+	var out []time.Time
+	for {
+		next, ok := rule.nextAfter(anchor, day)
+		if !ok || next.After(until) {
+			return out
+		}
+		out = append(out, next)
+		day = next
+	}
+}
+
+// IsSeriesDate reports whether date is a future rule day of the task's
+// series. It checks the rule directly, so dates far ahead cost nothing.
+func IsSeriesDate(task model.Task, date time.Time) bool {
+	rule, err := ParseRecurrence(task.Recurrence)
+	if err != nil || rule == nil || rule.FromCompletion {
+		return false
+	}
+	anchor := truncateDay(OccurrenceDate(task))
+	if anchor.IsZero() || !date.After(anchor) || (!rule.Until.IsZero() && date.After(rule.Until)) {
+		return false
+	}
+	return rule.matches(anchor, date)
+}
+
+// ValidateExceptions checks exceptions that are not in previous: each must
+// move a future rule day of the task's series, at most MaxExceptionYears
+// ahead, by no more than MaxCalendarDays.
+func ValidateExceptions(task model.Task, previous []model.RecurrenceException) error {
+	key := func(exception model.RecurrenceException) string {
+		return exception.Date.Format("2006-01-02") + ">" + exception.MoveTo.Format("2006-01-02") + " " + exception.DueTime
+	}
+	known := make(map[string]bool, len(previous))
+	for _, exception := range previous {
+		known[key(exception)] = true
+	}
+	horizon := truncateDay(OccurrenceDate(task)).AddDate(MaxExceptionYears, 0, 0)
+	for _, exception := range task.Exceptions {
+		if known[key(exception)] {
+			continue
+		}
+		if exception.Date.After(horizon) || !IsSeriesDate(task, exception.Date) {
+			return fmt.Errorf("no occurrence on %s", exception.Date.Format("2006-01-02"))
+		}
+		if exception.MoveTo.IsZero() || daysApart(exception.Date, exception.MoveTo) > MaxCalendarDays {
+			return fmt.Errorf("exception for %s moves too far", exception.Date.Format("2006-01-02"))
+		}
+	}
+	return nil
+}
+
+func daysApart(a, b time.Time) int {
+	days := int(b.Sub(a).Hours() / 24)
+	if days < 0 {
+		return -days
+	}
+	return days
+}
+
+// Occurrences lists the tasks falling on days in [start, end] (inclusive
+// UTC-midnight dates) as seen from loc. Open recurring tasks also yield
+// virtual occurrences for their future rule days, moved by any exceptions.
+// Rule days up to today and up to the current instance's due date are
+// skipped: completing the instance never creates them. Within a day, all-day
+// tasks come first in manual order, then timed tasks by time.
+func Occurrences(tasks []model.Task, start, end, today time.Time, loc *time.Location) []Occurrence {
+	if loc == nil {
+		loc = time.UTC
+	}
+	inRange := func(day string) (time.Time, bool) {
+		parsed, err := time.Parse("2006-01-02", day)
+		if err != nil || parsed.Before(start) || parsed.After(end) {
+			return time.Time{}, false
+		}
+		return parsed, true
+	}
+	out := make([]Occurrence, 0)
+	for _, task := range tasks {
+		if task.DueDate.IsZero() {
+			continue
+		}
+		if day, ok := inRange(LocalDueDate(task, loc)); ok {
+			out = append(out, Occurrence{Task: task, Day: day, SeriesDate: OccurrenceDate(task)})
+		}
+		if IsDone(task) || !IsRecurring(task.Recurrence) {
+			continue
+		}
+		after := truncateDay(today)
+		if due := truncateDay(task.DueDate); due.After(after) {
+			after = due
+		}
+		// Rule days are walked up to the range end only; later ones that an
+		// exception pulls into the range are checked against the rule.
+		seriesDates := seriesDatesAfter(task, after, end)
+		for _, exception := range task.Exceptions {
+			if exception.Date.After(end) && exception.Date.After(after) && IsSeriesDate(task, exception.Date) {
+				seriesDates = append(seriesDates, exception.Date)
+			}
+		}
+		for _, seriesDate := range seriesDates {
+			virtual := task
+			virtual.DueDate = seriesDate
+			virtual.OccurrenceDate = seriesDate
+			if exception, ok := ExceptionFor(task, seriesDate); ok {
+				virtual.DueDate = exception.MoveTo
+				virtual.DueTime = exception.DueTime
+				if exception.DueTime == "" {
+					virtual.TimeZone = ""
+				}
+			}
+			if day, ok := inRange(LocalDueDate(virtual, loc)); ok {
+				out = append(out, Occurrence{Task: virtual, Day: day, SeriesDate: seriesDate, Virtual: true})
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if !a.Day.Equal(b.Day) {
+			return a.Day.Before(b.Day)
+		}
+		if (a.Task.DueTime == "") != (b.Task.DueTime == "") {
+			return a.Task.DueTime == ""
+		}
+		if a.Task.DueTime != "" {
+			ai, _ := DueInstant(a.Task, loc)
+			bi, _ := DueInstant(b.Task, loc)
+			if !ai.Equal(bi) {
+				return ai.Before(bi)
+			}
+		}
+		return CompareRank(a.Task, b.Task) < 0
+	})
+	return out
+}
@@ -0,0 +1,111 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"taskpp/core/model"
+)
+
+func TestOccurrencesExpandsSeriesWithExceptions(t *testing.T) {
+	series := model.Task{
+		ID:         "s",
+		Status:     "active",
+		DueDate:    date(2026, 3, 2),
+		Recurrence: "RRULE:FREQ=WEEKLY",
+		Exceptions: []model.RecurrenceException{
+			{Date: date(2026, 3, 9), MoveTo: date(2026, 3, 11), DueTime: "09:00"},
+			{Date: date(2026, 3, 23), MoveTo: date(2026, 3, 13)},
+		},
+	}
+	single := model.Task{ID: "a", Status: "active", DueDate: date(2026, 3, 11), Order: 1}
+	got := Occurrences([]model.Task{series, single}, date(2026, 3, 1), date(2026, 3, 16), date(2026, 3, 1), time.UTC)
+	type placed struct {
+		id, day, series string
+		virtual         bool
+	}
+	want := []placed{
+		{"s", "2026-03-02", "2026-03-02", false},
+		{"a", "2026-03-11", "2026-03-11", false},
+		{"s", "2026-03-11", "2026-03-09", true},
+		{"s", "2026-03-13", "2026-03-23", true},
+		{"s", "2026-03-16", "2026-03-16", true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d occurrences, got %+v", len(want), got)
+	}
+	for i, occurrence := range got {
+		p := placed{occurrence.Task.ID, occurrence.Day.Format("2006-01-02"), occurrence.SeriesDate.Format("2006-01-02"), occurrence.Virtual}
+		if p != want[i] {
+			t.Fatalf("occurrence %d: expected %+v, got %+v", i, want[i], p)
+		}
+	}
+	if got[2].Task.DueTime != "09:00" {
+		t.Fatalf("expected exception time, got %q", got[2].Task.DueTime)
+	}
+}
+
+func TestOccurrencesSkipsDoneAndCompletionSeries(t *testing.T) {
+	tasks := []model.Task{
+		{ID: "done", Status: "done", DueDate: date(2026, 3, 2), Recurrence: "daily"},
+		{ID: "eod", Status: "active", DueDate: date(2026, 3, 2), Recurrence: "eod"},
+	}
+	got := Occurrences(tasks, date(2026, 3, 1), date(2026, 3, 7), date(2026, 3, 1), time.UTC)
+	if len(got) != 2 || got[0].Virtual || got[1].Virtual {
+		t.Fatalf("expected only real occurrences, got %+v", got)
+	}
+}
+
+func TestSeriesDatesCountsFromOccurrenceDate(t *testing.T) {
+	moved := model.Task{
+		DueDate:        date(2026, 3, 4),
+		OccurrenceDate: date(2026, 3, 2),
+		Recurrence:     "RRULE:FREQ=WEEKLY",
+	}
+	days := SeriesDates(moved, date(2026, 3, 16))
+	if len(days) != 2 || !days[0].Equal(date(2026, 3, 9)) || !days[1].Equal(date(2026, 3, 16)) {
+		t.Fatalf("unexpected series dates: %v", days)
+	}
+	if IsSeriesDate(moved, date(2026, 3, 11)) {
+		t.Fatalf("moved due date must not shift the series")
+	}
+}
+
+func TestOccurrencesSkipPastRuleDaysOfOverdueSeries(t *testing.T) {
+	overdue := model.Task{ID: "s", Status: "active", DueDate: date(2026, 3, 2), Recurrence: "daily"}
+	got := Occurrences([]model.Task{overdue}, date(2026, 3, 1), date(2026, 3, 7), date(2026, 3, 5), time.UTC)
+	if len(got) != 3 || got[0].Virtual || !got[1].Day.Equal(date(2026, 3, 6)) || !got[2].Day.Equal(date(2026, 3, 7)) {
+		t.Fatalf("expected the overdue instance, then days after today, got %+v", got)
+	}
+}
+
+func TestFarExceptionDoesNotWalkTheSeries(t *testing.T) {
+	series := model.Task{
+		ID:         "s",
+		Status:     "active",
+		DueDate:    date(2026, 3, 2),
+		Recurrence: "daily",
+		Exceptions: []model.RecurrenceException{
+			{Date: date(9999, 1, 1), MoveTo: date(2026, 3, 3)},
+		},
+	}
+	got := Occurrences([]model.Task{series}, date(2026, 3, 3), date(2026, 3, 3), date(2026, 3, 1), time.UTC)
+	if len(got) != 2 || !got[1].SeriesDate.Equal(date(9999, 1, 1)) {
+		t.Fatalf("expected the rule day and the pulled-in exception, got %+v", got)
+	}
+	if err := ValidateExceptions(series, nil); err == nil {
+		t.Fatalf("expected an exception years ahead to be rejected")
+	}
+	if err := ValidateExceptions(series, series.Exceptions); err != nil {
+		t.Fatalf("expected known exceptions to be kept: %v", err)
+	}
+	series.Exceptions = []model.RecurrenceException{{Date: date(2026, 3, 9), MoveTo: date(2026, 3, 10)}}
+	if err := ValidateExceptions(series, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	series.Recurrence = "RRULE:FREQ=WEEKLY"
+	series.Exceptions = []model.RecurrenceException{{Date: date(2026, 3, 10), MoveTo: date(2026, 3, 11)}}
+	if err := ValidateExceptions(series, nil); err == nil {
+		t.Fatalf("expected an off-rule exception to be rejected")
+	}
+}
@@ -0,0 +1,98 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"taskpp/core/model"
+)
+
+// ValidateChecklistText checks a checklist item's text.
+func ValidateChecklistText(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("checklist item text is required")
+	}
+	return nil
+}
+
+// SortChecklist orders items by Order, then ID for a stable result.
+func SortChecklist(items []model.ChecklistItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Order != items[j].Order {
+			return items[i].Order < items[j].Order
+		}
+		return items[i].ID < items[j].ID
+	})
+}
+
+// MergeChecklist combines the checklists of two copies of a task item by
+// item: the most recent text/checked edit and the most recent reorder of
+// each item win independently. A removal on either side wins over edits,
+// but not over an item added back after it (AddedAt later than the
+// removal). Returns the merged items, tombstones and removal times.
+func MergeChecklist(local, remote model.Task) ([]model.ChecklistItem, []string, map[string]time.Time) {
+	removed := make(map[string]time.Time, len(local.ChecklistRemoved)+len(remote.ChecklistRemoved))
+	for _, task := range []model.Task{local, remote} {
+		for _, id := range task.ChecklistRemoved {
+			at := task.ChecklistRemovedAt[id]
+			if seen, ok := removed[id]; !ok || at.After(seen) {
+				removed[id] = at
+			}
+		}
+	}
+	byID := make(map[string]model.ChecklistItem, len(local.Checklist)+len(remote.Checklist))
+	for _, item := range local.Checklist {
+		byID[item.ID] = item
+	}
+	for _, item := range remote.Checklist {
+		current, ok := byID[item.ID]
+		if !ok {
+			byID[item.ID] = item
+			continue
+		}
+		if item.UpdatedAt.After(current.UpdatedAt) {
+			current.Text, current.Checked, current.UpdatedAt = item.Text, item.Checked, item.UpdatedAt
+		}
+		if item.OrderedAt.After(current.OrderedAt) {
+			current.Order, current.OrderedAt = item.Order, item.OrderedAt
+		}
+		if item.AddedAt.After(current.AddedAt) {
+			current.AddedAt = item.AddedAt
+		}
+		byID[item.ID] = current
+	}
+	var merged []model.ChecklistItem
+	for id, item := range byID {
+		at, ok := removed[id]
+		if !ok {
+			merged = append(merged, item)
+		} else if item.AddedAt.After(at) {
+			merged = append(merged, item)
+			delete(removed, id)
+		}
+	}
+	SortChecklist(merged)
+	tombstones, removedAt := ChecklistTombstones(removed)
+	return merged, tombstones, removedAt
+}
+
+// ChecklistTombstones splits removal times by item id into the sorted ids
+// for Task.ChecklistRemoved and the known times for ChecklistRemovedAt.
+func ChecklistTombstones(removed map[string]time.Time) ([]string, map[string]time.Time) {
+	var ids []string
+	var removedAt map[string]time.Time
+	for id, at := range removed {
+		ids = append(ids, id)
+		if at.IsZero() {
+			continue
+		}
+		if removedAt == nil {
+			removedAt = make(map[string]time.Time)
+		}
+		removedAt[id] = at
+	}
+	sort.Strings(ids)
+	return ids, removedAt
+}
@@ -0,0 +1,53 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"taskpp/core/model"
+)
+
+func TestMergeChecklist(t *testing.T) {
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	local := []model.ChecklistItem{
+		{ID: "a", Text: "Socks", Checked: true, Order: 1, UpdatedAt: base.Add(time.Minute)},
+		{ID: "b", Text: "Charger", Order: 2, UpdatedAt: base},
+		{ID: "c", Text: "Hat", Order: 3, UpdatedAt: base},
+	}
+	remote := []model.ChecklistItem{
+		{ID: "a", Text: "Socks", Order: 4, UpdatedAt: base, OrderedAt: base.Add(2 * time.Minute)},
+		{ID: "b", Text: "Charger", Checked: true, Order: 2, UpdatedAt: base.Add(time.Minute)},
+		{ID: "d", Text: "Passport", Order: 0, UpdatedAt: base},
+	}
+	merged, removed, _ := MergeChecklist(model.Task{Checklist: local}, model.Task{Checklist: remote, ChecklistRemoved: []string{"c"}})
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 items, got %+v", merged)
+	}
+	if merged[0].ID != "d" || merged[1].ID != "b" || !merged[1].Checked {
+		t.Fatalf("expected per-item toggles to survive the merge, got %+v", merged)
+	}
+	if merged[2].ID != "a" || merged[2].Order != 4 || !merged[2].Checked {
+		t.Fatalf("expected remote reorder and local toggle to combine, got %+v", merged[2])
+	}
+	if len(removed) != 1 || removed[0] != "c" {
+		t.Fatalf("expected tombstone for c, got %v", removed)
+	}
+}
+
+func TestMergeChecklistItemAddedBackAfterRemoval(t *testing.T) {
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	removed := model.Task{
+		ChecklistRemoved:   []string{"a"},
+		ChecklistRemovedAt: map[string]time.Time{"a": base.Add(time.Minute)},
+	}
+	restored := model.Task{Checklist: []model.ChecklistItem{{ID: "a", Text: "Socks", AddedAt: base.Add(2 * time.Minute)}}}
+	merged, tombstones, _ := MergeChecklist(removed, restored)
+	if len(merged) != 1 || len(tombstones) != 0 {
+		t.Fatalf("expected the item added back to win, got %+v %v", merged, tombstones)
+	}
+	stale := model.Task{Checklist: []model.ChecklistItem{{ID: "a", Text: "Socks", AddedAt: base, UpdatedAt: base.Add(3 * time.Minute)}}}
+	merged, tombstones, removedAt := MergeChecklist(stale, removed)
+	if len(merged) != 0 || len(tombstones) != 1 || !removedAt["a"].Equal(base.Add(time.Minute)) {
+		t.Fatalf("expected a later edit not to undo the removal, got %+v %v %v", merged, tombstones, removedAt)
+	}
+}
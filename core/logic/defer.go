@@ -0,0 +1,25 @@
+package logic
+
+import (
+	"time"
+
+	"taskpp/core/model"
+)
+
+// IsDeferred reports whether an open task's start date is still after today
+// (a UTC-midnight date, see Today).
+func IsDeferred(task model.Task, today time.Time) bool {
+	if task.StartDate.IsZero() || IsDone(task) {
+		return false
+	}
+	return task.StartDate.After(today)
+}
+
+// NextWeek returns the Monday after today.
+func NextWeek(today time.Time) time.Time {
+	days := (8 - int(today.Weekday())) % 7
+	if days == 0 {
+		days = 7
+	}
+	return today.AddDate(0, 0, days)
+}
@@ -0,0 +1,30 @@
+package logic
+
+import (
+	"testing"
+
+	"taskpp/core/model"
+)
+
+func TestIsDeferred(t *testing.T) {
+	today := date(2026, 3, 4)
+	if !IsDeferred(model.Task{StartDate: date(2026, 3, 5)}, today) {
+		t.Fatalf("expected future start date to defer")
+	}
+	if IsDeferred(model.Task{StartDate: today}, today) {
+		t.Fatalf("expected task starting today to be visible")
+	}
+	if IsDeferred(model.Task{StartDate: date(2026, 3, 5), Status: "done"}, today) {
+		t.Fatalf("expected done task not to be deferred")
+	}
+}
+
+func TestNextWeek(t *testing.T) {
+	// 2026-03-04 is a Wednesday; 2026-03-09 a Monday.
+	if got := NextWeek(date(2026, 3, 4)); !got.Equal(date(2026, 3, 9)) {
+		t.Fatalf("expected following Monday, got %s", got)
+	}
+	if got := NextWeek(date(2026, 3, 9)); !got.Equal(date(2026, 3, 16)) {
+		t.Fatalf("expected a week later from Monday, got %s", got)
+	}
+}
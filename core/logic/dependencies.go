@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"fmt"
+
+	"taskpp/core/model"
+)
+
+// ValidateDependency checks that taskID may wait on blockerID. blockedBy maps
+// every known task id to the ids it currently waits on.
+func ValidateDependency(taskID, blockerID string, blockedBy map[string][]string) error {
+	if blockerID == "" {
+		return fmt.Errorf("missing blocker id")
+	}
+	if blockerID == taskID {
+		return fmt.Errorf("task cannot block itself")
+	}
+	if _, ok := blockedBy[blockerID]; !ok {
+		return fmt.Errorf("blocker not found: %s", blockerID)
+	}
+	seen := map[string]bool{}
+	stack := []string{blockerID}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == taskID {
+			return fmt.Errorf("dependency on %s would create a cycle", blockerID)
+		}
+		if seen[current] {
+			continue
+		}
+		seen[current] = true
+		stack = append(stack, blockedBy[current]...)
+	}
+	return nil
+}
+
+// IsBlocked reports whether any of the task's blockers is still open.
+// Blockers that no longer exist are ignored, so completing or deleting a
+// predecessor unblocks its dependents without touching them.
+func IsBlocked(task model.Task, lookup func(id string) (model.Task, bool)) bool {
+	for _, id := range task.BlockedBy {
+		blocker, ok := lookup(id)
+		if ok && !IsDone(blocker) {
+			return true
+		}
+	}
+	return false
+}
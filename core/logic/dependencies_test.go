@@ -0,0 +1,44 @@
+package logic
+
+import (
+	"testing"
+
+	"taskpp/core/model"
+)
+
+func TestValidateDependency(t *testing.T) {
+	blockedBy := map[string][]string{
+		"a": nil,
+		"b": {"a"},
+		"c": {"b"},
+	}
+	if err := ValidateDependency("d", "c", blockedBy); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ValidateDependency("a", "c", blockedBy); err == nil {
+		t.Fatalf("expected cycle error")
+	}
+	if err := ValidateDependency("a", "a", blockedBy); err == nil {
+		t.Fatalf("expected self-dependency error")
+	}
+	if err := ValidateDependency("a", "missing", blockedBy); err == nil {
+		t.Fatalf("expected missing blocker error")
+	}
+}
+
+func TestIsBlocked(t *testing.T) {
+	tasks := map[string]model.Task{
+		"open": {ID: "open", Status: "active"},
+		"done": {ID: "done", Status: "done"},
+	}
+	lookup := func(id string) (model.Task, bool) {
+		task, ok := tasks[id]
+		return task, ok
+	}
+	if !IsBlocked(model.Task{BlockedBy: []string{"done", "open"}}, lookup) {
+		t.Fatalf("expected open blocker to block")
+	}
+	if IsBlocked(model.Task{BlockedBy: []string{"done", "gone"}}, lookup) {
+		t.Fatalf("expected done and missing blockers not to block")
+	}
+}
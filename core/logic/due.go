@@ -0,0 +1,83 @@
+package logic
+
+import (
+	"fmt"
+	"time"
+
+	"taskpp/core/model"
+)
+
+// ValidateDue checks the optional due time ("HH:MM") and IANA time zone. A
+// time needs a date, and a zone needs a time: date-only tasks always float in
+// the viewer's zone.
+func ValidateDue(dueDate, dueTime, timeZone string) error {
+	if dueTime != "" {
+		if dueDate == "" {
+			return fmt.Errorf("due_time requires due_date")
+		}
+		if _, err := time.Parse("15:04", dueTime); err != nil {
+			return fmt.Errorf("invalid due_time: %s", dueTime)
+		}
+	}
+	if timeZone != "" {
+		if dueTime == "" {
+			return fmt.Errorf("time_zone requires due_time")
+		}
+		if _, err := time.LoadLocation(timeZone); err != nil {
+			return fmt.Errorf("invalid time_zone: %s", timeZone)
+		}
+	}
+	return nil
+}
+
+// DueInstant resolves a task's deadline to an instant. Floating tasks (no
+// zone) are read in viewer; date-only tasks are due at the end of their day.
+func DueInstant(task model.Task, viewer *time.Location) (time.Time, bool) {
+	if task.DueDate.IsZero() {
+		return time.Time{}, false
+	}
+	if viewer == nil {
+		viewer = time.UTC
+	}
+	year, month, day := task.DueDate.UTC().Date()
+	if task.DueTime == "" {
+		return time.Date(year, month, day+1, 0, 0, 0, 0, viewer).Add(-time.Nanosecond), true
+	}
+	clock, err := time.Parse("15:04", task.DueTime)
+	if err != nil {
+		return time.Date(year, month, day+1, 0, 0, 0, 0, viewer).Add(-time.Nanosecond), true
+	}
+	loc := viewer
+	if task.TimeZone != "" {
+		if zone, err := time.LoadLocation(task.TimeZone); err == nil {
+			loc = zone
+		}
+	}
+	return time.Date(year, month, day, clock.Hour(), clock.Minute(), 0, 0, loc), true
+}
+
+// LocalDueDate returns the task's due day ("YYYY-MM-DD") as seen from viewer.
+// Only zoned, timed tasks can land on a different day than their stored date.
+func LocalDueDate(task model.Task, viewer *time.Location) string {
+	if task.DueDate.IsZero() {
+		return ""
+	}
+	if task.DueTime == "" || task.TimeZone == "" {
+		return task.DueDate.UTC().Format("2006-01-02")
+	}
+	if viewer == nil {
+		viewer = time.UTC
+	}
+	instant, _ := DueInstant(task, viewer)
+	return instant.In(viewer).Format("2006-01-02")
+}
+
+// Today returns the current calendar day in loc as a UTC-midnight date, the
+// representation used for Task.DueDate.
+func Today(now time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	year, month, day := now.In(loc).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
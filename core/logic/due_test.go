@@ -0,0 +1,60 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"taskpp/core/model"
+)
+
+func TestValidateDue(t *testing.T) {
+	if err := ValidateDue("2026-03-02", "09:30", "America/Los_Angeles"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ValidateDue("", "09:30", ""); err == nil {
+		t.Fatalf("expected error for time without date")
+	}
+	if err := ValidateDue("2026-03-02", "25:00", ""); err == nil {
+		t.Fatalf("expected error for invalid time")
+	}
+	if err := ValidateDue("2026-03-02", "", "Europe/Paris"); err == nil {
+		t.Fatalf("expected error for zone without time")
+	}
+	if err := ValidateDue("2026-03-02", "09:30", "Mars/Base"); err == nil {
+		t.Fatalf("expected error for unknown zone")
+	}
+}
+
+func TestLocalDueDateAcrossZones(t *testing.T) {
+	la, _ := time.LoadLocation("America/Los_Angeles")
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	due := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	floating := model.Task{DueDate: due}
+	if got := LocalDueDate(floating, tokyo); got != "2026-03-02" {
+		t.Fatalf("date-only tasks should not shift, got %s", got)
+	}
+
+	// 20:00 in Los Angeles is already the next day in Tokyo.
+	zoned := model.Task{DueDate: due, DueTime: "20:00", TimeZone: "America/Los_Angeles"}
+	if got := LocalDueDate(zoned, tokyo); got != "2026-03-03" {
+		t.Fatalf("expected zoned task to land on 2026-03-03 in Tokyo, got %s", got)
+	}
+	if got := LocalDueDate(zoned, la); got != "2026-03-02" {
+		t.Fatalf("expected zoned task to stay on 2026-03-02 in LA, got %s", got)
+	}
+
+	instant, ok := DueInstant(floating, la)
+	if !ok || instant.In(la).Hour() != 23 {
+		t.Fatalf("expected date-only task due at end of day, got %v", instant)
+	}
+}
+
+func TestToday(t *testing.T) {
+	la, _ := time.LoadLocation("America/Los_Angeles")
+	// 03:00 UTC on Mar 3 is still the evening of Mar 2 in Los Angeles.
+	now := time.Date(2026, 3, 3, 3, 0, 0, 0, time.UTC)
+	if got := Today(now, la); !got.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected today: %v", got)
+	}
+}
@@ -0,0 +1,149 @@
+package logic
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"taskpp/core/model"
+)
+
+// Link types.
+const (
+	LinkURL    = "url"
+	LinkTel    = "tel"
+	LinkMailto = "mailto"
+	LinkFile   = "file"
+)
+
+var (
+	urlPattern   = regexp.MustCompile(`https?://[^\s<>"]+`)
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	phonePattern = regexp.MustCompile(`\+?\(?\d[\d\s().\-]{5,}\d`)
+	datePattern  = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+// minPhoneDigits keeps dates and short numbers from reading as phone numbers.
+const minPhoneDigits = 7
+
+// ValidateLink checks a link's type and value.
+func ValidateLink(link model.Link) error {
+	value := strings.TrimSpace(link.Value)
+	if value == "" {
+		return fmt.Errorf("link value is required")
+	}
+	switch link.Type {
+	case LinkURL:
+		parsed, err := url.Parse(value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("invalid url: %s", value)
+		}
+	case LinkTel:
+		if phoneDigits(value) < minPhoneDigits || strings.Trim(value, "+0123456789 ().-") != "" {
+			return fmt.Errorf("invalid phone number: %s", value)
+		}
+	case LinkMailto:
+		address, err := mail.ParseAddress(value)
+		if err != nil || address.Address != value {
+			return fmt.Errorf("invalid email address: %s", value)
+		}
+	case LinkFile:
+		if strings.ContainsRune(value, 0) {
+			return fmt.Errorf("invalid file path")
+		}
+	default:
+		return fmt.Errorf("invalid link type: %s", link.Type)
+	}
+	return nil
+}
+
+// LinkHref returns the URI a platform opens to launch a link.
+func LinkHref(link model.Link) string {
+	value := strings.TrimSpace(link.Value)
+	switch link.Type {
+	case LinkTel:
+		var b strings.Builder
+		b.WriteString("tel:")
+		for i, r := range value {
+			if (r >= '0' && r <= '9') || (r == '+' && i == 0) {
+				b.WriteRune(r)
+			}
+		}
+		return b.String()
+	case LinkMailto:
+		return "mailto:" + value
+	case LinkFile:
+		return (&url.URL{Scheme: "file", Path: value}).String()
+	}
+	return value
+}
+
+// ExtractLinks finds URLs, email addresses and phone numbers in free text,
+// in order of appearance and without duplicates.
+func ExtractLinks(text string) []model.Link {
+	type found struct {
+		start int
+		link  model.Link
+	}
+	var matches []found
+	taken := make([]bool, len(text))
+	claim := func(loc []int) bool {
+		for i := loc[0]; i < loc[1]; i++ {
+			if taken[i] {
+				return false
+			}
+		}
+		for i := loc[0]; i < loc[1]; i++ {
+			taken[i] = true
+		}
+		return true
+	}
+	for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+		value := strings.TrimRight(text[loc[0]:loc[1]], ".,;:!?)]}'")
+		if claim([]int{loc[0], loc[0] + len(value)}) {
+			matches = append(matches, found{loc[0], model.Link{Type: LinkURL, Value: value}})
+		}
+	}
+	for _, loc := range emailPattern.FindAllStringIndex(text, -1) {
+		if claim(loc) {
+			matches = append(matches, found{loc[0], model.Link{Type: LinkMailto, Value: text[loc[0]:loc[1]]}})
+		}
+	}
+	for _, loc := range phonePattern.FindAllStringIndex(text, -1) {
+		value := strings.TrimSpace(text[loc[0]:loc[1]])
+		if phoneDigits(value) < minPhoneDigits || datePattern.MatchString(value) {
+			continue
+		}
+		if claim(loc) {
+			matches = append(matches, found{loc[0], model.Link{Type: LinkTel, Value: value}})
+		}
+	}
+	for i := 1; i < len(matches); i++ {
+		for j := i; j > 0 && matches[j].start < matches[j-1].start; j-- {
+			matches[j], matches[j-1] = matches[j-1], matches[j]
+		}
+	}
+	out := make([]model.Link, 0, len(matches))
+	seen := make(map[string]bool)
+	for _, match := range matches {
+		href := LinkHref(match.link)
+		if seen[href] {
+			continue
+		}
+		seen[href] = true
+		out = append(out, match.link)
+	}
+	return out
+}
+
+func phoneDigits(value string) int {
+	count := 0
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			count++
+		}
+	}
+	return count
+}
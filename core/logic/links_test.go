@@ -0,0 +1,52 @@
+package logic
+
+import (
+	"testing"
+
+	"taskpp/core/model"
+)
+
+func TestExtractLinks(t *testing.T) {
+	text := "Call +1 (555) 010-7788 or email ops@example.com, docs at https://example.com/guide. Due 2026-03-02."
+	links := ExtractLinks(text)
+	if len(links) != 3 {
+		t.Fatalf("expected 3 links, got %+v", links)
+	}
+	if links[0].Type != LinkTel || LinkHref(links[0]) != "tel:+15550107788" {
+		t.Fatalf("unexpected phone link: %+v", links[0])
+	}
+	if links[1].Type != LinkMailto || links[1].Value != "ops@example.com" {
+		t.Fatalf("unexpected email link: %+v", links[1])
+	}
+	if links[2].Type != LinkURL || links[2].Value != "https://example.com/guide" {
+		t.Fatalf("unexpected url link: %+v", links[2])
+	}
+}
+
+func TestValidateLink(t *testing.T) {
+	valid := []model.Link{
+		{Type: LinkURL, Value: "https://example.com"},
+		{Type: LinkTel, Value: "+44 20 7946 0018"},
+		{Type: LinkMailto, Value: "a@example.com"},
+		{Type: LinkFile, Value: "/home/me/scan.pdf"},
+	}
+	for _, link := range valid {
+		if err := ValidateLink(link); err != nil {
+			t.Fatalf("expected %+v valid: %v", link, err)
+		}
+	}
+	invalid := []model.Link{
+		{Type: LinkURL, Value: "javascript:alert(1)"},
+		{Type: LinkTel, Value: "call me"},
+		{Type: LinkMailto, Value: "not-an-email"},
+		{Type: "sms", Value: "123"},
+	}
+	for _, link := range invalid {
+		if err := ValidateLink(link); err == nil {
+			t.Fatalf("expected %+v invalid", link)
+		}
+	}
+	if href := LinkHref(model.Link{Type: LinkFile, Value: "/tmp/a b.pdf"}); href != "file:///tmp/a%20b.pdf" {
+		t.Fatalf("unexpected file href: %s", href)
+	}
+}
@@ -2,6 +2,7 @@ package logic
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -33,3 +34,53 @@ func ValidateTask(title, status, priority, dueDate string, completedAt time.Time
 	}
 	return nil
 }
+
+// MaxCommentLength caps a single comment, in bytes.
+const MaxCommentLength = 10000
+
+// ValidateComment enforces basic comment rules.
+func ValidateComment(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("comment text is required")
+	}
+	if len(text) > MaxCommentLength {
+		return fmt.Errorf("comment longer than %d bytes", MaxCommentLength)
+	}
+	return nil
+}
+
+// ValidateProject enforces basic project rules. Color is optional and must be
+// a #RRGGBB hex value when set.
+func ValidateProject(name, color string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("project name is required")
+	}
+	if color == "" {
+		return nil
+	}
+	if len(color) != 7 || color[0] != '#' {
+		return fmt.Errorf("invalid color: %s", color)
+	}
+	for _, r := range color[1:] {
+		switch {
+		case r >= '0' && r <= '9', r >= 'a' && r <= 'f', r >= 'A' && r <= 'F':
+		default:
+			return fmt.Errorf("invalid color: %s", color)
+		}
+	}
+	return nil
+}
+
+// MaxDeviceNameLength caps a device name, in bytes.
+const MaxDeviceNameLength = 100
+
+// ValidateDeviceName enforces basic device name rules.
+func ValidateDeviceName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("device name is required")
+	}
+	if len(name) > MaxDeviceNameLength {
+		return fmt.Errorf("device name longer than %d bytes", MaxDeviceNameLength)
+	}
+	return nil
+}
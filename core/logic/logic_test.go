@@ -34,3 +34,18 @@ func zeroTime() time.Time { return time.Time{} }
 func nonZeroTime() time.Time {
 	return time.Now().UTC()
 }
+
+func TestValidateProject(t *testing.T) {
+	if err := ValidateProject("Work", "#1a2B3c"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ValidateProject("  ", ""); err == nil {
+		t.Fatalf("expected error for empty name")
+	}
+	if err := ValidateProject("Work", "red"); err == nil {
+		t.Fatalf("expected error for invalid color")
+	}
+	if err := ValidateProject("Work", "#12345g"); err == nil {
+		t.Fatalf("expected error for invalid hex digit")
+	}
+}
@@ -0,0 +1,172 @@
+package logic
+
+import (
+	"fmt"
+	"strings"
+
+	"taskpp/core/model"
+)
+
+// Rank keys order tasks manually. They compare as plain strings over
+// rankDigits, so a key can always be generated between two others without
+// touching either neighbour. Keys never end in the zero digit, which keeps
+// a gap open below every key.
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// MaxRankLength is the key length past which a list is rebalanced. Repeated
+// inserts at one spot grow keys by about one digit per five moves.
+const MaxRankLength = 32
+
+// TaskRank returns the key a task sorts by. Tasks written by clients that
+// only know the integer Order get a fixed-width key derived from it, so they
+// keep their relative order among ranked tasks.
+func TaskRank(task model.Task) string {
+	if task.Rank != "" {
+		return task.Rank
+	}
+	return RankFromOrder(task.Order)
+}
+
+// RankFromOrder maps a legacy Order to a 16-digit key. Each hex nibble uses
+// the digits 1-g so the key never contains the zero digit.
+func RankFromOrder(order int64) string {
+	value := uint64(order) ^ (1 << 63)
+	var b strings.Builder
+	for shift := 60; shift >= 0; shift -= 4 {
+		b.WriteByte(rankDigits[(value>>uint(shift))&0xf+1])
+	}
+	return b.String()
+}
+
+// CompareRank orders two tasks by rank key. Equal keys compare as 0 so
+// callers can fall back to their own tie-breaker.
+func CompareRank(a, b model.Task) int {
+	return strings.Compare(TaskRank(a), TaskRank(b))
+}
+
+// ValidateRank checks a client-supplied key. Empty means "use Order".
+func ValidateRank(rank string) error {
+	if rank == "" {
+		return nil
+	}
+	if len(rank) > 2*MaxRankLength {
+		return fmt.Errorf("rank too long")
+	}
+	for i := 0; i < len(rank); i++ {
+		if strings.IndexByte(rankDigits, rank[i]) < 0 {
+			return fmt.Errorf("invalid rank: %s", rank)
+		}
+	}
+	if rank[len(rank)-1] == '0' {
+		return fmt.Errorf("rank must not end in 0: %s", rank)
+	}
+	return nil
+}
+
+// RankBetween returns a key strictly between before and after. An empty
+// before means the start of the list and an empty after its end. ok is
+// false when before does not sort below after (e.g. two devices gave
+// neighbours the same key), and the list needs rebalancing first.
+func RankBetween(before, after string) (string, bool) {
+	if after != "" && before >= after {
+		return "", false
+	}
+	return rankMidpoint(before, after), true
+}
+
+// RanksBetween returns n ascending keys strictly between before and after,
+// splitting the gap in halves so keys grow with log n. It is used to spread
+// a run of tasks that share one key without touching the rest of the list.
+func RanksBetween(before, after string, n int) ([]string, bool) {
+	if n <= 0 {
+		return nil, true
+	}
+	mid, ok := RankBetween(before, after)
+	if !ok {
+		return nil, false
+	}
+	left, ok := RanksBetween(before, mid, n/2)
+	if !ok {
+		return nil, false
+	}
+	right, ok := RanksBetween(mid, after, n-n/2-1)
+	if !ok {
+		return nil, false
+	}
+	return append(append(left, mid), right...), true
+}
+
+// rankMidpoint finds a key between a and b, where b == "" stands for the
+// end of the key space.
+func rankMidpoint(a, b string) string {
+	if b != "" {
+		// Keep the common prefix, treating missing digits of a as zero.
+		n := 0
+		for n < len(b) && rankDigit(a, n) == rankDigitValue(b[n]) {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + rankMidpoint(rest, b[n:])
+		}
+	}
+	lo := rankDigit(a, 0)
+	hi := len(rankDigits)
+	if b != "" {
+		hi = rankDigitValue(b[0])
+	}
+	if hi-lo > 1 {
+		return string(rankDigits[(lo+hi+1)/2])
+	}
+	// Adjacent digits: a one-digit prefix of b still sorts above a.
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(rankDigits[lo]) + rankMidpoint(rest, "")
+}
+
+func rankDigit(key string, i int) int {
+	if i >= len(key) {
+		return 0
+	}
+	return rankDigitValue(key[i])
+}
+
+func rankDigitValue(c byte) int {
+	return strings.IndexByte(rankDigits, c)
+}
+
+// RankSequence returns n evenly spaced keys in ascending order, used to
+// rebalance a list.
+func RankSequence(n int) []string {
+	width := 1
+	space := uint64(len(rankDigits))
+	for space < uint64(n+1)*uint64(len(rankDigits)) {
+		width++
+		space *= uint64(len(rankDigits))
+	}
+	step := space / uint64(n+1)
+	out := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		value := uint64(i) * step
+		digits := make([]byte, width)
+		for j := width - 1; j >= 0; j-- {
+			digits[j] = rankDigits[value%uint64(len(rankDigits))]
+			value /= uint64(len(rankDigits))
+		}
+		out = append(out, strings.TrimRight(string(digits), "0"))
+	}
+	return out
+}
+
+// NeedsRebalance reports whether a generated key has grown too long.
+func NeedsRebalance(rank string) bool {
+	return len(rank) > MaxRankLength
+}
@@ -0,0 +1,103 @@
+package logic
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"taskpp/core/model"
+)
+
+func TestRankFromOrderKeepsOrder(t *testing.T) {
+	orders := []int64{math.MinInt64, -5, -1, 0, 1, 2, 1024, math.MaxInt64}
+	for i := 1; i < len(orders); i++ {
+		a, b := RankFromOrder(orders[i-1]), RankFromOrder(orders[i])
+		if a >= b {
+			t.Fatalf("order %d -> %q should sort below %d -> %q", orders[i-1], a, orders[i], b)
+		}
+		if err := ValidateRank(b); err != nil {
+			t.Fatalf("legacy key %q invalid: %v", b, err)
+		}
+	}
+	if TaskRank(model.Task{Order: 3, Rank: "x"}) != "x" {
+		t.Fatalf("explicit rank must win over order")
+	}
+}
+
+func TestRankBetween(t *testing.T) {
+	cases := [][2]string{
+		{"", ""},
+		{"", "1"},
+		{"1", "2"},
+		{"1", "101"},
+		{"az", "b"},
+		{"y", ""},
+		{"zzz", ""},
+		{RankFromOrder(0), RankFromOrder(1)},
+	}
+	for _, c := range cases {
+		rank, ok := RankBetween(c[0], c[1])
+		if !ok || rank <= c[0] || (c[1] != "" && rank >= c[1]) {
+			t.Fatalf("between %q and %q: got %q %v", c[0], c[1], rank, ok)
+		}
+		if err := ValidateRank(rank); err != nil {
+			t.Fatalf("between %q and %q: %v", c[0], c[1], err)
+		}
+	}
+	if _, ok := RankBetween("b", "b"); ok {
+		t.Fatalf("expected equal neighbours to need a rebalance")
+	}
+}
+
+func TestRanksBetween(t *testing.T) {
+	before, after := RankFromOrder(0), RankFromOrder(1)
+	ranks, ok := RanksBetween(before, after, 50)
+	if !ok || len(ranks) != 50 || !sort.StringsAreSorted(ranks) {
+		t.Fatalf("expected 50 ascending keys, got %v", ranks)
+	}
+	for i, rank := range ranks {
+		if rank <= before || rank >= after || (i > 0 && rank == ranks[i-1]) || NeedsRebalance(rank) {
+			t.Fatalf("key %q out of place between %q and %q", rank, before, after)
+		}
+	}
+	if _, ok := RanksBetween("b", "b", 2); ok {
+		t.Fatalf("expected equal bounds to fail")
+	}
+}
+
+func TestRepeatedInsertsGrowSlowly(t *testing.T) {
+	before, after := "a", "b"
+	for i := 0; i < 100; i++ {
+		rank, ok := RankBetween(before, after)
+		if !ok {
+			t.Fatalf("insert %d failed between %q and %q", i, before, after)
+		}
+		after = rank
+	}
+	if len(after) > MaxRankLength || !NeedsRebalance(after+"11111111111111111111111111111111") {
+		t.Fatalf("unexpected key length %d", len(after))
+	}
+}
+
+func TestRankSequence(t *testing.T) {
+	ranks := RankSequence(500)
+	if len(ranks) != 500 || !sort.StringsAreSorted(ranks) {
+		t.Fatalf("expected 500 ascending keys")
+	}
+	for i, rank := range ranks {
+		if err := ValidateRank(rank); err != nil {
+			t.Fatalf("key %d: %v", i, err)
+		}
+		if i > 0 && ranks[i-1] == rank {
+			t.Fatalf("duplicate key %q", rank)
+		}
+	}
+}
+
+func TestValidateRank(t *testing.T) {
+	for _, bad := range []string{"A", "a0", "a-b"} {
+		if ValidateRank(bad) == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package logic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence values understood by the core. Anything else must be an
+// iCalendar RRULE (prefixed with "RRULE:") using the supported subset:
+// FREQ=DAILY|WEEKLY|MONTHLY|YEARLY, INTERVAL, BYDAY, BYMONTHDAY and UNTIL.
+const (
+	RecurrenceNone  = "none"
+	RecurrenceDaily = "daily"
+	// RecurrenceEOD repeats from the completion day: the next instance is
+	// due the day after the task is finished, whatever its old due date.
+	RecurrenceEOD = "eod"
+)
+
+// Rule is a parsed recurrence rule.
+type Rule struct {
+	Freq       string
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	Until      time.Time
+	// FromCompletion anchors the next instance on the completion day.
+	FromCompletion bool
+}
+
+// WeekdayNum is a BYDAY entry such as "MO" or "-1FR" (N == 0 means every).
+type WeekdayNum struct {
+	Day time.Weekday
+	N   int
+}
+
+// MaxInterval caps INTERVAL. nextAfter steps by whole intervals, so the cap
+// only keeps the dates of far instances within range.
+const MaxInterval = 1000
+
+// maxPeriods bounds how many matching periods (days, weeks, months or years
+// of the rule's frequency) nextAfter looks through. Every rule that can
+// match at all does so within a few of them (Feb 29 yearly takes four).
+const maxPeriods = 100
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// IsRecurring reports whether a recurrence value describes a series.
+func IsRecurring(recurrence string) bool {
+	return recurrence != "" && recurrence != RecurrenceNone
+}
+
+// ValidateRecurrence checks that a recurrence value can be parsed.
+func ValidateRecurrence(recurrence string) error {
+	_, err := ParseRecurrence(recurrence)
+	return err
+}
+
+// ParseRecurrence parses "daily", "eod" or an RRULE. It returns a nil rule for
+// non-recurring values.
+func ParseRecurrence(recurrence string) (*Rule, error) {
+	switch recurrence {
+	case "", RecurrenceNone:
+		return nil, nil
+	case RecurrenceDaily:
+		return &Rule{Freq: "DAILY", Interval: 1}, nil
+	case RecurrenceEOD:
+		return &Rule{Freq: "DAILY", Interval: 1, FromCompletion: true}, nil
+	}
+	body, ok := strings.CutPrefix(recurrence, "RRULE:")
+	if !ok {
+		return nil, fmt.Errorf("invalid recurrence: %s", recurrence)
+	}
+	rule := &Rule{Interval: 1}
+	for _, part := range strings.Split(body, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rrule part: %s", part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			switch strings.ToUpper(value) {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				rule.Freq = strings.ToUpper(value)
+			default:
+				return nil, fmt.Errorf("unsupported rrule freq: %s", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > MaxInterval {
+				return nil, fmt.Errorf("invalid rrule interval: %s", value)
+			}
+			rule.Interval = n
+		case "BYDAY":
+			for _, item := range strings.Split(value, ",") {
+				day, err := parseWeekdayNum(item)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			for _, item := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(item)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("invalid rrule bymonthday: %s", item)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = until
+		default:
+			return nil, fmt.Errorf("unsupported rrule part: %s", key)
+		}
+	}
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("rrule freq is required")
+	}
+	for _, day := range rule.ByDay {
+		if day.N != 0 && rule.Freq != "MONTHLY" {
+			return nil, fmt.Errorf("ordinal byday is only supported for monthly rules")
+		}
+	}
+	if len(rule.ByMonthDay) > 0 && rule.Freq != "MONTHLY" {
+		return nil, fmt.Errorf("bymonthday is only supported for monthly rules")
+	}
+	return rule, nil
+}
+
+// NextDue returns the due date of the instance that follows one due on due
+// and completed at completedAt. Dates are calendar days at UTC midnight. The
+// next date is always after both the current due date and the completion
+// day, so finishing late does not spawn instances that are already overdue.
+// ok is false when the series has ended.
+func NextDue(recurrence string, due, completedAt time.Time) (time.Time, bool, error) {
+	rule, err := ParseRecurrence(recurrence)
+	if err != nil || rule == nil {
+		return time.Time{}, false, err
+	}
+	completedDay := truncateDay(completedAt)
+	anchor := truncateDay(due)
+	if anchor.IsZero() || rule.FromCompletion {
+		anchor = completedDay
+	}
+	after := anchor
+	if completedDay.After(after) {
+		after = completedDay
+	}
+	next, ok := rule.nextAfter(anchor, after)
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	return next, true, nil
+}
+
+// nextAfter returns the first date matching the rule strictly after after,
+// counting intervals from anchor. It jumps straight to the periods the
+// interval selects and only looks at the days inside them.
+func (r *Rule) nextAfter(anchor, after time.Time) (time.Time, bool) {
+	first := after.AddDate(0, 0, 1)
+	base := r.periodStart(anchor)
+	k := r.periodsBetween(base, first)
+	if m := ((k % r.Interval) + r.Interval) % r.Interval; m != 0 {
+		k += r.Interval - m
+	}
+	for i := 0; i < maxPeriods; i, k = i+1, k+r.Interval {
+		start, end := r.period(base, k), r.period(base, k+1)
+		if start.Before(first) {
+			start = first
+		}
+		for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+			if !r.Until.IsZero() && day.After(r.Until) {
+				return time.Time{}, false
+			}
+			if r.matches(anchor, day) {
+				return day, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// periodStart returns the first day of the period of the rule's frequency
+// that contains day.
+func (r *Rule) periodStart(day time.Time) time.Time {
+	switch r.Freq {
+	case "WEEKLY":
+		return startOfWeek(day)
+	case "MONTHLY":
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case "YEARLY":
+		return time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
+// period returns the first day of the k-th period after the one starting
+// on base.
+func (r *Rule) period(base time.Time, k int) time.Time {
+	switch r.Freq {
+	case "WEEKLY":
+		return base.AddDate(0, 0, 7*k)
+	case "MONTHLY":
+		return base.AddDate(0, k, 0)
+	case "YEARLY":
+		return base.AddDate(k, 0, 0)
+	}
+	return base.AddDate(0, 0, k)
+}
+
+// periodsBetween counts the periods from the one starting on base to the
+// one containing day.
+func (r *Rule) periodsBetween(base, day time.Time) int {
+	switch r.Freq {
+	case "WEEKLY":
+		return daysBetween(base, startOfWeek(day)) / 7
+	case "MONTHLY":
+		return (day.Year()-base.Year())*12 + int(day.Month()-base.Month())
+	case "YEARLY":
+		return day.Year() - base.Year()
+	}
+	return daysBetween(base, day)
+}
+
+func (r *Rule) matches(anchor, day time.Time) bool {
+	switch r.Freq {
+	case "DAILY":
+		return daysBetween(anchor, day)%r.Interval == 0 && r.matchesWeekday(day)
+	case "WEEKLY":
+		weeks := daysBetween(startOfWeek(anchor), startOfWeek(day)) / 7
+		if weeks%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 {
+			return day.Weekday() == anchor.Weekday()
+		}
+		return r.matchesWeekday(day)
+	case "MONTHLY":
+		months := (day.Year()-anchor.Year())*12 + int(day.Month()-anchor.Month())
+		if months%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+			return day.Day() == anchor.Day()
+		}
+		if len(r.ByMonthDay) > 0 && !r.matchesMonthDay(day) {
+			return false
+		}
+		return len(r.ByDay) == 0 || r.matchesMonthWeekday(day)
+	case "YEARLY":
+		return (day.Year()-anchor.Year())%r.Interval == 0 &&
+			day.Month() == anchor.Month() && day.Day() == anchor.Day()
+	}
+	return false
+}
+
+func (r *Rule) matchesWeekday(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, entry := range r.ByDay {
+		if entry.Day == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesMonthDay(day time.Time) bool {
+	last := daysInMonth(day)
+	for _, n := range r.ByMonthDay {
+		if n > 0 && day.Day() == n {
+			return true
+		}
+		if n < 0 && day.Day() == last+n+1 {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesMonthWeekday(day time.Time) bool {
+	nth := (day.Day()-1)/7 + 1
+	nthFromEnd := -((daysInMonth(day)-day.Day())/7 + 1)
+	for _, entry := range r.ByDay {
+		if entry.Day != day.Weekday() {
+			continue
+		}
+		if entry.N == 0 || entry.N == nth || entry.N == nthFromEnd {
+			return true
+		}
+	}
+	return false
+}
+
+func parseWeekdayNum(input string) (WeekdayNum, error) {
+	input = strings.ToUpper(strings.TrimSpace(input))
+	if len(input) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid rrule byday: %s", input)
+	}
+	code := input[len(input)-2:]
+	day, ok := weekdayCodes[code]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid rrule byday: %s", input)
+	}
+	n := 0
+	if prefix := input[:len(input)-2]; prefix != "" {
+		parsed, err := strconv.Atoi(prefix)
+		if err != nil || parsed == 0 || parsed < -5 || parsed > 5 {
+			return WeekdayNum{}, fmt.Errorf("invalid rrule byday: %s", input)
+		}
+		n = parsed
+	}
+	return WeekdayNum{Day: day, N: n}, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return truncateDay(parsed), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid rrule until: %s", value)
+}
+
+func truncateDay(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func startOfWeek(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func daysBetween(a, b time.Time) int {
+	return int(b.Sub(a).Hours() / 24)
+}
+
+func daysInMonth(day time.Time) int {
+	return time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
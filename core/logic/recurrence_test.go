@@ -0,0 +1,78 @@
+package logic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextDueDailyAndEOD(t *testing.T) {
+	due := date(2026, 3, 2)
+	next, ok, err := NextDue(RecurrenceDaily, due, due.Add(10*time.Hour))
+	if err != nil || !ok || !next.Equal(date(2026, 3, 3)) {
+		t.Fatalf("daily: got %v %v %v", next, ok, err)
+	}
+	// Completing three days late skips the instances that are already overdue.
+	next, _, _ = NextDue(RecurrenceDaily, due, date(2026, 3, 5).Add(time.Hour))
+	if !next.Equal(date(2026, 3, 6)) {
+		t.Fatalf("daily late: got %v", next)
+	}
+	// eod ignores an early due date and repeats from the completion day.
+	next, _, _ = NextDue(RecurrenceEOD, date(2026, 3, 10), date(2026, 3, 2).Add(time.Hour))
+	if !next.Equal(date(2026, 3, 3)) {
+		t.Fatalf("eod: got %v", next)
+	}
+}
+
+func TestNextDueRRULE(t *testing.T) {
+	cases := []struct {
+		rule string
+		due  time.Time
+		want time.Time
+	}{
+		// Friday -> Monday for weekdays.
+		{"RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", date(2026, 3, 6), date(2026, 3, 9)},
+		{"RRULE:FREQ=WEEKLY;INTERVAL=2", date(2026, 3, 4), date(2026, 3, 18)},
+		{"RRULE:FREQ=MONTHLY;BYMONTHDAY=-1", date(2026, 1, 31), date(2026, 2, 28)},
+		{"RRULE:FREQ=MONTHLY;BYDAY=2TU", date(2026, 3, 10), date(2026, 4, 14)},
+		{"RRULE:FREQ=MONTHLY", date(2026, 1, 15), date(2026, 2, 15)},
+		{"RRULE:FREQ=YEARLY", date(2026, 7, 4), date(2027, 7, 4)},
+		{"RRULE:FREQ=YEARLY", date(2028, 2, 29), date(2032, 2, 29)},
+		// Large intervals jump straight to the next period.
+		{"RRULE:FREQ=YEARLY;INTERVAL=1000", date(2026, 7, 4), date(3026, 7, 4)},
+		{"RRULE:FREQ=MONTHLY;INTERVAL=1000;BYDAY=-1FR", date(2026, 3, 27), date(2109, 7, 26)},
+		{"RRULE:FREQ=WEEKLY;INTERVAL=3;BYDAY=MO,TH", date(2026, 3, 5), date(2026, 3, 23)},
+		{"RRULE:FREQ=DAILY;INTERVAL=1000", date(2026, 3, 2), date(2028, 11, 26)},
+	}
+	for _, tc := range cases {
+		next, ok, err := NextDue(tc.rule, tc.due, tc.due)
+		if err != nil || !ok || !next.Equal(tc.want) {
+			t.Fatalf("%s: expected %v, got %v %v %v", tc.rule, tc.want, next, ok, err)
+		}
+	}
+	_, ok, err := NextDue("RRULE:FREQ=DAILY;UNTIL=20260302", date(2026, 3, 2), date(2026, 3, 2))
+	if err != nil || ok {
+		t.Fatalf("expected series to end at until, got %v %v", ok, err)
+	}
+	// Every selected month is a February, so the rule never matches.
+	_, ok, err = NextDue("RRULE:FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=31", date(2026, 2, 10), date(2026, 2, 10))
+	if err != nil || ok {
+		t.Fatalf("expected a rule without matching days to end, got %v %v", ok, err)
+	}
+}
+
+func TestValidateRecurrence(t *testing.T) {
+	for _, valid := range []string{"", "none", "daily", "eod", "RRULE:FREQ=WEEKLY;BYDAY=SA,SU", "RRULE:FREQ=YEARLY;INTERVAL=1000"} {
+		if err := ValidateRecurrence(valid); err != nil {
+			t.Fatalf("%q: unexpected error %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"weekly", "RRULE:FREQ=HOURLY", "RRULE:FREQ=DAILY;COUNT=3", "RRULE:FREQ=WEEKLY;BYDAY=1MO", "RRULE:FREQ=DAILY;INTERVAL=1001"} {
+		if err := ValidateRecurrence(invalid); err == nil {
+			t.Fatalf("%q: expected error", invalid)
+		}
+	}
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
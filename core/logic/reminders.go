@@ -0,0 +1,100 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"taskpp/core/model"
+)
+
+// DefaultReminderClock anchors relative reminders on date-only tasks.
+const DefaultReminderClock = 9 * time.Hour
+
+// Firing is a reminder scheduled to go off at FireAt.
+type Firing struct {
+	TaskID     string
+	ReminderID string
+	Title      string
+	FireAt     time.Time
+}
+
+// ValidateReminder checks that a relative reminder has a due date to hang off.
+func ValidateReminder(task model.Task, reminder model.Reminder) error {
+	if reminder.At.IsZero() && task.DueDate.IsZero() {
+		return fmt.Errorf("relative reminder requires a due date")
+	}
+	if reminder.At.IsZero() && reminder.OffsetMinutes < 0 {
+		return fmt.Errorf("reminder offset must not be negative")
+	}
+	return nil
+}
+
+// ReminderFireTime returns when a reminder goes off. Snoozing overrides the
+// schedule; relative reminders count back from the due time, or from
+// DefaultReminderClock on the due day for date-only tasks.
+func ReminderFireTime(task model.Task, reminder model.Reminder, viewer *time.Location) (time.Time, bool) {
+	if !reminder.SnoozedUntil.IsZero() {
+		return reminder.SnoozedUntil, true
+	}
+	if !reminder.At.IsZero() {
+		return reminder.At, true
+	}
+	if task.DueDate.IsZero() {
+		return time.Time{}, false
+	}
+	base, _ := DueInstant(task, viewer)
+	if task.DueTime == "" {
+		if viewer == nil {
+			viewer = time.UTC
+		}
+		year, month, day := task.DueDate.UTC().Date()
+		base = time.Date(year, month, day, 0, 0, 0, 0, viewer).Add(DefaultReminderClock)
+	}
+	return base.Add(-time.Duration(reminder.OffsetMinutes) * time.Minute), true
+}
+
+// ScheduleReminders lists every unacknowledged reminder on open tasks,
+// earliest first.
+func ScheduleReminders(tasks []model.Task, viewer *time.Location) []Firing {
+	out := make([]Firing, 0)
+	for _, task := range tasks {
+		if IsDone(task) || task.Archived {
+			continue
+		}
+		for _, reminder := range task.Reminders {
+			if !reminder.AcknowledgedAt.IsZero() {
+				continue
+			}
+			fireAt, ok := ReminderFireTime(task, reminder, viewer)
+			if !ok {
+				continue
+			}
+			out = append(out, Firing{TaskID: task.ID, ReminderID: reminder.ID, Title: task.Title, FireAt: fireAt})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].FireAt.Before(out[j].FireAt) })
+	return out
+}
+
+// PendingReminders returns the reminders that should have fired by now.
+func PendingReminders(tasks []model.Task, now time.Time, viewer *time.Location) []Firing {
+	out := make([]Firing, 0)
+	for _, firing := range ScheduleReminders(tasks, viewer) {
+		if firing.FireAt.After(now) {
+			break
+		}
+		out = append(out, firing)
+	}
+	return out
+}
+
+// NextReminder returns the first reminder due after now.
+func NextReminder(tasks []model.Task, now time.Time, viewer *time.Location) (Firing, bool) {
+	for _, firing := range ScheduleReminders(tasks, viewer) {
+		if firing.FireAt.After(now) {
+			return firing, true
+		}
+	}
+	return Firing{}, false
+}
@@ -0,0 +1,64 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"taskpp/core/model"
+)
+
+func TestReminderSchedule(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	tasks := []model.Task{
+		{
+			ID:      "timed",
+			DueDate: date(2026, 3, 2),
+			DueTime: "12:30",
+			Reminders: []model.Reminder{
+				{ID: "r1", OffsetMinutes: 45},
+				{ID: "r2", OffsetMinutes: 15},
+			},
+		},
+		{
+			ID:        "all-day",
+			DueDate:   date(2026, 3, 3),
+			Reminders: []model.Reminder{{ID: "r3"}},
+		},
+		{
+			ID:        "absolute",
+			Reminders: []model.Reminder{{ID: "r4", At: now.Add(-time.Hour), AcknowledgedAt: now}},
+		},
+		{
+			ID:        "snoozed",
+			Reminders: []model.Reminder{{ID: "r5", At: now.Add(-time.Hour), SnoozedUntil: now.Add(time.Minute)}},
+		},
+		{
+			ID:        "done",
+			Status:    "done",
+			Reminders: []model.Reminder{{ID: "r6", At: now.Add(-time.Hour)}},
+		},
+	}
+
+	pending := PendingReminders(tasks, now, time.UTC)
+	if len(pending) != 1 || pending[0].ReminderID != "r1" {
+		t.Fatalf("expected only r1 pending, got %+v", pending)
+	}
+	next, ok := NextReminder(tasks, now, time.UTC)
+	if !ok || next.ReminderID != "r5" {
+		t.Fatalf("expected snoozed reminder next, got %+v", next)
+	}
+	schedule := ScheduleReminders(tasks, time.UTC)
+	last := schedule[len(schedule)-1]
+	if last.ReminderID != "r3" || !last.FireAt.Equal(time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected all-day reminder at 09:00 on due day, got %+v", last)
+	}
+}
+
+func TestValidateReminder(t *testing.T) {
+	if err := ValidateReminder(model.Task{}, model.Reminder{OffsetMinutes: 10}); err == nil {
+		t.Fatalf("expected error for relative reminder without due date")
+	}
+	if err := ValidateReminder(model.Task{}, model.Reminder{At: time.Now()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NormalizeTag canonicalizes a tag: trimmed, lowercase, without a leading '#',
+// with inner whitespace collapsed to single dashes.
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	tag = strings.TrimLeft(tag, "#")
+	tag = strings.ToLower(strings.Join(strings.Fields(tag), "-"))
+	if tag == "" {
+		return "", fmt.Errorf("tag is required")
+	}
+	if strings.ContainsAny(tag, ",") {
+		return "", fmt.Errorf("invalid tag: %s", tag)
+	}
+	return tag, nil
+}
+
+// NormalizeTags normalizes, de-duplicates and sorts a tag list.
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, raw := range tags {
+		tag, err := NormalizeTag(raw)
+		if err != nil {
+			return nil, err
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	sort.Strings(out)
+	return out, nil
+}
@@ -0,0 +1,25 @@
+package logic
+
+import "testing"
+
+func TestNormalizeTags(t *testing.T) {
+	got, err := NormalizeTags([]string{" #Home ", "errands", "home", "Deep  Work"})
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	want := []string{"deep-work", "errands", "home"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+	if _, err := NormalizeTag(" # "); err == nil {
+		t.Fatalf("expected error for empty tag")
+	}
+	if _, err := NormalizeTag("a,b"); err == nil {
+		t.Fatalf("expected error for comma in tag")
+	}
+}
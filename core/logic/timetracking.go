@@ -0,0 +1,138 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"taskpp/core/model"
+)
+
+// Time report groupings.
+const (
+	GroupByTask    = "task"
+	GroupByProject = "project"
+	GroupByTag     = "tag"
+)
+
+// TimeTotal is one row of a time report. Estimate sums the estimates of the
+// distinct tasks that had time logged in the range.
+type TimeTotal struct {
+	Key             string
+	Spent           time.Duration
+	EstimateMinutes int64
+	Entries         int
+}
+
+// ValidateEstimate rejects negative estimates; 0 means none.
+func ValidateEstimate(minutes int64) error {
+	if minutes < 0 {
+		return fmt.Errorf("estimate must not be negative")
+	}
+	return nil
+}
+
+// ValidateTimeEntry enforces basic time entry rules. A zero End is a running
+// timer.
+func ValidateTimeEntry(entry model.TimeEntry) error {
+	if entry.TaskID == "" {
+		return fmt.Errorf("task_id is required")
+	}
+	if entry.Start.IsZero() {
+		return fmt.Errorf("start is required")
+	}
+	if !entry.End.IsZero() && entry.End.Before(entry.Start) {
+		return fmt.Errorf("end before start")
+	}
+	if len(entry.Note) > MaxCommentLength {
+		return fmt.Errorf("note longer than %d bytes", MaxCommentLength)
+	}
+	return nil
+}
+
+// EntryDuration returns how much of an entry falls inside [from, to). A zero
+// bound is open, and a running entry counts up to now.
+func EntryDuration(entry model.TimeEntry, from, to, now time.Time) time.Duration {
+	start, end := entry.Start, entry.End
+	if end.IsZero() {
+		end = now
+	}
+	if !from.IsZero() && start.Before(from) {
+		start = from
+	}
+	if !to.IsZero() && end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// SummarizeTime totals time entries inside [from, to) per task, project or
+// tag, largest first. An entry on a task with several tags counts toward each
+// tag; untagged and unassigned time is grouped under the empty key.
+func SummarizeTime(entries []model.TimeEntry, tasks []model.Task, groupBy string, from, to, now time.Time) ([]TimeTotal, error) {
+	byID := make(map[string]model.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+	keysFor := func(task model.Task) []string {
+		switch groupBy {
+		case GroupByProject:
+			return []string{task.ProjectID}
+		case GroupByTag:
+			if len(task.Tags) == 0 {
+				return []string{""}
+			}
+			return task.Tags
+		}
+		return []string{task.ID}
+	}
+	switch groupBy {
+	case GroupByTask, GroupByProject, GroupByTag:
+	default:
+		return nil, fmt.Errorf("invalid group_by: %s", groupBy)
+	}
+
+	totals := make(map[string]*TimeTotal)
+	estimated := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Deleted {
+			continue
+		}
+		spent := EntryDuration(entry, from, to, now)
+		if spent == 0 {
+			continue
+		}
+		task, ok := byID[entry.TaskID]
+		if !ok {
+			task = model.Task{ID: entry.TaskID}
+		}
+		for _, key := range keysFor(task) {
+			total := totals[key]
+			if total == nil {
+				total = &TimeTotal{Key: key}
+				totals[key] = total
+			}
+			total.Spent += spent
+			total.Entries++
+			if !estimated[key+"\x00"+task.ID] {
+				estimated[key+"\x00"+task.ID] = true
+				total.EstimateMinutes += task.EstimateMinutes
+			}
+		}
+	}
+
+	out := make([]TimeTotal, 0, len(totals))
+	for _, total := range totals {
+		out = append(out, *total)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Spent != out[j].Spent {
+			return out[i].Spent > out[j].Spent
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out, nil
+}
@@ -0,0 +1,60 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"taskpp/core/model"
+)
+
+func TestEntryDurationClipsToRange(t *testing.T) {
+	start := time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)
+	entry := model.TimeEntry{TaskID: "t1", Start: start, End: start.Add(2 * time.Hour)}
+	from := date(2026, 3, 2)
+	if got := EntryDuration(entry, from, time.Time{}, time.Time{}); got != time.Hour {
+		t.Fatalf("expected 1h inside range, got %v", got)
+	}
+	running := model.TimeEntry{TaskID: "t1", Start: start}
+	if got := EntryDuration(running, time.Time{}, time.Time{}, start.Add(30*time.Minute)); got != 30*time.Minute {
+		t.Fatalf("expected running entry to count up to now, got %v", got)
+	}
+	if got := EntryDuration(entry, time.Time{}, start, time.Time{}); got != 0 {
+		t.Fatalf("expected no time before range end, got %v", got)
+	}
+}
+
+func TestSummarizeTimeGroups(t *testing.T) {
+	tasks := []model.Task{
+		{ID: "t1", ProjectID: "p1", Tags: []string{"deep", "client"}, EstimateMinutes: 60},
+		{ID: "t2", ProjectID: "p1", EstimateMinutes: 30},
+	}
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	entries := []model.TimeEntry{
+		{ID: "e1", TaskID: "t1", Start: start, End: start.Add(45 * time.Minute)},
+		{ID: "e2", TaskID: "t1", Start: start.Add(time.Hour), End: start.Add(90 * time.Minute)},
+		{ID: "e3", TaskID: "t2", Start: start, End: start.Add(20 * time.Minute)},
+		{ID: "e4", TaskID: "t2", Start: start, End: start.Add(time.Hour), Deleted: true},
+	}
+
+	byTask, err := SummarizeTime(entries, tasks, GroupByTask, time.Time{}, time.Time{}, start)
+	if err != nil {
+		t.Fatalf("summarize: %v", err)
+	}
+	if len(byTask) != 2 || byTask[0].Key != "t1" || byTask[0].Spent != 75*time.Minute || byTask[0].Entries != 2 || byTask[0].EstimateMinutes != 60 {
+		t.Fatalf("unexpected task totals: %+v", byTask)
+	}
+
+	byProject, _ := SummarizeTime(entries, tasks, GroupByProject, time.Time{}, time.Time{}, start)
+	if len(byProject) != 1 || byProject[0].Spent != 95*time.Minute || byProject[0].EstimateMinutes != 90 {
+		t.Fatalf("unexpected project totals: %+v", byProject)
+	}
+
+	byTag, _ := SummarizeTime(entries, tasks, GroupByTag, time.Time{}, time.Time{}, start)
+	if len(byTag) != 3 || byTag[0].Key != "client" || byTag[1].Key != "deep" || byTag[2].Key != "" {
+		t.Fatalf("unexpected tag totals: %+v", byTag)
+	}
+
+	if _, err := SummarizeTime(entries, tasks, "week", time.Time{}, time.Time{}, start); err == nil {
+		t.Fatalf("expected invalid grouping to fail")
+	}
+}
@@ -0,0 +1,112 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+
+	"taskpp/core/model"
+)
+
+// MaxTaskDepth is the maximum nesting depth of a task tree, counting the root.
+const MaxTaskDepth = 5
+
+// IsDone reports whether a task counts as completed.
+func IsDone(task model.Task) bool {
+	return task.Status == "done"
+}
+
+// ValidateParent checks that making parentID the parent of taskID keeps the
+// hierarchy acyclic and within MaxTaskDepth. parents maps every known task id
+// to its current parent id ("" for roots).
+func ValidateParent(taskID, parentID string, parents map[string]string) error {
+	if parentID == "" {
+		return nil
+	}
+	if parentID == taskID {
+		return fmt.Errorf("task cannot be its own parent")
+	}
+	if _, ok := parents[parentID]; !ok {
+		return fmt.Errorf("parent not found: %s", parentID)
+	}
+	depth := 1
+	seen := map[string]bool{}
+	for id := parentID; id != ""; id = parents[id] {
+		if id == taskID {
+			return fmt.Errorf("parent %s would create a cycle", parentID)
+		}
+		if seen[id] {
+			return fmt.Errorf("existing hierarchy contains a cycle at %s", id)
+		}
+		seen[id] = true
+		depth++
+	}
+	if total := depth + subtreeHeight(taskID, parents) - 1; total > MaxTaskDepth {
+		return fmt.Errorf("task hierarchy deeper than %d levels", MaxTaskDepth)
+	}
+	return nil
+}
+
+// Descendants returns the ids of all tasks below id, parents before children.
+func Descendants(tasks []model.Task, id string) []string {
+	children := childIndex(tasks)
+	out := make([]string, 0)
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range children[current] {
+			if seen[child] {
+				continue
+			}
+			seen[child] = true
+			out = append(out, child)
+			queue = append(queue, child)
+		}
+	}
+	return out
+}
+
+// SortSiblings orders tasks that share a parent by rank, then creation time.
+func SortSiblings(tasks []model.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if c := CompareRank(tasks[i], tasks[j]); c != 0 {
+			return c < 0
+		}
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
+}
+
+func subtreeHeight(id string, parents map[string]string) int {
+	children := make(map[string][]string)
+	for child, parent := range parents {
+		if parent != "" {
+			children[parent] = append(children[parent], child)
+		}
+	}
+	var height func(string, map[string]bool) int
+	height = func(node string, seen map[string]bool) int {
+		if seen[node] {
+			return 0
+		}
+		seen[node] = true
+		best := 0
+		for _, child := range children[node] {
+			if h := height(child, seen); h > best {
+				best = h
+			}
+		}
+		return best + 1
+	}
+	return height(id, map[string]bool{})
+}
+
+func childIndex(tasks []model.Task) map[string][]string {
+	children := make(map[string][]string)
+	for _, task := range tasks {
+		if task.ParentID != "" {
+			children[task.ParentID] = append(children[task.ParentID], task.ID)
+		}
+	}
+	return children
+}
@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"testing"
+
+	"taskpp/core/model"
+)
+
+func TestValidateParentRejectsCycles(t *testing.T) {
+	parents := map[string]string{"a": "", "b": "a", "c": "b"}
+	if err := ValidateParent("a", "c", parents); err == nil {
+		t.Fatalf("expected cycle error")
+	}
+	if err := ValidateParent("a", "a", parents); err == nil {
+		t.Fatalf("expected self-parent error")
+	}
+	if err := ValidateParent("c", "a", parents); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ValidateParent("c", "missing", parents); err == nil {
+		t.Fatalf("expected missing parent error")
+	}
+}
+
+func TestValidateParentDepthLimit(t *testing.T) {
+	parents := map[string]string{"l1": "", "l2": "l1", "l3": "l2", "l4": "l3", "l5": "l4", "x": "", "y": "x"}
+	if err := ValidateParent("x", "l5", parents); err == nil {
+		t.Fatalf("expected depth error for leaf under level 5")
+	}
+	if err := ValidateParent("x", "l3", parents); err != nil {
+		t.Fatalf("expected two-level subtree to fit under level 3: %v", err)
+	}
+	if err := ValidateParent("x", "l4", parents); err == nil {
+		t.Fatalf("expected depth error for two-level subtree under level 4")
+	}
+}
+
+func TestDescendants(t *testing.T) {
+	tasks := []model.Task{
+		{ID: "root"},
+		{ID: "child", ParentID: "root"},
+		{ID: "grandchild", ParentID: "child"},
+		{ID: "other"},
+	}
+	got := Descendants(tasks, "root")
+	if len(got) != 2 || got[0] != "child" || got[1] != "grandchild" {
+		t.Fatalf("unexpected descendants: %v", got)
+	}
+}
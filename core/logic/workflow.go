@@ -0,0 +1,129 @@
+package logic
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"taskpp/core/model"
+)
+
+// Workflow state categories. Only CategoryDone counts as completed.
+const (
+	CategoryOpen = "open"
+	CategoryDone = "done"
+)
+
+// DefaultWorkflow is used until the user configures one.
+func DefaultWorkflow() model.Workflow {
+	return model.Workflow{
+		States: []model.WorkflowState{
+			{ID: "todo", Name: "To do", Category: CategoryOpen},
+			{ID: "doing", Name: "Doing", Category: CategoryOpen},
+			{ID: "done", Name: "Done", Category: CategoryDone},
+		},
+	}
+}
+
+// ValidateWorkflow checks state ids are unique and well-formed, that there
+// is at least one open and one done state, and that transitions name known
+// states.
+func ValidateWorkflow(wf model.Workflow) error {
+	seen := make(map[string]bool, len(wf.States))
+	categories := make(map[string]bool, 2)
+	for _, state := range wf.States {
+		if state.ID == "" || strings.TrimSpace(state.ID) != state.ID || strings.ContainsAny(state.ID, " \t\n") {
+			return fmt.Errorf("invalid state id: %q", state.ID)
+		}
+		if seen[state.ID] {
+			return fmt.Errorf("duplicate state: %s", state.ID)
+		}
+		seen[state.ID] = true
+		if strings.TrimSpace(state.Name) == "" {
+			return fmt.Errorf("state %s needs a name", state.ID)
+		}
+		switch state.Category {
+		case CategoryOpen, CategoryDone:
+			categories[state.Category] = true
+		default:
+			return fmt.Errorf("invalid category for state %s: %s", state.ID, state.Category)
+		}
+	}
+	if !categories[CategoryOpen] || !categories[CategoryDone] {
+		return fmt.Errorf("workflow needs at least one open and one done state")
+	}
+	for _, transition := range wf.Transitions {
+		if !seen[transition.From] || !seen[transition.To] {
+			return fmt.Errorf("transition %s -> %s names an unknown state", transition.From, transition.To)
+		}
+	}
+	return nil
+}
+
+// FindState returns the workflow state with the given id.
+func FindState(wf model.Workflow, id string) (model.WorkflowState, bool) {
+	for _, state := range wf.States {
+		if state.ID == id {
+			return state, true
+		}
+	}
+	return model.WorkflowState{}, false
+}
+
+// DefaultState returns the first state of the open or done category.
+func DefaultState(wf model.Workflow, done bool) string {
+	category := CategoryOpen
+	if done {
+		category = CategoryDone
+	}
+	for _, state := range wf.States {
+		if state.Category == category {
+			return state.ID
+		}
+	}
+	return ""
+}
+
+// StateOf returns the task's workflow state. Tasks without a known state, or
+// whose state disagrees with their status (say, completed from a client that
+// only knows "done"), fall back to the first state of their category.
+func StateOf(task model.Task, wf model.Workflow) string {
+	if state, ok := FindState(wf, task.State); ok && (state.Category == CategoryDone) == IsDone(task) {
+		return state.ID
+	}
+	return DefaultState(wf, IsDone(task))
+}
+
+// CanTransition reports whether the workflow allows moving from one state to
+// another.
+func CanTransition(wf model.Workflow, from, to string) bool {
+	if from == to || from == "" || len(wf.Transitions) == 0 {
+		return true
+	}
+	for _, transition := range wf.Transitions {
+		if transition.From == from && transition.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyState moves a task into a workflow state, keeping Status and
+// CompletedAt in line with the state's category.
+func ApplyState(task *model.Task, wf model.Workflow, id string, now time.Time) error {
+	state, ok := FindState(wf, id)
+	if !ok {
+		return fmt.Errorf("unknown state: %s", id)
+	}
+	task.State = state.ID
+	if state.Category == CategoryDone {
+		task.Status = "done"
+		if task.CompletedAt.IsZero() {
+			task.CompletedAt = now
+		}
+		return nil
+	}
+	task.Status = "active"
+	task.CompletedAt = time.Time{}
+	return nil
+}
@@ -0,0 +1,102 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"taskpp/core/model"
+)
+
+func reviewWorkflow() model.Workflow {
+	return model.Workflow{
+		States: []model.WorkflowState{
+			{ID: "todo", Name: "To do", Category: CategoryOpen},
+			{ID: "doing", Name: "Doing", Category: CategoryOpen},
+			{ID: "review", Name: "Review", Category: CategoryOpen},
+			{ID: "shipped", Name: "Shipped", Category: CategoryDone},
+		},
+		Transitions: []model.WorkflowTransition{
+			{From: "todo", To: "doing"},
+			{From: "doing", To: "review"},
+			{From: "review", To: "doing"},
+			{From: "review", To: "shipped"},
+		},
+	}
+}
+
+func TestValidateWorkflow(t *testing.T) {
+	if err := ValidateWorkflow(reviewWorkflow()); err != nil {
+		t.Fatalf("expected valid workflow: %v", err)
+	}
+	if err := ValidateWorkflow(DefaultWorkflow()); err != nil {
+		t.Fatalf("expected default workflow to be valid: %v", err)
+	}
+	cases := []model.Workflow{
+		{States: []model.WorkflowState{{ID: "todo", Name: "To do", Category: CategoryOpen}}},
+		{States: []model.WorkflowState{
+			{ID: "todo", Name: "To do", Category: CategoryOpen},
+			{ID: "todo", Name: "Again", Category: CategoryDone},
+		}},
+		{States: []model.WorkflowState{
+			{ID: "in review", Name: "Review", Category: CategoryOpen},
+			{ID: "done", Name: "Done", Category: CategoryDone},
+		}},
+		{States: []model.WorkflowState{
+			{ID: "todo", Name: "To do", Category: "blocked"},
+			{ID: "done", Name: "Done", Category: CategoryDone},
+		}},
+		{
+			States:      DefaultWorkflow().States,
+			Transitions: []model.WorkflowTransition{{From: "todo", To: "review"}},
+		},
+	}
+	for i, wf := range cases {
+		if err := ValidateWorkflow(wf); err == nil {
+			t.Fatalf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestStateOfFallsBackByCategory(t *testing.T) {
+	wf := reviewWorkflow()
+	if got := StateOf(model.Task{Status: "active"}, wf); got != "todo" {
+		t.Fatalf("expected legacy open task in todo, got %s", got)
+	}
+	if got := StateOf(model.Task{Status: "done"}, wf); got != "shipped" {
+		t.Fatalf("expected legacy done task in shipped, got %s", got)
+	}
+	if got := StateOf(model.Task{Status: "done", State: "review"}, wf); got != "shipped" {
+		t.Fatalf("expected completed task to leave an open state, got %s", got)
+	}
+	if got := StateOf(model.Task{Status: "active", State: "review"}, wf); got != "review" {
+		t.Fatalf("expected known state kept, got %s", got)
+	}
+}
+
+func TestTransitionsAndApplyState(t *testing.T) {
+	wf := reviewWorkflow()
+	if !CanTransition(wf, "doing", "review") || CanTransition(wf, "todo", "shipped") {
+		t.Fatalf("unexpected transition rules")
+	}
+	if !CanTransition(DefaultWorkflow(), "todo", "done") {
+		t.Fatalf("expected free transitions when none are listed")
+	}
+
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	task := model.Task{Status: "active", State: "review"}
+	if err := ApplyState(&task, wf, "shipped", now); err != nil {
+		t.Fatalf("apply state: %v", err)
+	}
+	if task.Status != "done" || !task.CompletedAt.Equal(now) {
+		t.Fatalf("expected done category to complete the task, got %+v", task)
+	}
+	if err := ApplyState(&task, wf, "doing", now); err != nil {
+		t.Fatalf("apply state: %v", err)
+	}
+	if task.Status != "active" || !task.CompletedAt.IsZero() {
+		t.Fatalf("expected open category to reopen the task, got %+v", task)
+	}
+	if err := ApplyState(&task, wf, "archived", now); err == nil {
+		t.Fatalf("expected unknown state to fail")
+	}
+}
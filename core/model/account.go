@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Account is this device's login on the sync server. The token only works
+// for this device; an empty token means logged out.
+type Account struct {
+	ServerURL string
+	Username  string
+	UserID    string
+	Token     string
+	ExpiresAt time.Time
+}
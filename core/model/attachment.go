@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// Attachment is a file linked to a task. The bytes live in the blob store
+// under Hash, a keyed content hash, so identical files are stored once.
+type Attachment struct {
+	ID       string
+	Name     string
+	MimeType string
+	Size     int64
+	Hash     string
+	AddedAt  time.Time
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// ChecklistItem is a lightweight, checkable line inside a task. UpdatedAt
+// covers Text and Checked; OrderedAt covers Order, so a reorder on one device
+// does not undo a toggle on another. AddedAt is when the item was added or
+// last brought back; only a removal after it hides the item.
+type ChecklistItem struct {
+	ID        string
+	Text      string
+	Checked   bool
+	Order     int64
+	UpdatedAt time.Time
+	OrderedAt time.Time
+	AddedAt   time.Time
+}
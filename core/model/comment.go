@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// Comment is a note in a task's thread. Only the authoring device may edit
+// or delete it; deletion leaves a tombstone so late edits cannot revive it.
+type Comment struct {
+	ID        string
+	TaskID    string
+	DeviceID  string
+	Text      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Deleted   bool
+}
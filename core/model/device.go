@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Device is one entry of the synced device registry. LastSeen is local: it
+// is the newest event this device has received from it. Not bind-safe.
+type Device struct {
+	ID        string
+	Name      string
+	Platform  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	LastSeen  time.Time
+	// Acked is the newest cursor the device has acknowledged; compaction
+	// only prunes events every device has acked.
+	Acked   Cursor
+	Revoked bool
+	// RevokedSeq is the device's own seq at revocation; later events from
+	// it are ignored.
+	RevokedSeq int64
+}
@@ -1,8 +1,30 @@
 package model
 
+import "time"
+
 // TaskFilter is used for querying tasks.
 type TaskFilter struct {
 	Status   string
 	Archived *bool
 	DueDate  string
+	// State selects one workflow state.
+	State string
+	// DueFrom and DueTo bound the due day ("YYYY-MM-DD", inclusive).
+	DueFrom string
+	DueTo   string
+	// Location is the viewer's zone used to place timed tasks on a day and
+	// to sort them; nil means UTC.
+	Location *time.Location
+	// ProjectID limits results to one project; a pointer to "" selects
+	// tasks without a project.
+	ProjectID *string
+	// TagsAny matches tasks carrying at least one of the tags, TagsAll those
+	// carrying every tag and TagsNone those carrying none of them.
+	TagsAny  []string
+	TagsAll  []string
+	TagsNone []string
+	// HideDeferred drops open tasks whose start date is still in the future.
+	HideDeferred bool
+	// Blocked selects tasks with (true) or without (false) an open blocker.
+	Blocked *bool
 }
@@ -0,0 +1,9 @@
+package model
+
+// Link is an actionable reference on a task: a web page, phone number, email
+// address or local file.
+type Link struct {
+	Type  string
+	Label string
+	Value string
+}
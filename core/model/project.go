@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// Project groups tasks into a named list. Not bind-safe.
+type Project struct {
+	ID        string
+	Name      string
+	Color     string
+	Order     int64
+	Archived  bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
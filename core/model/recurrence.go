@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// RecurrenceException moves one future occurrence of a recurring series.
+// Date is the day the rule gives the occurrence; MoveTo and DueTime are
+// where it actually lands.
+type RecurrenceException struct {
+	Date    time.Time
+	MoveTo  time.Time
+	DueTime string
+}
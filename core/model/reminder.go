@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Reminder is an alarm attached to a task. It fires at At when set, or
+// OffsetMinutes before the task's due time otherwise.
+type Reminder struct {
+	ID             string
+	At             time.Time
+	OffsetMinutes  int64
+	AcknowledgedAt time.Time
+	SnoozedUntil   time.Time
+}
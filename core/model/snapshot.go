@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// Cursor maps a device id to the highest event seq seen from that device.
+type Cursor map[string]int64
+
+// Snapshot is the full synced state at Cursor, encrypted with the vault
+// key. Events it covers can be pruned from the log.
+type Snapshot struct {
+	Cursor    Cursor
+	CreatedAt time.Time
+	Payload   []byte
+}
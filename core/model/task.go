@@ -10,9 +10,45 @@ type Task struct {
 	Status      string
 	Priority    string
 	DueDate     time.Time
+	// State is the workflow state; Status stays the coarse active/done
+	// category that completion rules key off.
+	State string
+	// StartDate defers the task: it stays out of default listings until then.
+	StartDate time.Time
+	// DueTime is an optional "HH:MM" on DueDate, read in TimeZone when set
+	// and in the viewer's zone otherwise.
+	DueTime     string
+	TimeZone    string
 	Order       int64
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	CompletedAt time.Time
 	Archived    bool
+	ProjectID   string
+	ParentID    string
+	Tags        []string
+	Recurrence  string
+	SeriesID    string
+	Reminders   []Reminder
+	// OccurrenceDate is the day the recurrence rule gave this instance, kept
+	// when the instance is moved so the series does not drift; zero means
+	// DueDate.
+	OccurrenceDate time.Time
+	// Exceptions move future occurrences of the series off their rule days.
+	Exceptions []RecurrenceException
+	// BlockedBy lists the ids of tasks that must be done before this one.
+	BlockedBy []string
+	Checklist []ChecklistItem
+	// ChecklistRemoved keeps ids of deleted checklist items so a merge with
+	// an older copy does not bring them back. ChecklistRemovedAt holds when
+	// each was removed; ids removed before it existed have no time.
+	ChecklistRemoved   []string
+	ChecklistRemovedAt map[string]time.Time
+	Attachments        []Attachment
+	Links              []Link
+	// EstimateMinutes is the expected effort; 0 means no estimate.
+	EstimateMinutes int64
+	// Rank is the fractional manual-order key. Empty means it is derived
+	// from Order, which older clients still write.
+	Rank string
 }
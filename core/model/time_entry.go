@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// TimeEntry is a span of work logged against a task. A running timer has a
+// zero End; each device runs at most one timer at a time.
+type TimeEntry struct {
+	ID        string
+	TaskID    string
+	DeviceID  string
+	Start     time.Time
+	End       time.Time
+	Note      string
+	UpdatedAt time.Time
+	Deleted   bool
+}
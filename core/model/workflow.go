@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// WorkflowState is a user-defined task state such as "review". Category
+// ("open" or "done") ties it to the coarse Task.Status.
+type WorkflowState struct {
+	ID       string
+	Name     string
+	Category string
+}
+
+// WorkflowTransition allows moving a task from one state to another.
+type WorkflowTransition struct {
+	From string
+	To   string
+}
+
+// Workflow is the synced set of task states. With no transitions listed any
+// move between states is allowed.
+type Workflow struct {
+	States      []WorkflowState
+	Transitions []WorkflowTransition
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,152 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// BlobChunkSize is the plaintext size of each encrypted attachment chunk.
+const BlobChunkSize = 256 * 1024
+
+// HasBlob reports whether every chunk of a blob is stored locally.
+func (s *Store) HasBlob(hash string) (bool, error) {
+	if err := s.Open(); err != nil {
+		return false, err
+	}
+	var chunks, stored int64
+	row := s.db.QueryRow(`SELECT chunks FROM blobs WHERE hash = ?`, hash)
+	if err := row.Scan(&chunks); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("get blob: %w", err)
+	}
+	row = s.db.QueryRow(`SELECT COUNT(*) FROM blob_chunks WHERE hash = ?`, hash)
+	if err := row.Scan(&stored); err != nil {
+		return false, fmt.Errorf("count blob chunks: %w", err)
+	}
+	return stored == chunks, nil
+}
+
+// PutBlob splits data into chunks, encrypts each one and stores them under
+// hash. Storing a hash that is already present is a no-op.
+func (s *Store) PutBlob(hash string, data []byte) error {
+	if err := s.Open(); err != nil {
+		return err
+	}
+	if s.enc == nil || !s.enc.IsUnlocked() {
+		return fmt.Errorf("keys not unlocked")
+	}
+	if exists, err := s.HasBlob(hash); err != nil || exists {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin blob: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM blob_chunks WHERE hash = ?`, hash); err != nil {
+		return fmt.Errorf("reset blob chunks: %w", err)
+	}
+	index := 0
+	for offset := 0; offset < len(data) || index == 0; offset += BlobChunkSize {
+		end := offset + BlobChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		ciphertext, err := s.enc.Encrypt(data[offset:end])
+		if err != nil {
+			return fmt.Errorf("encrypt blob chunk: %w", err)
+		}
+		if _, err := tx.Exec(`INSERT INTO blob_chunks (hash, idx, ciphertext) VALUES (?, ?, ?)`, hash, index, ciphertext); err != nil {
+			return fmt.Errorf("insert blob chunk: %w", err)
+		}
+		index++
+	}
+	stmt := `INSERT INTO blobs (hash, size, chunks) VALUES (?, ?, ?)
+	ON CONFLICT(hash) DO UPDATE SET
+		size = excluded.size,
+		chunks = excluded.chunks`
+	if _, err := tx.Exec(stmt, hash, len(data), index); err != nil {
+		return fmt.Errorf("insert blob: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit blob: %w", err)
+	}
+	return nil
+}
+
+// GetBlob decrypts and reassembles a stored blob.
+func (s *Store) GetBlob(hash string) ([]byte, error) {
+	if err := s.Open(); err != nil {
+		return nil, err
+	}
+	if s.enc == nil || !s.enc.IsUnlocked() {
+		return nil, fmt.Errorf("keys not unlocked")
+	}
+	exists, err := s.HasBlob(hash)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("blob not found: %s", hash)
+	}
+	rows, err := s.db.Query(`SELECT ciphertext FROM blob_chunks WHERE hash = ? ORDER BY idx`, hash)
+	if err != nil {
+		return nil, fmt.Errorf("list blob chunks: %w", err)
+	}
+	defer rows.Close()
+	var out []byte
+	for rows.Next() {
+		var ciphertext []byte
+		if err := rows.Scan(&ciphertext); err != nil {
+			return nil, fmt.Errorf("blob chunk scan: %w", err)
+		}
+		plaintext, err := s.enc.Decrypt(ciphertext)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt blob chunk: %w", err)
+		}
+		out = append(out, plaintext...)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("blob chunk rows: %w", err)
+	}
+	return out, nil
+}
+
+// ListBlobs returns the hashes of all stored blobs.
+func (s *Store) ListBlobs() ([]string, error) {
+	if err := s.Open(); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(`SELECT hash FROM blobs ORDER BY hash`)
+	if err != nil {
+		return nil, fmt.Errorf("list blobs: %w", err)
+	}
+	defer rows.Close()
+	out := make([]string, 0)
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("list blobs scan: %w", err)
+		}
+		out = append(out, hash)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list blobs rows: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) DeleteBlob(hash string) error {
+	if err := s.Open(); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec(`DELETE FROM blob_chunks WHERE hash = ?`, hash); err != nil {
+		return fmt.Errorf("delete blob chunks: %w", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM blobs WHERE hash = ?`, hash); err != nil {
+		return fmt.Errorf("delete blob: %w", err)
+	}
+	return nil
+}
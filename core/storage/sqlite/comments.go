@@ -0,0 +1,116 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"taskpp/core/model"
+)
+
+// ListComments returns a task's comments, tombstones included, oldest first.
+func (s *Store) ListComments(taskID string) ([]model.Comment, error) {
+	if err := s.Open(); err != nil {
+		return nil, err
+	}
+	if s.enc == nil || !s.enc.IsUnlocked() {
+		return nil, fmt.Errorf("keys not unlocked")
+	}
+
+	rows, err := s.db.Query(`SELECT ciphertext FROM comments WHERE task_id = ?`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list comments: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]model.Comment, 0)
+	for rows.Next() {
+		var ciphertext []byte
+		if err := rows.Scan(&ciphertext); err != nil {
+			return nil, fmt.Errorf("list comments scan: %w", err)
+		}
+		comment, err := s.decryptComment(ciphertext)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list comments rows: %w", err)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+func (s *Store) GetComment(id string) (model.Comment, error) {
+	if err := s.Open(); err != nil {
+		return model.Comment{}, err
+	}
+	if s.enc == nil || !s.enc.IsUnlocked() {
+		return model.Comment{}, fmt.Errorf("keys not unlocked")
+	}
+
+	row := s.db.QueryRow(`SELECT ciphertext FROM comments WHERE id = ?`, id)
+	var ciphertext []byte
+	if err := row.Scan(&ciphertext); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Comment{}, nil
+		}
+		return model.Comment{}, fmt.Errorf("get comment: %w", err)
+	}
+	return s.decryptComment(ciphertext)
+}
+
+func (s *Store) UpsertComment(comment model.Comment) error {
+	if err := s.Open(); err != nil {
+		return err
+	}
+	if s.enc == nil || !s.enc.IsUnlocked() {
+		return fmt.Errorf("keys not unlocked")
+	}
+	payload, err := json.Marshal(comment)
+	if err != nil {
+		return fmt.Errorf("encode comment: %w", err)
+	}
+	ciphertext, err := s.enc.Encrypt(payload)
+	if err != nil {
+		return fmt.Errorf("encrypt comment: %w", err)
+	}
+	stmt := `INSERT INTO comments (id, task_id, ciphertext) VALUES (?, ?, ?)
+	ON CONFLICT(id) DO UPDATE SET
+		task_id = excluded.task_id,
+		ciphertext = excluded.ciphertext`
+	if _, err := s.db.Exec(stmt, comment.ID, comment.TaskID, ciphertext); err != nil {
+		return fmt.Errorf("upsert comment: %w", err)
+	}
+	return nil
+}
+
+// DeleteTaskComments drops every comment on a task.
+func (s *Store) DeleteTaskComments(taskID string) error {
+	if err := s.Open(); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec(`DELETE FROM comments WHERE task_id = ?`, taskID); err != nil {
+		return fmt.Errorf("delete comments: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) decryptComment(ciphertext []byte) (model.Comment, error) {
+	payload, err := s.enc.Decrypt(ciphertext)
+	if err != nil {
+		return model.Comment{}, fmt.Errorf("decrypt comment: %w", err)
+	}
+	var comment model.Comment
+	if err := json.Unmarshal(payload, &comment); err != nil {
+		return model.Comment{}, fmt.Errorf("decode comment: %w", err)
+	}
+	return comment, nil
+}
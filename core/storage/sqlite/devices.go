@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"taskpp/core/model"
+)
+
+func (s *Store) ListDevices() ([]model.Device, error) {
+	if err := s.Open(); err != nil {
+		return nil, err
+	}
+	if s.enc == nil || !s.enc.IsUnlocked() {
+		return nil, fmt.Errorf("keys not unlocked")
+	}
+
+	rows, err := s.db.Query(`SELECT id, ciphertext FROM devices ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("list devices: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]model.Device, 0)
+	for rows.Next() {
+		var id string
+		var ciphertext []byte
+		if err := rows.Scan(&id, &ciphertext); err != nil {
+			return nil, fmt.Errorf("list devices scan: %w", err)
+		}
+		device, err := s.decryptDevice(ciphertext)
+		if err != nil {
+			return nil, err
+		}
+		if device.ID == "" {
+			device.ID = id
+		}
+		out = append(out, device)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list devices rows: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) GetDevice(id string) (model.Device, error) {
+	if err := s.Open(); err != nil {
+		return model.Device{}, err
+	}
+	if s.enc == nil || !s.enc.IsUnlocked() {
+		return model.Device{}, fmt.Errorf("keys not unlocked")
+	}
+
+	row := s.db.QueryRow(`SELECT id, ciphertext FROM devices WHERE id = ?`, id)
+	var storedID string
+	var ciphertext []byte
+	if err := row.Scan(&storedID, &ciphertext); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Device{}, nil
+		}
+		return model.Device{}, fmt.Errorf("get device: %w", err)
+	}
+	device, err := s.decryptDevice(ciphertext)
+	if err != nil {
+		return model.Device{}, err
+	}
+	if device.ID == "" {
+		device.ID = storedID
+	}
+	return device, nil
+}
+
+func (s *Store) UpsertDevice(device model.Device) error {
+	if err := s.Open(); err != nil {
+		return err
+	}
+	if s.enc == nil || !s.enc.IsUnlocked() {
+		return fmt.Errorf("keys not unlocked")
+	}
+	payload, err := json.Marshal(device)
+	if err != nil {
+		return fmt.Errorf("encode device: %w", err)
+	}
+	ciphertext, err := s.enc.Encrypt(payload)
+	if err != nil {
+		return fmt.Errorf("encrypt device: %w", err)
+	}
+	stmt := `INSERT INTO devices (id, ciphertext) VALUES (?, ?)
+	ON CONFLICT(id) DO UPDATE SET
+		ciphertext = excluded.ciphertext`
+	if _, err := s.db.Exec(stmt, device.ID, ciphertext); err != nil {
+		return fmt.Errorf("upsert device: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) decryptDevice(ciphertext []byte) (model.Device, error) {
+	payload, err := s.enc.Decrypt(ciphertext)
+	if err != nil {
+		return model.Device{}, fmt.Errorf("decrypt device: %w", err)
+	}
+	var device model.Device
+	if err := json.Unmarshal(payload, &device); err != nil {
+		return model.Device{}, fmt.Errorf("decode device: %w", err)
+	}
+	return device, nil
+}
@@ -0,0 +1,125 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"taskpp/core/model"
+)
+
+func (s *Store) ListProjects() ([]model.Project, error) {
+	if err := s.Open(); err != nil {
+		return nil, err
+	}
+	if s.enc == nil || !s.enc.IsUnlocked() {
+		return nil, fmt.Errorf("keys not unlocked")
+	}
+
+	rows, err := s.db.Query(`SELECT id, ciphertext FROM projects`)
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]model.Project, 0)
+	for rows.Next() {
+		var id string
+		var ciphertext []byte
+		if err := rows.Scan(&id, &ciphertext); err != nil {
+			return nil, fmt.Errorf("list projects scan: %w", err)
+		}
+		project, err := s.decryptProject(ciphertext)
+		if err != nil {
+			return nil, err
+		}
+		if project.ID == "" {
+			project.ID = id
+		}
+		out = append(out, project)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list projects rows: %w", err)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Order != out[j].Order {
+			return out[i].Order < out[j].Order
+		}
+		return out[i].CreatedAt.Before(out[j].CreatedAt)
+	})
+	return out, nil
+}
+
+func (s *Store) GetProject(id string) (model.Project, error) {
+	if err := s.Open(); err != nil {
+		return model.Project{}, err
+	}
+	if s.enc == nil || !s.enc.IsUnlocked() {
+		return model.Project{}, fmt.Errorf("keys not unlocked")
+	}
+
+	row := s.db.QueryRow(`SELECT id, ciphertext FROM projects WHERE id = ?`, id)
+	var storedID string
+	var ciphertext []byte
+	if err := row.Scan(&storedID, &ciphertext); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Project{}, nil
+		}
+		return model.Project{}, fmt.Errorf("get project: %w", err)
+	}
+	project, err := s.decryptProject(ciphertext)
+	if err != nil {
+		return model.Project{}, err
+	}
+	if project.ID == "" {
+		project.ID = storedID
+	}
+	return project, nil
+}
+
+func (s *Store) UpsertProject(project model.Project) error {
+	if err := s.Open(); err != nil {
+		return err
+	}
+	if s.enc == nil || !s.enc.IsUnlocked() {
+		return fmt.Errorf("keys not unlocked")
+	}
+	payload, err := json.Marshal(project)
+	if err != nil {
+		return fmt.Errorf("encode project: %w", err)
+	}
+	ciphertext, err := s.enc.Encrypt(payload)
+	if err != nil {
+		return fmt.Errorf("encrypt project: %w", err)
+	}
+	stmt := `INSERT INTO projects (id, ciphertext) VALUES (?, ?)
+	ON CONFLICT(id) DO UPDATE SET
+		ciphertext = excluded.ciphertext`
+	if _, err := s.db.Exec(stmt, project.ID, ciphertext); err != nil {
+		return fmt.Errorf("upsert project: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) DeleteProject(id string) error {
+	if err := s.Open(); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec(`DELETE FROM projects WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete project: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) decryptProject(ciphertext []byte) (model.Project, error) {
+	payload, err := s.enc.Decrypt(ciphertext)
+	if err != nil {
+		return model.Project{}, fmt.Errorf("decrypt project: %w", err)
+	}
+	var project model.Project
+	if err := json.Unmarshal(payload, &project); err != nil {
+		return model.Project{}, fmt.Errorf("decode project: %w", err)
+	}
+	return project, nil
+}
@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"taskpp/core/model"
+)
+
+const (
+	workflowSettingKey = "workflow"
+	snapshotSettingKey = "snapshot"
+	accountSettingKey  = "account"
+)
+
+// GetWorkflow returns the stored workflow, or a zero Workflow when none has
+// been configured.
+func (s *Store) GetWorkflow() (model.Workflow, error) {
+	var workflow model.Workflow
+	ok, err := s.getSetting(workflowSettingKey, &workflow)
+	if err != nil || !ok {
+		return model.Workflow{}, err
+	}
+	return workflow, nil
+}
+
+func (s *Store) SaveWorkflow(workflow model.Workflow) error {
+	return s.saveSetting(workflowSettingKey, workflow)
+}
+
+// GetSnapshot returns the latest compaction snapshot, or a zero Snapshot.
+func (s *Store) GetSnapshot() (model.Snapshot, error) {
+	var snapshot model.Snapshot
+	if _, err := s.getSetting(snapshotSettingKey, &snapshot); err != nil {
+		return model.Snapshot{}, err
+	}
+	return snapshot, nil
+}
+
+func (s *Store) SaveSnapshot(snapshot model.Snapshot) error {
+	return s.saveSetting(snapshotSettingKey, snapshot)
+}
+
+// GetAccount returns the sync server login, or a zero Account.
+func (s *Store) GetAccount() (model.Account, error) {
+	var account model.Account
+	if _, err := s.getSetting(accountSettingKey, &account); err != nil {
+		return model.Account{}, err
+	}
+	return account, nil
+}
+
+func (s *Store) SaveAccount(account model.Account) error {
+	return s.saveSetting(accountSettingKey, account)
+}
+
+func (s *Store) getSetting(key string, out any) (bool, error) {
+	if err := s.Open(); err != nil {
+		return false, err
+	}
+	if s.enc == nil || !s.enc.IsUnlocked() {
+		return false, fmt.Errorf("keys not unlocked")
+	}
+	row := s.db.QueryRow(`SELECT ciphertext FROM settings WHERE key = ?`, key)
+	var ciphertext []byte
+	if err := row.Scan(&ciphertext); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("get setting %s: %w", key, err)
+	}
+	payload, err := s.enc.Decrypt(ciphertext)
+	if err != nil {
+		return false, fmt.Errorf("decrypt setting %s: %w", key, err)
+	}
+	if err := json.Unmarshal(payload, out); err != nil {
+		return false, fmt.Errorf("decode setting %s: %w", key, err)
+	}
+	return true, nil
+}
+
+func (s *Store) saveSetting(key string, value any) error {
+	if err := s.Open(); err != nil {
+		return err
+	}
+	if s.enc == nil || !s.enc.IsUnlocked() {
+		return fmt.Errorf("keys not unlocked")
+	}
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode setting %s: %w", key, err)
+	}
+	ciphertext, err := s.enc.Encrypt(payload)
+	if err != nil {
+		return fmt.Errorf("encrypt setting %s: %w", key, err)
+	}
+	stmt := `INSERT INTO settings (key, ciphertext) VALUES (?, ?)
+	ON CONFLICT(key) DO UPDATE SET ciphertext = excluded.ciphertext`
+	if _, err := s.db.Exec(stmt, key, ciphertext); err != nil {
+		return fmt.Errorf("save setting %s: %w", key, err)
+	}
+	return nil
+}
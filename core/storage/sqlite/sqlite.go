@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"taskpp/core/crypto"
+	"taskpp/core/logic"
 	"taskpp/core/model"
 
 	_ "modernc.org/sqlite"
@@ -96,6 +97,41 @@ func (s *Store) migrate(ctx context.Context) error {
 			detected_at TEXT NOT NULL,
 			resolution TEXT NOT NULL
 		);`,
+		`CREATE TABLE IF NOT EXISTS projects (
+			id TEXT PRIMARY KEY,
+			ciphertext BLOB NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS comments (
+			id TEXT PRIMARY KEY,
+			task_id TEXT NOT NULL,
+			ciphertext BLOB NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_comments_task ON comments(task_id);`,
+		`CREATE TABLE IF NOT EXISTS time_entries (
+			id TEXT PRIMARY KEY,
+			task_id TEXT NOT NULL,
+			ciphertext BLOB NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_time_entries_task ON time_entries(task_id);`,
+		`CREATE TABLE IF NOT EXISTS devices (
+			id TEXT PRIMARY KEY,
+			ciphertext BLOB NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS settings (
+			key TEXT PRIMARY KEY,
+			ciphertext BLOB NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS blobs (
+			hash TEXT PRIMARY KEY,
+			size INTEGER NOT NULL,
+			chunks INTEGER NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS blob_chunks (
+			hash TEXT NOT NULL,
+			idx INTEGER NOT NULL,
+			ciphertext BLOB NOT NULL,
+			PRIMARY KEY (hash, idx)
+		);`,
 	}
 
 	for _, stmt := range stmts {
@@ -123,7 +159,7 @@ func (s *Store) ListTasks(filter model.TaskFilter) ([]model.Task, error) {
 	}
 	defer rows.Close()
 
-	out := make([]model.Task, 0)
+	all := make([]model.Task, 0)
 	for rows.Next() {
 		var id string
 		var ciphertext []byte
@@ -141,15 +177,32 @@ func (s *Store) ListTasks(filter model.TaskFilter) ([]model.Task, error) {
 		if task.ID == "" {
 			task.ID = id
 		}
+		all = append(all, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list tasks rows: %w", err)
+	}
+	// Blocked state depends on other tasks, so it is checked once every
+	// task has been decoded.
+	byID := make(map[string]model.Task, len(all))
+	for _, task := range all {
+		byID[task.ID] = task
+	}
+	lookup := func(id string) (model.Task, bool) {
+		task, ok := byID[id]
+		return task, ok
+	}
+	out := make([]model.Task, 0, len(all))
+	for _, task := range all {
 		if !matchesFilter(task, filter) {
 			continue
 		}
+		if filter.Blocked != nil && logic.IsBlocked(task, lookup) != *filter.Blocked {
+			continue
+		}
 		out = append(out, task)
 	}
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("list tasks rows: %w", err)
-	}
-	sortTasks(out)
+	sortTasks(out, filter.Location)
 	return out, nil
 }
 
@@ -291,6 +344,37 @@ func (s *Store) HasEvent(id string) (bool, error) {
 	return true, nil
 }
 
+// DeleteEvents removes events from the log, used when compaction has
+// folded them into a snapshot.
+func (s *Store) DeleteEvents(ids []string) error {
+	if err := s.Open(); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("delete events begin: %w", err)
+	}
+	stmt, err := tx.Prepare(`DELETE FROM task_events WHERE id = ?`)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("delete events prepare: %w", err)
+	}
+	defer stmt.Close()
+	for _, id := range ids {
+		if _, err := stmt.Exec(id); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("delete events exec: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("delete events commit: %w", err)
+	}
+	return nil
+}
+
 func (s *Store) ListEventsSince(seq int64) ([]model.Event, error) {
 	if err := s.Open(); err != nil {
 		return nil, err
@@ -522,33 +606,75 @@ func matchesFilter(task model.Task, filter model.TaskFilter) bool {
 	if filter.Status != "" && task.Status != filter.Status {
 		return false
 	}
+	if filter.State != "" && task.State != filter.State {
+		return false
+	}
 	if filter.Archived != nil && task.Archived != *filter.Archived {
 		return false
 	}
-	if filter.DueDate != "" {
-		if formatDate(task.DueDate) != filter.DueDate {
+	if filter.DueDate != "" || filter.DueFrom != "" || filter.DueTo != "" {
+		due := logic.LocalDueDate(task, filter.Location)
+		if filter.DueDate != "" && due != filter.DueDate {
+			return false
+		}
+		if filter.DueFrom != "" && (due == "" || due < filter.DueFrom) {
+			return false
+		}
+		if filter.DueTo != "" && (due == "" || due > filter.DueTo) {
+			return false
+		}
+	}
+	if filter.ProjectID != nil && task.ProjectID != *filter.ProjectID {
+		return false
+	}
+	if filter.HideDeferred && logic.IsDeferred(task, logic.Today(time.Now(), filter.Location)) {
+		return false
+	}
+	if len(filter.TagsAny) > 0 || len(filter.TagsAll) > 0 || len(filter.TagsNone) > 0 {
+		tags := make(map[string]bool, len(task.Tags))
+		for _, tag := range task.Tags {
+			tags[tag] = true
+		}
+		if len(filter.TagsAny) > 0 && !hasAnyTag(tags, filter.TagsAny) {
+			return false
+		}
+		for _, tag := range filter.TagsAll {
+			if !tags[tag] {
+				return false
+			}
+		}
+		if hasAnyTag(tags, filter.TagsNone) {
 			return false
 		}
 	}
 	return true
 }
 
-func sortTasks(tasks []model.Task) {
+func hasAnyTag(tags map[string]bool, wanted []string) bool {
+	for _, tag := range wanted {
+		if tags[tag] {
+			return true
+		}
+	}
+	return false
+}
+
+func sortTasks(tasks []model.Task, loc *time.Location) {
 	sort.Slice(tasks, func(i, j int) bool {
 		ai := tasks[i]
 		aj := tasks[j]
-		aiZero := ai.DueDate.IsZero()
-		ajZero := aj.DueDate.IsZero()
-		if aiZero != ajZero {
-			return !aiZero
-		}
-		if !aiZero && !ajZero {
-			if !ai.DueDate.Equal(aj.DueDate) {
-				return ai.DueDate.Before(aj.DueDate)
+		aiDue, aiOK := logic.DueInstant(ai, loc)
+		ajDue, ajOK := logic.DueInstant(aj, loc)
+		if aiOK != ajOK {
+			return aiOK
+		}
+		if aiOK && ajOK {
+			if !aiDue.Equal(ajDue) {
+				return aiDue.Before(ajDue)
 			}
 		}
-		if ai.Order != aj.Order {
-			return ai.Order < aj.Order
+		if c := logic.CompareRank(ai, aj); c != 0 {
+			return c < 0
 		}
 		return ai.CreatedAt.Before(aj.CreatedAt)
 	})
@@ -236,3 +236,200 @@ func newTestCryptor(t *testing.T) *crypto.Manager {
 	}
 	return manager
 }
+
+func TestProjectsRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	projects := []model.Project{
+		{ID: "p2", Name: "Home", Order: 2, CreatedAt: now, UpdatedAt: now},
+		{ID: "p1", Name: "Work", Color: "#ff0000", Order: 1, CreatedAt: now, UpdatedAt: now},
+	}
+	for _, project := range projects {
+		if err := store.UpsertProject(project); err != nil {
+			t.Fatalf("upsert project: %v", err)
+		}
+	}
+	listed, err := store.ListProjects()
+	if err != nil {
+		t.Fatalf("list projects: %v", err)
+	}
+	if len(listed) != 2 || listed[0].ID != "p1" {
+		t.Fatalf("expected projects sorted by order, got %+v", listed)
+	}
+
+	if err := store.UpsertTask(model.Task{ID: "t1", Title: "In project", ProjectID: "p1"}); err != nil {
+		t.Fatalf("upsert task: %v", err)
+	}
+	if err := store.UpsertTask(model.Task{ID: "t2", Title: "Inbox"}); err != nil {
+		t.Fatalf("upsert task: %v", err)
+	}
+	projectID := "p1"
+	tasks, err := store.ListTasks(model.TaskFilter{ProjectID: &projectID})
+	if err != nil {
+		t.Fatalf("list tasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != "t1" {
+		t.Fatalf("unexpected project filter result: %+v", tasks)
+	}
+
+	if err := store.DeleteProject("p1"); err != nil {
+		t.Fatalf("delete project: %v", err)
+	}
+	got, err := store.GetProject("p1")
+	if err != nil {
+		t.Fatalf("get project: %v", err)
+	}
+	if got.ID != "" {
+		t.Fatalf("expected project to be deleted")
+	}
+}
+
+func TestCommentsRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	comments := []model.Comment{
+		{ID: "c2", TaskID: "t1", Text: "Second", CreatedAt: now.Add(time.Minute)},
+		{ID: "c1", TaskID: "t1", Text: "First", CreatedAt: now},
+		{ID: "c3", TaskID: "t2", Text: "Other task", CreatedAt: now},
+	}
+	for _, comment := range comments {
+		if err := store.UpsertComment(comment); err != nil {
+			t.Fatalf("upsert comment: %v", err)
+		}
+	}
+	listed, err := store.ListComments("t1")
+	if err != nil {
+		t.Fatalf("list comments: %v", err)
+	}
+	if len(listed) != 2 || listed[0].ID != "c1" {
+		t.Fatalf("expected t1 comments oldest first, got %+v", listed)
+	}
+
+	if err := store.DeleteTaskComments("t1"); err != nil {
+		t.Fatalf("delete comments: %v", err)
+	}
+	got, err := store.GetComment("c1")
+	if err != nil {
+		t.Fatalf("get comment: %v", err)
+	}
+	if got.ID != "" {
+		t.Fatalf("expected comment to be deleted")
+	}
+	if other, _ := store.GetComment("c3"); other.ID != "c3" {
+		t.Fatalf("expected other task's comment to remain")
+	}
+}
+
+func TestBlobsChunkedRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	data := make([]byte, BlobChunkSize*2+17)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	if err := store.PutBlob("h1", data); err != nil {
+		t.Fatalf("put blob: %v", err)
+	}
+	if err := store.PutBlob("h2", nil); err != nil {
+		t.Fatalf("put empty blob: %v", err)
+	}
+	got, err := store.GetBlob("h1")
+	if err != nil {
+		t.Fatalf("get blob: %v", err)
+	}
+	if string(got) != string(data) {
+		t.Fatalf("blob round trip mismatch: got %d bytes", len(got))
+	}
+	var chunks int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM blob_chunks WHERE hash = 'h1'`).Scan(&chunks); err != nil {
+		t.Fatalf("count chunks: %v", err)
+	}
+	if chunks != 3 {
+		t.Fatalf("expected 3 chunks, got %d", chunks)
+	}
+
+	if err := store.DeleteBlob("h1"); err != nil {
+		t.Fatalf("delete blob: %v", err)
+	}
+	hashes, err := store.ListBlobs()
+	if err != nil {
+		t.Fatalf("list blobs: %v", err)
+	}
+	if len(hashes) != 1 || hashes[0] != "h2" {
+		t.Fatalf("expected only h2 to remain, got %v", hashes)
+	}
+}
+
+func TestTimeEntriesRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	entries := []model.TimeEntry{
+		{ID: "e2", TaskID: "t1", Start: now.Add(time.Hour)},
+		{ID: "e1", TaskID: "t1", Start: now, End: now.Add(time.Minute)},
+		{ID: "e3", TaskID: "t2", Start: now},
+	}
+	for _, entry := range entries {
+		if err := store.UpsertTimeEntry(entry); err != nil {
+			t.Fatalf("upsert time entry: %v", err)
+		}
+	}
+	listed, err := store.ListTimeEntries("t1")
+	if err != nil {
+		t.Fatalf("list time entries: %v", err)
+	}
+	if len(listed) != 2 || listed[0].ID != "e1" || !listed[0].End.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expected t1 entries in start order, got %+v", listed)
+	}
+	all, err := store.ListTimeEntries("")
+	if err != nil || len(all) != 3 {
+		t.Fatalf("expected all entries, got %d (%v)", len(all), err)
+	}
+
+	if err := store.DeleteTaskTimeEntries("t1"); err != nil {
+		t.Fatalf("delete time entries: %v", err)
+	}
+	if got, _ := store.GetTimeEntry("e1"); got.ID != "" {
+		t.Fatalf("expected entry to be deleted")
+	}
+	if other, _ := store.GetTimeEntry("e3"); other.ID != "e3" {
+		t.Fatalf("expected other task's entry to remain")
+	}
+}
+
+func TestWorkflowRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	empty, err := store.GetWorkflow()
+	if err != nil {
+		t.Fatalf("get workflow: %v", err)
+	}
+	if len(empty.States) != 0 {
+		t.Fatalf("expected no stored workflow, got %+v", empty)
+	}
+	workflow := model.Workflow{
+		States: []model.WorkflowState{
+			{ID: "todo", Name: "To do", Category: "open"},
+			{ID: "done", Name: "Done", Category: "done"},
+		},
+		Transitions: []model.WorkflowTransition{{From: "todo", To: "done"}},
+		UpdatedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+	if err := store.SaveWorkflow(workflow); err != nil {
+		t.Fatalf("save workflow: %v", err)
+	}
+	got, err := store.GetWorkflow()
+	if err != nil {
+		t.Fatalf("get workflow: %v", err)
+	}
+	if len(got.States) != 2 || got.Transitions[0].To != "done" || !got.UpdatedAt.Equal(workflow.UpdatedAt) {
+		t.Fatalf("unexpected workflow: %+v", got)
+	}
+}
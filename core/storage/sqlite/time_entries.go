@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"taskpp/core/model"
+)
+
+// ListTimeEntries returns a task's time entries, tombstones included, in
+// start order. An empty taskID lists entries for every task.
+func (s *Store) ListTimeEntries(taskID string) ([]model.TimeEntry, error) {
+	if err := s.Open(); err != nil {
+		return nil, err
+	}
+	if s.enc == nil || !s.enc.IsUnlocked() {
+		return nil, fmt.Errorf("keys not unlocked")
+	}
+
+	query := `SELECT ciphertext FROM time_entries`
+	args := []any{}
+	if taskID != "" {
+		query += ` WHERE task_id = ?`
+		args = append(args, taskID)
+	}
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list time entries: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]model.TimeEntry, 0)
+	for rows.Next() {
+		var ciphertext []byte
+		if err := rows.Scan(&ciphertext); err != nil {
+			return nil, fmt.Errorf("list time entries scan: %w", err)
+		}
+		entry, err := s.decryptTimeEntry(ciphertext)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list time entries rows: %w", err)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Start.Equal(out[j].Start) {
+			return out[i].Start.Before(out[j].Start)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+func (s *Store) GetTimeEntry(id string) (model.TimeEntry, error) {
+	if err := s.Open(); err != nil {
+		return model.TimeEntry{}, err
+	}
+	if s.enc == nil || !s.enc.IsUnlocked() {
+		return model.TimeEntry{}, fmt.Errorf("keys not unlocked")
+	}
+
+	row := s.db.QueryRow(`SELECT ciphertext FROM time_entries WHERE id = ?`, id)
+	var ciphertext []byte
+	if err := row.Scan(&ciphertext); err != nil {
+		if err == sql.ErrNoRows {
+			return model.TimeEntry{}, nil
+		}
+		return model.TimeEntry{}, fmt.Errorf("get time entry: %w", err)
+	}
+	return s.decryptTimeEntry(ciphertext)
+}
+
+func (s *Store) UpsertTimeEntry(entry model.TimeEntry) error {
+	if err := s.Open(); err != nil {
+		return err
+	}
+	if s.enc == nil || !s.enc.IsUnlocked() {
+		return fmt.Errorf("keys not unlocked")
+	}
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode time entry: %w", err)
+	}
+	ciphertext, err := s.enc.Encrypt(payload)
+	if err != nil {
+		return fmt.Errorf("encrypt time entry: %w", err)
+	}
+	stmt := `INSERT INTO time_entries (id, task_id, ciphertext) VALUES (?, ?, ?)
+	ON CONFLICT(id) DO UPDATE SET
+		task_id = excluded.task_id,
+		ciphertext = excluded.ciphertext`
+	if _, err := s.db.Exec(stmt, entry.ID, entry.TaskID, ciphertext); err != nil {
+		return fmt.Errorf("upsert time entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteTaskTimeEntries drops every time entry logged against a task.
+func (s *Store) DeleteTaskTimeEntries(taskID string) error {
+	if err := s.Open(); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec(`DELETE FROM time_entries WHERE task_id = ?`, taskID); err != nil {
+		return fmt.Errorf("delete time entries: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) decryptTimeEntry(ciphertext []byte) (model.TimeEntry, error) {
+	payload, err := s.enc.Decrypt(ciphertext)
+	if err != nil {
+		return model.TimeEntry{}, fmt.Errorf("decrypt time entry: %w", err)
+	}
+	var entry model.TimeEntry
+	if err := json.Unmarshal(payload, &entry); err != nil {
+		return model.TimeEntry{}, fmt.Errorf("decode time entry: %w", err)
+	}
+	return entry, nil
+}
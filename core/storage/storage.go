@@ -12,9 +12,42 @@ type Storage interface {
 	UpsertTask(task model.Task) error
 	DeleteTask(id string) error
 
+	ListProjects() ([]model.Project, error)
+	GetProject(id string) (model.Project, error)
+	UpsertProject(project model.Project) error
+	DeleteProject(id string) error
+
+	ListComments(taskID string) ([]model.Comment, error)
+	GetComment(id string) (model.Comment, error)
+	UpsertComment(comment model.Comment) error
+	DeleteTaskComments(taskID string) error
+
+	ListTimeEntries(taskID string) ([]model.TimeEntry, error)
+	GetTimeEntry(id string) (model.TimeEntry, error)
+	UpsertTimeEntry(entry model.TimeEntry) error
+	DeleteTaskTimeEntries(taskID string) error
+
+	ListDevices() ([]model.Device, error)
+	GetDevice(id string) (model.Device, error)
+	UpsertDevice(device model.Device) error
+
+	GetWorkflow() (model.Workflow, error)
+	SaveWorkflow(workflow model.Workflow) error
+	GetSnapshot() (model.Snapshot, error)
+	SaveSnapshot(snapshot model.Snapshot) error
+	GetAccount() (model.Account, error)
+	SaveAccount(account model.Account) error
+
+	HasBlob(hash string) (bool, error)
+	PutBlob(hash string, data []byte) error
+	GetBlob(hash string) ([]byte, error)
+	ListBlobs() ([]string, error)
+	DeleteBlob(hash string) error
+
 	AppendEvents(events []model.Event) error
 	ListEventsSince(seq int64) ([]model.Event, error)
 	HasEvent(id string) (bool, error)
+	DeleteEvents(ids []string) error
 	GetSyncState() (model.SyncState, error)
 	SaveSyncState(state model.SyncState) error
 
@@ -0,0 +1,183 @@
+package sync
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrUnauthorized is returned when the server rejects credentials or the
+// session token; a stored token should be dropped.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// AccountSession is a login on the sync server. The token is a bearer
+// credential for the device that logged in.
+type AccountSession struct {
+	UserID    string
+	Token     string
+	ExpiresAt time.Time
+}
+
+// AccountClient talks to the account API of a sync server on behalf of one
+// device.
+type AccountClient struct {
+	baseURL  string
+	deviceID string
+	http     *http.Client
+}
+
+// NewAccountClient returns a client for the server at baseURL.
+func NewAccountClient(baseURL, deviceID string) *AccountClient {
+	return &AccountClient{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		deviceID: deviceID,
+		http:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type accountCredentials struct {
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	DeviceID   string `json:"device_id"`
+	DeviceName string `json:"device_name"`
+}
+
+type accountSession struct {
+	UserID     string `json:"user_id"`
+	Token      string `json:"token"`
+	ExpiresAt  string `json:"expires_at"`
+	DEKWrapped []byte `json:"dek_wrapped,omitempty"`
+}
+
+type accountRecovery struct {
+	DEKWrapped []byte `json:"dek_wrapped"`
+	Verifier   []byte `json:"verifier,omitempty"`
+}
+
+type accountReset struct {
+	Username    string `json:"username"`
+	Verifier    []byte `json:"verifier"`
+	NewPassword string `json:"new_password"`
+	DeviceID    string `json:"device_id"`
+	DeviceName  string `json:"device_name"`
+}
+
+// Signup creates an account and logs this device in.
+func (a *AccountClient) Signup(username, password, deviceName string) (AccountSession, error) {
+	return a.startSession("/auth/signup", username, password, deviceName)
+}
+
+// Login logs this device in.
+func (a *AccountClient) Login(username, password, deviceName string) (AccountSession, error) {
+	return a.startSession("/auth/login", username, password, deviceName)
+}
+
+func (a *AccountClient) startSession(path, username, password, deviceName string) (AccountSession, error) {
+	var reply accountSession
+	creds := accountCredentials{Username: username, Password: password, DeviceID: a.deviceID, DeviceName: deviceName}
+	if err := a.do(http.MethodPost, path, "", creds, &reply); err != nil {
+		return AccountSession{}, err
+	}
+	return reply.session()
+}
+
+// Logout revokes the session's token.
+func (a *AccountClient) Logout(session AccountSession) error {
+	return a.do(http.MethodPost, "/auth/logout", session.Token, nil, nil)
+}
+
+// RevokeDevice signs another device of the account out.
+func (a *AccountClient) RevokeDevice(session AccountSession, deviceID string) error {
+	return a.do(http.MethodPost, "/auth/revoke", session.Token, map[string]string{"device_id": deviceID}, nil)
+}
+
+// SaveRecovery uploads the data key wrapped with the recovery key, and the
+// verifier that later authorizes ResetPassword.
+func (a *AccountClient) SaveRecovery(session AccountSession, dekWrapped, verifier []byte) error {
+	return a.do(http.MethodPut, "/auth/recovery", session.Token, accountRecovery{DEKWrapped: dekWrapped, Verifier: verifier}, nil)
+}
+
+// Recovery downloads the recovery-wrapped data key.
+func (a *AccountClient) Recovery(session AccountSession) ([]byte, error) {
+	var reply accountRecovery
+	if err := a.do(http.MethodGet, "/auth/recovery", session.Token, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply.DEKWrapped, nil
+}
+
+// ResetPassword sets a new password with the recovery verifier instead of
+// the old password. Every other session of the account is revoked; this
+// device is logged in and receives the recovery-wrapped data key.
+func (a *AccountClient) ResetPassword(username string, verifier []byte, newPassword, deviceName string) (AccountSession, []byte, error) {
+	var reply accountSession
+	req := accountReset{Username: username, Verifier: verifier, NewPassword: newPassword, DeviceID: a.deviceID, DeviceName: deviceName}
+	if err := a.do(http.MethodPost, "/auth/reset-password", "", req, &reply); err != nil {
+		return AccountSession{}, nil, err
+	}
+	session, err := reply.session()
+	if err != nil {
+		return AccountSession{}, nil, err
+	}
+	return session, reply.DEKWrapped, nil
+}
+
+func (s accountSession) session() (AccountSession, error) {
+	expires, err := time.Parse(time.RFC3339, s.ExpiresAt)
+	if err != nil || s.Token == "" {
+		return AccountSession{}, fmt.Errorf("invalid session from server")
+	}
+	return AccountSession{UserID: s.UserID, Token: s.Token, ExpiresAt: expires}, nil
+}
+
+// do sends a JSON request and decodes a JSON reply into out. Error replies
+// carry {"error": "..."}; 401 wraps ErrUnauthorized.
+func (a *AccountClient) do(method, path, token string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, a.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Device-ID", a.deviceID)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("contact server: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&failure)
+		if failure.Error == "" {
+			failure.Error = resp.Status
+		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			return fmt.Errorf("%w: %s", ErrUnauthorized, failure.Error)
+		}
+		return fmt.Errorf("server: %s", failure.Error)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
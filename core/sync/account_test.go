@@ -0,0 +1,74 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"taskpp/internal/server"
+)
+
+func newAccountServer(t *testing.T) string {
+	t.Helper()
+	store, err := server.OpenStore(context.Background(), "file:"+filepath.Join(t.TempDir(), "server.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	srv, err := server.New(store, server.Options{})
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	httpServer := httptest.NewServer(srv.Handler())
+	t.Cleanup(httpServer.Close)
+	return httpServer.URL
+}
+
+func TestAccountClientAgainstServer(t *testing.T) {
+	url := newAccountServer(t)
+	laptop := NewAccountClient(url+"/", "laptop")
+	phone := NewAccountClient(url, "phone")
+
+	session, err := laptop.Signup("alice", "correct horse", "Laptop")
+	if err != nil || session.Token == "" || session.ExpiresAt.IsZero() {
+		t.Fatalf("signup: %+v %v", session, err)
+	}
+	if _, err := phone.Login("alice", "wrong password", "Phone"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+	phoneSession, err := phone.Login("alice", "correct horse", "Phone")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if _, err := laptop.Recovery(phoneSession); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected the phone's token to be refused from the laptop, got %v", err)
+	}
+
+	verifier := bytes.Repeat([]byte{3}, 32)
+	if err := laptop.SaveRecovery(session, []byte("wrapped dek"), verifier); err != nil {
+		t.Fatalf("save recovery: %v", err)
+	}
+	if wrapped, err := phone.Recovery(phoneSession); err != nil || string(wrapped) != "wrapped dek" {
+		t.Fatalf("recovery: %q %v", wrapped, err)
+	}
+	if err := laptop.RevokeDevice(session, "phone"); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, err := phone.Recovery(phoneSession); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected the revoked phone to be refused, got %v", err)
+	}
+
+	resetSession, wrapped, err := phone.ResetPassword("alice", verifier, "battery staple", "Phone")
+	if err != nil || string(wrapped) != "wrapped dek" {
+		t.Fatalf("reset: %q %v", wrapped, err)
+	}
+	if err := laptop.Logout(session); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected the reset to sign the laptop out, got %v", err)
+	}
+	if err := phone.Logout(resetSession); err != nil {
+		t.Fatalf("logout: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"taskpp/core/model"
+)
+
+// ApplyCommentEvent applies a comment event. Edits and deletes are only
+// accepted from the authoring device, and a deleted comment stays deleted.
+// Returns (updatedComment, changed, conflict, error).
+func ApplyCommentEvent(comment model.Comment, event model.Event) (model.Comment, bool, bool, error) {
+	var payload CommentDTO
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return model.Comment{}, false, false, fmt.Errorf("decode comment payload: %w", err)
+	}
+	evtTime, err := time.Parse(time.RFC3339Nano, payload.UpdatedAt)
+	if err != nil && payload.UpdatedAt != "" {
+		return model.Comment{}, false, false, fmt.Errorf("parse updated_at: %w", err)
+	}
+	if payload.UpdatedAt == "" {
+		evtTime = event.TS
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, payload.CreatedAt)
+	if err != nil && payload.CreatedAt != "" {
+		return model.Comment{}, false, false, fmt.Errorf("parse created_at: %w", err)
+	}
+
+	if comment.ID != "" {
+		if comment.Deleted || event.DeviceID != comment.DeviceID {
+			return comment, false, false, nil
+		}
+	} else if payload.DeviceID != event.DeviceID {
+		return comment, false, false, nil
+	}
+	updated := model.Comment{
+		ID:        payload.ID,
+		TaskID:    payload.TaskID,
+		DeviceID:  payload.DeviceID,
+		Text:      payload.Text,
+		CreatedAt: createdAt,
+		UpdatedAt: evtTime,
+		Deleted:   payload.Deleted,
+	}
+	if comment.ID != "" {
+		// The thread position and author never change after creation.
+		updated.TaskID, updated.DeviceID, updated.CreatedAt = comment.TaskID, comment.DeviceID, comment.CreatedAt
+	}
+	if updated.Deleted {
+		return updated, true, false, nil
+	}
+	changed, conflict := resolveLWW(comment.ID != "", comment.UpdatedAt, updated.UpdatedAt, event.Seq)
+	if changed {
+		return updated, true, false, nil
+	}
+	return comment, false, conflict, nil
+}
+
+// CommentDTO mirrors bind.CommentDTO without imports to avoid dependency cycles.
+type CommentDTO struct {
+	ID        string `json:"id"`
+	TaskID    string `json:"task_id"`
+	DeviceID  string `json:"device_id"`
+	Text      string `json:"text"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+	Deleted   bool   `json:"deleted"`
+}
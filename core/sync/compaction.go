@@ -0,0 +1,168 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"taskpp/core/model"
+)
+
+// Cipher encrypts snapshot bodies with the vault key.
+type Cipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// SnapshotEntry is one entity of a snapshot in event form: a type such as
+// "create" or "project_create" and the DTO an event of that type carries.
+// Bootstrapping replays entries through the normal apply path.
+type SnapshotEntry struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// SnapshotBody is the plaintext of a snapshot.
+type SnapshotBody struct {
+	Cursor    model.Cursor    `json:"cursor"`
+	CreatedAt string          `json:"created_at"`
+	Entries   []SnapshotEntry `json:"entries"`
+}
+
+// CursorOf returns the highest seq per device among events, merged into
+// base.
+func CursorOf(base model.Cursor, events []model.Event) model.Cursor {
+	cursor := make(model.Cursor, len(base))
+	for device, seq := range base {
+		cursor[device] = seq
+	}
+	for _, event := range events {
+		if event.Seq > cursor[event.DeviceID] {
+			cursor[event.DeviceID] = event.Seq
+		}
+	}
+	return cursor
+}
+
+// MergeCursor returns the per-device maximum of a and b.
+func MergeCursor(a, b model.Cursor) model.Cursor {
+	out := CursorOf(a, nil)
+	for device, seq := range b {
+		if seq > out[device] {
+			out[device] = seq
+		}
+	}
+	return out
+}
+
+// Covers reports whether cursor includes event.
+func Covers(cursor model.Cursor, event model.Event) bool {
+	seq, ok := cursor[event.DeviceID]
+	return ok && event.Seq <= seq
+}
+
+// Prunable returns the ids of events that the snapshot cursor covers and
+// that every known peer has acknowledged. Peers are the devices that wrote
+// events or acknowledged anything; one that never acknowledged blocks
+// pruning entirely, since it may still need any event.
+func Prunable(events []model.Event, snapshot model.Cursor, localDeviceID string, acks map[string]model.Cursor) []string {
+	peers := make(map[string]bool)
+	for device := range snapshot {
+		peers[device] = true
+	}
+	for _, event := range events {
+		peers[event.DeviceID] = true
+	}
+	for device := range acks {
+		peers[device] = true
+	}
+	delete(peers, localDeviceID)
+
+	out := make([]string, 0)
+	for _, event := range events {
+		if !Covers(snapshot, event) {
+			continue
+		}
+		acked := true
+		for peer := range peers {
+			if event.DeviceID == peer {
+				continue
+			}
+			if !Covers(acks[peer], event) {
+				acked = false
+				break
+			}
+		}
+		if acked {
+			out = append(out, event.ID)
+		}
+	}
+	return out
+}
+
+// SealSnapshot encrypts a snapshot body.
+func SealSnapshot(body SnapshotBody, cipher Cipher) (model.Snapshot, error) {
+	createdAt, err := time.Parse(time.RFC3339Nano, body.CreatedAt)
+	if err != nil {
+		return model.Snapshot{}, fmt.Errorf("parse created_at: %w", err)
+	}
+	plaintext, err := json.Marshal(body)
+	if err != nil {
+		return model.Snapshot{}, fmt.Errorf("encode snapshot: %w", err)
+	}
+	payload, err := cipher.Encrypt(plaintext)
+	if err != nil {
+		return model.Snapshot{}, fmt.Errorf("encrypt snapshot: %w", err)
+	}
+	return model.Snapshot{Cursor: body.Cursor, CreatedAt: createdAt, Payload: payload}, nil
+}
+
+// OpenSnapshot decrypts a snapshot and checks its cursor matches the
+// unencrypted one it travelled with.
+func OpenSnapshot(snapshot model.Snapshot, cipher Cipher) (SnapshotBody, error) {
+	plaintext, err := cipher.Decrypt(snapshot.Payload)
+	if err != nil {
+		return SnapshotBody{}, fmt.Errorf("decrypt snapshot: %w", err)
+	}
+	var body SnapshotBody
+	if err := json.Unmarshal(plaintext, &body); err != nil {
+		return SnapshotBody{}, fmt.Errorf("decode snapshot: %w", err)
+	}
+	if len(body.Cursor) != len(snapshot.Cursor) {
+		return SnapshotBody{}, fmt.Errorf("snapshot cursor mismatch")
+	}
+	for device, seq := range body.Cursor {
+		if snapshot.Cursor[device] != seq {
+			return SnapshotBody{}, fmt.Errorf("snapshot cursor mismatch")
+		}
+	}
+	return body, nil
+}
+
+// SnapshotEvents turns snapshot entries into events for the apply path.
+// Comments keep their author as the event device, since comment events
+// are only accepted from the author.
+func SnapshotEvents(body SnapshotBody) ([]model.Event, error) {
+	createdAt, err := time.Parse(time.RFC3339Nano, body.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("parse created_at: %w", err)
+	}
+	out := make([]model.Event, 0, len(body.Entries))
+	for i, entry := range body.Entries {
+		event := model.Event{
+			ID:      fmt.Sprintf("snapshot-%d", i),
+			TS:      createdAt,
+			Type:    entry.Type,
+			Payload: entry.Payload,
+		}
+		var author struct {
+			DeviceID string `json:"device_id"`
+		}
+		if err := json.Unmarshal(entry.Payload, &author); err != nil {
+			return nil, fmt.Errorf("decode snapshot entry: %w", err)
+		}
+		event.DeviceID = author.DeviceID
+		out = append(out, event)
+	}
+	return out, nil
+}
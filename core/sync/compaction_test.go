@@ -0,0 +1,66 @@
+package sync
+
+import (
+	"reflect"
+	"testing"
+
+	"taskpp/core/model"
+)
+
+func TestPrunableNeedsEveryPeerAck(t *testing.T) {
+	events := []model.Event{
+		{ID: "a1", DeviceID: "a", Seq: 1},
+		{ID: "a2", DeviceID: "a", Seq: 2},
+		{ID: "b1", DeviceID: "b", Seq: 1},
+		{ID: "a3", DeviceID: "a", Seq: 3},
+	}
+	snapshot := CursorOf(nil, events[:3])
+
+	if got := Prunable(events, snapshot, "a", nil); !reflect.DeepEqual(got, []string{"b1"}) {
+		t.Fatalf("expected only b's own event without acks, got %v", got)
+	}
+	acks := map[string]model.Cursor{"b": {"a": 1}}
+	if got := Prunable(events, snapshot, "a", acks); !reflect.DeepEqual(got, []string{"a1", "b1"}) {
+		t.Fatalf("expected acked events, got %v", got)
+	}
+	acks["c"] = model.Cursor{"a": 3, "b": 1}
+	acks["b"] = MergeCursor(acks["b"], model.Cursor{"a": 3})
+	if got := Prunable(events, snapshot, "a", acks); !reflect.DeepEqual(got, []string{"a1", "a2", "b1"}) {
+		t.Fatalf("expected events outside the snapshot to stay, got %v", got)
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	body := SnapshotBody{
+		Cursor:    model.Cursor{"a": 2},
+		CreatedAt: "2026-03-01T10:00:00Z",
+		Entries: []SnapshotEntry{
+			{Type: "create", Payload: []byte(`{"id":"t1"}`)},
+			{Type: "comment_add", Payload: []byte(`{"id":"c1","device_id":"b"}`)},
+		},
+	}
+	snapshot, err := SealSnapshot(body, plainCipher{})
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	opened, err := OpenSnapshot(snapshot, plainCipher{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	events, err := SnapshotEvents(opened)
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	if len(events) != 2 || events[0].DeviceID != "" || events[1].DeviceID != "b" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	snapshot.Cursor = model.Cursor{"a": 3}
+	if _, err := OpenSnapshot(snapshot, plainCipher{}); err == nil {
+		t.Fatalf("expected cursor mismatch")
+	}
+}
+
+type plainCipher struct{}
+
+func (plainCipher) Encrypt(plaintext []byte) ([]byte, error)  { return plaintext, nil }
+func (plainCipher) Decrypt(ciphertext []byte) ([]byte, error) { return ciphertext, nil }
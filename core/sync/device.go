@@ -0,0 +1,67 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"taskpp/core/model"
+)
+
+// ApplyDeviceEvent applies a device registry event. Name and platform
+// resolve by LWW on updated_at, acknowledged cursors only move forward and
+// a revocation is sticky. LastSeen is local and never taken from events.
+// Returns (updatedDevice, changed, conflict, error).
+func ApplyDeviceEvent(device model.Device, event model.Event) (model.Device, bool, bool, error) {
+	var payload DeviceDTO
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return model.Device{}, false, false, fmt.Errorf("decode device payload: %w", err)
+	}
+	evtTime, err := time.Parse(time.RFC3339Nano, payload.UpdatedAt)
+	if err != nil && payload.UpdatedAt != "" {
+		return model.Device{}, false, false, fmt.Errorf("parse updated_at: %w", err)
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, payload.CreatedAt)
+	if err != nil && payload.CreatedAt != "" {
+		return model.Device{}, false, false, fmt.Errorf("parse created_at: %w", err)
+	}
+
+	updated := device
+	updated.ID = payload.ID
+	conflict := false
+	if payload.UpdatedAt != "" {
+		var changed bool
+		changed, conflict = resolveLWW(device.ID != "" && !device.UpdatedAt.IsZero(), device.UpdatedAt, evtTime, event.Seq)
+		if changed {
+			updated.Name = payload.Name
+			updated.Platform = payload.Platform
+			updated.UpdatedAt = evtTime
+		}
+	}
+	if updated.CreatedAt.IsZero() || (!createdAt.IsZero() && createdAt.Before(updated.CreatedAt)) {
+		updated.CreatedAt = createdAt
+	}
+	if len(payload.Acked) > 0 {
+		updated.Acked = MergeCursor(device.Acked, payload.Acked)
+	}
+	if payload.Revoked {
+		updated.Revoked = true
+		if payload.RevokedSeq > updated.RevokedSeq {
+			updated.RevokedSeq = payload.RevokedSeq
+		}
+	}
+	return updated, !reflect.DeepEqual(updated, device), conflict, nil
+}
+
+// DeviceDTO mirrors bind.DeviceDTO without imports to avoid dependency cycles.
+type DeviceDTO struct {
+	ID         string       `json:"id"`
+	Name       string       `json:"name"`
+	Platform   string       `json:"platform"`
+	CreatedAt  string       `json:"created_at"`
+	UpdatedAt  string       `json:"updated_at"`
+	Acked      model.Cursor `json:"acked"`
+	Revoked    bool         `json:"revoked"`
+	RevokedSeq int64        `json:"revoked_seq"`
+}
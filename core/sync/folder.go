@@ -0,0 +1,230 @@
+package sync
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"taskpp/core/model"
+)
+
+// segmentExt marks finished segment files. Anything else in a device
+// directory, such as an interrupted write, is ignored.
+const segmentExt = ".seg"
+
+// FolderTransport syncs through a directory that something else replicates
+// (a network share, Syncthing, a USB stick). Every device appends its own
+// events as segment files under <dir>/<device-id>/ and reads the segments
+// of all other devices, so no two devices ever write the same file.
+//
+// A segment named "<after>-<last>.seg" holds one JSON event per line with
+// seqs in (after, last]. Readers follow the chain of segments from their
+// cursor and stop at a gap, so a segment that has not been replicated yet
+// is picked up on a later sync. A segment that was cut short while copying
+// is read up to its last complete line.
+type FolderTransport struct {
+	dir      string
+	deviceID string
+}
+
+// NewFolderTransport returns a transport for deviceID over dir.
+func NewFolderTransport(dir, deviceID string) *FolderTransport {
+	return &FolderTransport{dir: dir, deviceID: deviceID}
+}
+
+type segment struct {
+	name  string
+	after int64
+	last  int64
+}
+
+// Push writes the given events of this device that are not in a segment yet
+// as one new segment. The file is written under a temporary name and
+// renamed when complete.
+func (f *FolderTransport) Push(events []model.Event) (int, error) {
+	if f.deviceID == "" {
+		return 0, fmt.Errorf("missing device id")
+	}
+	own := filepath.Join(f.dir, f.deviceID)
+	if err := os.MkdirAll(own, 0o700); err != nil {
+		return 0, fmt.Errorf("create device folder: %w", err)
+	}
+	segments, err := listSegments(own)
+	if err != nil {
+		return 0, err
+	}
+	var written int64
+	if len(segments) > 0 {
+		written = segments[len(segments)-1].last
+	}
+	pending := make([]model.Event, 0)
+	for _, event := range events {
+		if event.DeviceID == f.deviceID && event.Seq > written {
+			pending = append(pending, event)
+		}
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Seq < pending[j].Seq })
+
+	tmp, err := os.CreateTemp(own, ".segment-*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("create segment: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for _, event := range pending {
+		if err := encoder.Encode(ToWire(event)); err != nil {
+			tmp.Close()
+			return 0, fmt.Errorf("write segment: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("write segment: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("sync segment: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("close segment: %w", err)
+	}
+	name := fmt.Sprintf("%d-%d%s", written, pending[len(pending)-1].Seq, segmentExt)
+	if err := os.Rename(tmp.Name(), filepath.Join(own, name)); err != nil {
+		return 0, fmt.Errorf("publish segment: %w", err)
+	}
+	return len(pending), nil
+}
+
+// Pull reads every other device's segments past cursor.
+func (f *FolderTransport) Pull(cursor model.Cursor) ([]model.Event, error) {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read sync folder: %w", err)
+	}
+	out := make([]model.Event, 0)
+	for _, entry := range entries {
+		deviceID := entry.Name()
+		if !entry.IsDir() || deviceID == f.deviceID || strings.HasPrefix(deviceID, ".") {
+			continue
+		}
+		events, err := f.pullDevice(deviceID, cursor[deviceID])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, events...)
+	}
+	return out, nil
+}
+
+func (f *FolderTransport) pullDevice(deviceID string, have int64) ([]model.Event, error) {
+	dir := filepath.Join(f.dir, deviceID)
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.Event, 0)
+	for _, seg := range segments {
+		if seg.last <= have {
+			continue
+		}
+		if seg.after > have {
+			break
+		}
+		events, complete, err := readSegment(filepath.Join(dir, seg.name), deviceID, have)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			out = append(out, event)
+			have = event.Seq
+		}
+		if !complete || have < seg.last {
+			break
+		}
+	}
+	return out, nil
+}
+
+// listSegments returns the finished segments in dir, ordered by position in
+// the chain.
+func listSegments(dir string) ([]segment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read device folder: %w", err)
+	}
+	out := make([]segment, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		bounds := strings.SplitN(strings.TrimSuffix(name, segmentExt), "-", 2)
+		if len(bounds) != 2 {
+			continue
+		}
+		after, err1 := strconv.ParseInt(bounds[0], 10, 64)
+		last, err2 := strconv.ParseInt(bounds[1], 10, 64)
+		if err1 != nil || err2 != nil || last <= after {
+			continue
+		}
+		out = append(out, segment{name: name, after: after, last: last})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].after != out[j].after {
+			return out[i].after < out[j].after
+		}
+		return out[i].last > out[j].last
+	})
+	return out, nil
+}
+
+// readSegment returns the events past have from a segment. complete is
+// false when the file ends in a partial or unreadable line; everything
+// before it is still returned.
+func readSegment(path, deviceID string, have int64) ([]model.Event, bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("open segment: %w", err)
+	}
+	defer file.Close()
+	out := make([]model.Event, 0)
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// A final line without a newline is a write still in flight.
+			return out, len(line) == 0, nil
+		}
+		var wire WireEvent
+		if err := json.Unmarshal(line, &wire); err != nil {
+			return out, false, nil
+		}
+		event, err := FromWire(wire)
+		if err != nil || event.DeviceID != deviceID {
+			return out, false, nil
+		}
+		if event.Seq <= have {
+			continue
+		}
+		out = append(out, event)
+		have = event.Seq
+	}
+}
@@ -0,0 +1,100 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"taskpp/core/model"
+)
+
+func folderEvents(deviceID string, from, to int64) []model.Event {
+	out := make([]model.Event, 0)
+	for seq := from; seq <= to; seq++ {
+		out = append(out, model.Event{
+			ID:       fmt.Sprintf("%s-%d", deviceID, seq),
+			DeviceID: deviceID,
+			Seq:      seq,
+			TS:       time.Date(2026, 3, 1, 10, int(seq), 0, 0, time.UTC),
+			Type:     "update",
+			Payload:  []byte{byte(seq)},
+		})
+	}
+	return out
+}
+
+func seqs(events []model.Event) []int64 {
+	out := make([]int64, 0, len(events))
+	for _, event := range events {
+		out = append(out, event.Seq)
+	}
+	return out
+}
+
+func TestFolderTransportPushPull(t *testing.T) {
+	dir := t.TempDir()
+	a := NewFolderTransport(dir, "a")
+	b := NewFolderTransport(dir, "b")
+
+	events := append(folderEvents("a", 1, 3), folderEvents("c", 1, 1)...)
+	if n, err := a.Push(events); err != nil || n != 3 {
+		t.Fatalf("push: %d %v", n, err)
+	}
+	if n, err := a.Push(events); err != nil || n != 0 {
+		t.Fatalf("expected nothing new to push, got %d %v", n, err)
+	}
+	if n, err := a.Push(folderEvents("a", 1, 4)); err != nil || n != 1 {
+		t.Fatalf("push tail: %d %v", n, err)
+	}
+
+	pulled, err := b.Pull(nil)
+	if err != nil {
+		t.Fatalf("pull: %v", err)
+	}
+	if got := seqs(pulled); len(got) != 4 || got[3] != 4 || string(pulled[0].Payload) != "\x01" {
+		t.Fatalf("unexpected pull: %v", got)
+	}
+	if pulled, _ = b.Pull(model.Cursor{"a": 2}); len(pulled) != 2 || pulled[0].Seq != 3 {
+		t.Fatalf("expected events past the cursor, got %v", seqs(pulled))
+	}
+	if pulled, _ = a.Pull(nil); len(pulled) != 0 {
+		t.Fatalf("expected a device to skip its own folder, got %v", seqs(pulled))
+	}
+}
+
+func TestFolderTransportToleratesPartialSegments(t *testing.T) {
+	dir := t.TempDir()
+	a := NewFolderTransport(dir, "a")
+	if _, err := a.Push(folderEvents("a", 1, 2)); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	own := filepath.Join(dir, "a")
+	// A segment still being copied: one full line and one cut short.
+	line, _ := json.Marshal(ToWire(folderEvents("a", 3, 3)[0]))
+	partial := append(append(line, '\n'), line[:10]...)
+	if err := os.WriteFile(filepath.Join(own, "2-4.seg"), partial, 0o600); err != nil {
+		t.Fatalf("write partial: %v", err)
+	}
+	// A segment past a gap and an interrupted temp file.
+	if err := os.WriteFile(filepath.Join(own, "5-6.seg"), []byte{}, 0o600); err != nil {
+		t.Fatalf("write gap: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(own, ".segment-1.tmp"), []byte("junk"), 0o600); err != nil {
+		t.Fatalf("write temp: %v", err)
+	}
+
+	b := NewFolderTransport(dir, "b")
+	pulled, err := b.Pull(nil)
+	if err != nil {
+		t.Fatalf("pull: %v", err)
+	}
+	if got := seqs(pulled); len(got) != 3 || got[2] != 3 {
+		t.Fatalf("expected events up to the cut, got %v", got)
+	}
+	if pulled, _ = b.Pull(model.Cursor{"a": 3}); len(pulled) != 0 {
+		t.Fatalf("expected to wait for the rest, got %v", seqs(pulled))
+	}
+}
@@ -0,0 +1,583 @@
+package sync
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	gosync "sync"
+	"time"
+
+	"taskpp/core/model"
+)
+
+// DefaultDiscoveryAddr is the multicast group LAN peers find each other on.
+const DefaultDiscoveryAddr = "239.255.77.77:7777"
+
+const (
+	lanProtocol     = "taskpp-lan/1"
+	lanMaxFrameSize = 64 << 20
+	// lanMaxHandshakeFrame caps the plaintext frames read before a peer
+	// has authenticated.
+	lanMaxHandshakeFrame = 4 << 10
+	lanTimeout           = 30 * time.Second
+)
+
+// PeerStore is the local side of a LAN session.
+type PeerStore interface {
+	// Cursor returns the highest seq held per device.
+	Cursor() (model.Cursor, error)
+	// EventsAfter returns the events the cursor does not cover.
+	EventsAfter(cursor model.Cursor) ([]model.Event, error)
+	// Import applies and logs events received from the peer.
+	Import(events []model.Event) error
+}
+
+// LANPeer is a device that answered a discovery query.
+type LANPeer struct {
+	DeviceID string
+	Addr     string
+}
+
+// LANResult reports one session: events sent to and received from the peer.
+type LANResult struct {
+	Sent     int
+	Received int
+}
+
+// LANServer accepts sync sessions from devices sharing the vault and
+// answers their discovery queries. Sessions run one at a time.
+type LANServer struct {
+	authKey  []byte
+	deviceID string
+	store    PeerStore
+
+	listener  net.Listener
+	discovery net.PacketConn
+	sessions  gosync.Mutex
+	wg        gosync.WaitGroup
+}
+
+// NewLANServer returns a server for deviceID. authKey is derived from the
+// vault key, so only devices of the same vault can discover or sync.
+func NewLANServer(authKey []byte, deviceID string, store PeerStore) *LANServer {
+	return &LANServer{authKey: authKey, deviceID: deviceID, store: store}
+}
+
+// Start listens for sessions on listenAddr and, unless discoveryAddr is
+// empty, for discovery queries on discoveryAddr (a multicast group or, for
+// tests, a unicast address).
+func (s *LANServer) Start(listenAddr, discoveryAddr string) error {
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	s.listener = listener
+	if discoveryAddr != "" {
+		conn, err := listenDiscovery(discoveryAddr)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		s.discovery = conn
+		s.wg.Add(1)
+		go s.answerQueries()
+	}
+	s.wg.Add(1)
+	go s.acceptSessions()
+	return nil
+}
+
+// Addr returns the address sessions are accepted on.
+func (s *LANServer) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// DiscoveryAddr returns the address discovery queries are read from.
+func (s *LANServer) DiscoveryAddr() string {
+	if s.discovery == nil {
+		return ""
+	}
+	return s.discovery.LocalAddr().String()
+}
+
+// Close stops the server and waits for a running session to finish.
+func (s *LANServer) Close() error {
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	if s.discovery != nil {
+		s.discovery.Close()
+	}
+	s.wg.Wait()
+	return err
+}
+
+func (s *LANServer) acceptSessions() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			// Only authenticated peers wait for their turn, so a stranger
+			// holding a socket open cannot stall sync.
+			_ = conn.SetDeadline(time.Now().Add(lanTimeout))
+			send, recv, err := acceptPeer(conn, s.authKey, s.deviceID)
+			if err != nil {
+				return
+			}
+			s.sessions.Lock()
+			defer s.sessions.Unlock()
+			_ = conn.SetDeadline(time.Now().Add(lanTimeout))
+			_, _ = serveSession(conn, send, recv, s.store)
+		}()
+	}
+}
+
+// SyncWithPeer runs one session with the LAN server at addr.
+func SyncWithPeer(addr string, authKey []byte, deviceID string, store PeerStore) (LANResult, error) {
+	conn, err := net.DialTimeout("tcp", addr, lanTimeout)
+	if err != nil {
+		return LANResult{}, fmt.Errorf("dial peer: %w", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(lanTimeout))
+	return clientSession(conn, authKey, deviceID, store)
+}
+
+type lanHello struct {
+	Protocol string `json:"protocol"`
+	DeviceID string `json:"device_id"`
+	Nonce    []byte `json:"nonce"`
+	Proof    []byte `json:"proof,omitempty"`
+	// Error is set instead of the rest when the server refuses a session.
+	Error string `json:"error,omitempty"`
+}
+
+type lanProof struct {
+	Proof []byte `json:"proof"`
+}
+
+type lanCursor struct {
+	Cursor model.Cursor `json:"cursor"`
+	Events []WireEvent  `json:"events,omitempty"`
+}
+
+type lanEvents struct {
+	Events []WireEvent `json:"events"`
+}
+
+type lanDone struct {
+	Received int `json:"received"`
+}
+
+// clientSession: hello, proofs, then the client sends its cursor, receives
+// the server's cursor with the events it lacks, and sends back what the
+// server lacks.
+func clientSession(conn net.Conn, authKey []byte, deviceID string, store PeerStore) (LANResult, error) {
+	nonce, err := randomNonce()
+	if err != nil {
+		return LANResult{}, err
+	}
+	hello := lanHello{Protocol: lanProtocol, DeviceID: deviceID, Nonce: nonce}
+	if err := writeJSONFrame(conn, nil, hello); err != nil {
+		return LANResult{}, err
+	}
+	var reply lanHello
+	if err := readJSONFrame(conn, nil, &reply); err != nil {
+		return LANResult{}, err
+	}
+	if reply.Error != "" {
+		return LANResult{}, fmt.Errorf("peer refused session: %s", reply.Error)
+	}
+	if reply.Protocol != lanProtocol {
+		return LANResult{}, fmt.Errorf("unsupported peer protocol: %s", reply.Protocol)
+	}
+	if reply.DeviceID == deviceID {
+		return LANResult{}, fmt.Errorf("cannot sync with this device")
+	}
+	transcript := [][]byte{nonce, reply.Nonce, []byte(deviceID), []byte(reply.DeviceID)}
+	if !hmac.Equal(reply.Proof, lanMAC(authKey, "server", transcript...)) {
+		return LANResult{}, fmt.Errorf("peer failed authentication")
+	}
+	if err := writeJSONFrame(conn, nil, lanProof{Proof: lanMAC(authKey, "client", transcript...)}); err != nil {
+		return LANResult{}, err
+	}
+	send, recv, err := sessionCiphers(authKey, nonce, reply.Nonce, true)
+	if err != nil {
+		return LANResult{}, err
+	}
+
+	cursor, err := store.Cursor()
+	if err != nil {
+		return LANResult{}, err
+	}
+	if err := writeJSONFrame(conn, send, lanCursor{Cursor: cursor}); err != nil {
+		return LANResult{}, err
+	}
+	var theirs lanCursor
+	if err := readJSONFrame(conn, recv, &theirs); err != nil {
+		return LANResult{}, err
+	}
+	received, err := fromWireEvents(theirs.Events)
+	if err != nil {
+		return LANResult{}, err
+	}
+	if err := store.Import(received); err != nil {
+		return LANResult{}, err
+	}
+	outgoing, err := store.EventsAfter(theirs.Cursor)
+	if err != nil {
+		return LANResult{}, err
+	}
+	if err := writeJSONFrame(conn, send, lanEvents{Events: toWireEvents(outgoing)}); err != nil {
+		return LANResult{}, err
+	}
+	var done lanDone
+	if err := readJSONFrame(conn, recv, &done); err != nil {
+		return LANResult{}, err
+	}
+	return LANResult{Sent: done.Received, Received: len(received)}, nil
+}
+
+// acceptPeer runs the server side of the hello and proofs, and returns the
+// session ciphers once the peer has proven it holds the vault key.
+func acceptPeer(conn net.Conn, authKey []byte, deviceID string) (*frameCipher, *frameCipher, error) {
+	var hello lanHello
+	if err := readJSONFrame(conn, nil, &hello); err != nil {
+		return nil, nil, err
+	}
+	refusal := ""
+	switch {
+	case hello.Protocol != lanProtocol:
+		refusal = "unsupported protocol"
+	case hello.DeviceID == deviceID:
+		refusal = "same device id"
+	case len(hello.Nonce) == 0:
+		refusal = "missing nonce"
+	}
+	if refusal != "" {
+		_ = writeJSONFrame(conn, nil, lanHello{Protocol: lanProtocol, Error: refusal})
+		return nil, nil, fmt.Errorf("invalid hello: %s", refusal)
+	}
+	nonce, err := randomNonce()
+	if err != nil {
+		return nil, nil, err
+	}
+	transcript := [][]byte{hello.Nonce, nonce, []byte(hello.DeviceID), []byte(deviceID)}
+	reply := lanHello{
+		Protocol: lanProtocol,
+		DeviceID: deviceID,
+		Nonce:    nonce,
+		Proof:    lanMAC(authKey, "server", transcript...),
+	}
+	if err := writeJSONFrame(conn, nil, reply); err != nil {
+		return nil, nil, err
+	}
+	var proof lanProof
+	if err := readJSONFrame(conn, nil, &proof); err != nil {
+		return nil, nil, err
+	}
+	if !hmac.Equal(proof.Proof, lanMAC(authKey, "client", transcript...)) {
+		return nil, nil, fmt.Errorf("peer failed authentication")
+	}
+	return sessionCiphers(authKey, hello.Nonce, nonce, false)
+}
+
+// serveSession exchanges events with an authenticated peer.
+func serveSession(conn net.Conn, send, recv *frameCipher, store PeerStore) (LANResult, error) {
+	var theirs lanCursor
+	if err := readJSONFrame(conn, recv, &theirs); err != nil {
+		return LANResult{}, err
+	}
+	cursor, err := store.Cursor()
+	if err != nil {
+		return LANResult{}, err
+	}
+	outgoing, err := store.EventsAfter(theirs.Cursor)
+	if err != nil {
+		return LANResult{}, err
+	}
+	if err := writeJSONFrame(conn, send, lanCursor{Cursor: cursor, Events: toWireEvents(outgoing)}); err != nil {
+		return LANResult{}, err
+	}
+	var incoming lanEvents
+	if err := readJSONFrame(conn, recv, &incoming); err != nil {
+		return LANResult{}, err
+	}
+	received, err := fromWireEvents(incoming.Events)
+	if err != nil {
+		return LANResult{}, err
+	}
+	if err := store.Import(received); err != nil {
+		return LANResult{}, err
+	}
+	if err := writeJSONFrame(conn, send, lanDone{Received: len(received)}); err != nil {
+		return LANResult{}, err
+	}
+	return LANResult{Sent: len(outgoing), Received: len(received)}, nil
+}
+
+type lanQuery struct {
+	Type     string `json:"type"`
+	Nonce    []byte `json:"nonce"`
+	DeviceID string `json:"device_id,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	Proof    []byte `json:"proof"`
+}
+
+// DiscoverPeers sends a discovery query to discoveryAddr and collects the
+// answers of devices sharing the vault until timeout.
+func DiscoverPeers(discoveryAddr string, authKey []byte, timeout time.Duration) ([]LANPeer, error) {
+	target, err := net.ResolveUDPAddr("udp4", discoveryAddr)
+	if err != nil {
+		return nil, fmt.Errorf("resolve discovery address: %w", err)
+	}
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+	defer conn.Close()
+	nonce, err := randomNonce()
+	if err != nil {
+		return nil, err
+	}
+	query, _ := json.Marshal(lanQuery{Type: "query", Nonce: nonce, Proof: lanMAC(authKey, "query", nonce)})
+	if _, err := conn.WriteTo(query, target); err != nil {
+		return nil, fmt.Errorf("send query: %w", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(timeout))
+
+	seen := make(map[string]bool)
+	out := make([]LANPeer, 0)
+	buf := make([]byte, 2048)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return out, nil
+			}
+			return nil, fmt.Errorf("read answers: %w", err)
+		}
+		var answer lanQuery
+		if json.Unmarshal(buf[:n], &answer) != nil || answer.Type != "answer" {
+			continue
+		}
+		port := strconv.Itoa(answer.Port)
+		if !hmac.Equal(answer.Proof, lanMAC(authKey, "answer", nonce, []byte(answer.DeviceID), []byte(port))) {
+			continue
+		}
+		udp, ok := from.(*net.UDPAddr)
+		if !ok || seen[answer.DeviceID] {
+			continue
+		}
+		seen[answer.DeviceID] = true
+		out = append(out, LANPeer{DeviceID: answer.DeviceID, Addr: net.JoinHostPort(udp.IP.String(), port)})
+	}
+}
+
+func (s *LANServer) answerQueries() {
+	defer s.wg.Done()
+	port := s.listener.Addr().(*net.TCPAddr).Port
+	buf := make([]byte, 2048)
+	for {
+		n, from, err := s.discovery.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		var query lanQuery
+		if json.Unmarshal(buf[:n], &query) != nil || query.Type != "query" {
+			continue
+		}
+		if !hmac.Equal(query.Proof, lanMAC(s.authKey, "query", query.Nonce)) {
+			continue
+		}
+		answer, _ := json.Marshal(lanQuery{
+			Type:     "answer",
+			Nonce:    query.Nonce,
+			DeviceID: s.deviceID,
+			Port:     port,
+			Proof:    lanMAC(s.authKey, "answer", query.Nonce, []byte(s.deviceID), []byte(strconv.Itoa(port))),
+		})
+		_, _ = s.discovery.WriteTo(answer, from)
+	}
+}
+
+func listenDiscovery(addr string) (net.PacketConn, error) {
+	udp, err := net.ResolveUDPAddr("udp4", addr)
+	if err != nil {
+		return nil, fmt.Errorf("resolve discovery address: %w", err)
+	}
+	if udp.IP.IsMulticast() {
+		conn, err := net.ListenMulticastUDP("udp4", nil, udp)
+		if err != nil {
+			return nil, fmt.Errorf("join discovery group: %w", err)
+		}
+		return conn, nil
+	}
+	conn, err := net.ListenUDP("udp4", udp)
+	if err != nil {
+		return nil, fmt.Errorf("listen for discovery: %w", err)
+	}
+	return conn, nil
+}
+
+// lanMAC authenticates a message of several parts; each part is length
+// prefixed so different splits never collide.
+func lanMAC(key []byte, label string, parts ...[]byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(lanProtocol + " " + label))
+	for _, part := range parts {
+		var size [4]byte
+		binary.BigEndian.PutUint32(size[:], uint32(len(part)))
+		mac.Write(size[:])
+		mac.Write(part)
+	}
+	return mac.Sum(nil)
+}
+
+func randomNonce() ([]byte, error) {
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("nonce: %w", err)
+	}
+	return nonce, nil
+}
+
+// frameCipher seals frames of one direction with a counter nonce.
+type frameCipher struct {
+	aead    cipher.AEAD
+	counter uint64
+}
+
+func (f *frameCipher) nonce() []byte {
+	nonce := make([]byte, f.aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], f.counter)
+	f.counter++
+	return nonce
+}
+
+// sessionCiphers derives one key per direction from both nonces.
+func sessionCiphers(authKey, clientNonce, serverNonce []byte, client bool) (*frameCipher, *frameCipher, error) {
+	salt := append(append([]byte(nil), clientNonce...), serverNonce...)
+	keys, err := hkdf.Key(sha256.New, authKey, salt, lanProtocol+" session", 64)
+	if err != nil {
+		return nil, nil, fmt.Errorf("derive session keys: %w", err)
+	}
+	toServer, err := newFrameCipher(keys[:32])
+	if err != nil {
+		return nil, nil, err
+	}
+	toClient, err := newFrameCipher(keys[32:])
+	if err != nil {
+		return nil, nil, err
+	}
+	if client {
+		return toServer, toClient, nil
+	}
+	return toClient, toServer, nil
+}
+
+func newFrameCipher(key []byte) (*frameCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("session cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("session cipher: %w", err)
+	}
+	return &frameCipher{aead: aead}, nil
+}
+
+// writeJSONFrame writes a length-prefixed JSON frame, sealed when sealer is
+// set.
+func writeJSONFrame(w io.Writer, sealer *frameCipher, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode frame: %w", err)
+	}
+	if sealer != nil {
+		data = sealer.aead.Seal(nil, sealer.nonce(), data, nil)
+	}
+	if len(data) > lanMaxFrameSize {
+		return fmt.Errorf("frame too large")
+	}
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(data)))
+	if _, err := w.Write(append(size[:], data...)); err != nil {
+		return fmt.Errorf("write frame: %w", err)
+	}
+	return nil
+}
+
+// readJSONFrame reads a frame written by writeJSONFrame. Plaintext frames
+// only carry the handshake and are capped at lanMaxHandshakeFrame.
+func readJSONFrame(r io.Reader, opener *frameCipher, v any) error {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return fmt.Errorf("read frame: %w", err)
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	limit := uint32(lanMaxFrameSize)
+	if opener == nil {
+		limit = lanMaxHandshakeFrame
+	}
+	if n > limit {
+		return fmt.Errorf("frame too large")
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return fmt.Errorf("read frame: %w", err)
+	}
+	if opener != nil {
+		plain, err := opener.aead.Open(nil, opener.nonce(), data, nil)
+		if err != nil {
+			return fmt.Errorf("open frame: %w", err)
+		}
+		data = plain
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decode frame: %w", err)
+	}
+	return nil
+}
+
+func toWireEvents(events []model.Event) []WireEvent {
+	out := make([]WireEvent, 0, len(events))
+	for _, event := range events {
+		out = append(out, ToWire(event))
+	}
+	return out
+}
+
+func fromWireEvents(wire []WireEvent) ([]model.Event, error) {
+	out := make([]model.Event, 0, len(wire))
+	for _, item := range wire {
+		event, err := FromWire(item)
+		if err != nil {
+			return nil, fmt.Errorf("decode event: %w", err)
+		}
+		out = append(out, event)
+	}
+	return out, nil
+}
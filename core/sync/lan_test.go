@@ -0,0 +1,143 @@
+package sync
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"taskpp/core/model"
+)
+
+// memoryPeer is an in-memory PeerStore.
+type memoryPeer struct {
+	mu     gosync.Mutex
+	events []model.Event
+}
+
+func (m *memoryPeer) Cursor() (model.Cursor, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return CursorOf(nil, m.events), nil
+}
+
+func (m *memoryPeer) EventsAfter(cursor model.Cursor) ([]model.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]model.Event, 0)
+	for _, event := range m.events {
+		if !Covers(cursor, event) {
+			out = append(out, event)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryPeer) Import(events []model.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, events...)
+	return nil
+}
+
+func TestLANSessionExchangesMissingEvents(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	server := &memoryPeer{events: append(folderEvents("a", 1, 3), folderEvents("c", 1, 1)...)}
+	client := &memoryPeer{events: append(folderEvents("b", 1, 2), folderEvents("a", 1, 1)...)}
+
+	lan := NewLANServer(key, "a", server)
+	if err := lan.Start("127.0.0.1:0", "127.0.0.1:0"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer lan.Close()
+
+	peers, err := DiscoverPeers(lan.DiscoveryAddr(), key, 500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if len(peers) != 1 || peers[0].DeviceID != "a" || peers[0].Addr != lan.Addr() {
+		t.Fatalf("unexpected peers: %+v (server at %s)", peers, lan.Addr())
+	}
+	if peers, _ := DiscoverPeers(lan.DiscoveryAddr(), bytes.Repeat([]byte{8}, 32), 200*time.Millisecond); len(peers) != 0 {
+		t.Fatalf("expected another vault to get no answer, got %+v", peers)
+	}
+
+	result, err := SyncWithPeer(peers[0].Addr, key, "b", client)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if result.Received != 3 || result.Sent != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	serverCursor, _ := server.Cursor()
+	clientCursor, _ := client.Cursor()
+	want := model.Cursor{"a": 3, "b": 2, "c": 1}
+	for device, seq := range want {
+		if serverCursor[device] != seq || clientCursor[device] != seq {
+			t.Fatalf("expected both at %v, got %v and %v", want, serverCursor, clientCursor)
+		}
+	}
+	if result, err := SyncWithPeer(lan.Addr(), key, "b", client); err != nil || result != (LANResult{}) {
+		t.Fatalf("expected nothing left to exchange, got %+v %v", result, err)
+	}
+}
+
+func TestLANSessionRejectsOtherVaults(t *testing.T) {
+	server := &memoryPeer{events: folderEvents("a", 1, 1)}
+	lan := NewLANServer(bytes.Repeat([]byte{1}, 32), "a", server)
+	if err := lan.Start("127.0.0.1:0", ""); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer lan.Close()
+
+	client := &memoryPeer{}
+	if _, err := SyncWithPeer(lan.Addr(), bytes.Repeat([]byte{2}, 32), "b", client); err == nil {
+		t.Fatalf("expected authentication to fail")
+	}
+	if len(client.events) != 0 {
+		t.Fatalf("expected no events to leak, got %d", len(client.events))
+	}
+}
+
+func TestLANSessionIgnoresSilentStrangers(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	server := &memoryPeer{events: folderEvents("a", 1, 2)}
+	lan := NewLANServer(key, "a", server)
+	if err := lan.Start("127.0.0.1:0", ""); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer lan.Close()
+
+	// A stranger connects and never speaks.
+	stranger, err := net.Dial("tcp", lan.Addr())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer stranger.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := SyncWithPeer(lan.Addr(), key, "b", &memoryPeer{})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("sync: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected a silent connection not to block other peers")
+	}
+}
+
+func TestLANSessionCapsHandshakeFrames(t *testing.T) {
+	var frame bytes.Buffer
+	_ = binary.Write(&frame, binary.BigEndian, uint32(lanMaxHandshakeFrame+1))
+	frame.Write(make([]byte, lanMaxHandshakeFrame+1))
+	var hello lanHello
+	if err := readJSONFrame(&frame, nil, &hello); err == nil {
+		t.Fatalf("expected an oversized handshake frame to be rejected")
+	}
+}
@@ -0,0 +1,372 @@
+package sync
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"strings"
+	gosync "sync"
+	"time"
+
+	"taskpp/core/crypto"
+)
+
+const (
+	pairProtocol = "taskpp-pair/1"
+	pairScheme   = "taskpp-pair"
+	// PairingCodeDigits is the length of a pairing code.
+	PairingCodeDigits = 8
+	// PairingTimeout is how long a pairing code stays valid.
+	PairingTimeout = 5 * time.Minute
+)
+
+// PairingJoiner describes the device that asks to join the vault.
+type PairingJoiner struct {
+	DeviceID string `json:"device_id"`
+	Name     string `json:"name"`
+	Platform string `json:"platform"`
+}
+
+// GrantFunc builds what a joiner receives once the code checked out.
+// wrapKey is a key only the joiner shares, for sealing the vault key.
+type GrantFunc func(joiner PairingJoiner, wrapKey []byte) ([]byte, error)
+
+// PairingHost waits for one device to join with a pairing code. The code is
+// used up by the first session that gets past the key exchange, whether or
+// not the code matched, so it cannot be guessed online; the host also stops
+// after PairingTimeout.
+type PairingHost struct {
+	code     string
+	deviceID string
+	grant    GrantFunc
+	listener net.Listener
+	expires  time.Time
+	timer    *time.Timer
+
+	mu     gosync.Mutex
+	done   chan struct{}
+	joiner PairingJoiner
+	err    error
+	wg     gosync.WaitGroup
+}
+
+// NewPairingCode returns a random numeric pairing code.
+func NewPairingCode() (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(PairingCodeDigits), nil)
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", fmt.Errorf("pairing code: %w", err)
+	}
+	return fmt.Sprintf("%0*d", PairingCodeDigits, n), nil
+}
+
+// FormatPairingCode groups a code for display, e.g. "1234-5678".
+func FormatPairingCode(code string) string {
+	half := len(code) / 2
+	return code[:half] + "-" + code[half:]
+}
+
+// PairingPayload returns the string a QR code carries: the host address and
+// the code, e.g. "taskpp-pair://192.168.1.20:41234?code=12345678".
+func PairingPayload(addr, code string) string {
+	return (&url.URL{Scheme: pairScheme, Host: addr, RawQuery: url.Values{"code": {code}}.Encode()}).String()
+}
+
+// ParsePairingPayload splits a pairing payload into address and code.
+// Separators in the code, as in a typed "1234-5678", are ignored.
+func ParsePairingPayload(payload string) (string, string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(payload))
+	if err != nil || parsed.Scheme != pairScheme || parsed.Host == "" {
+		return "", "", fmt.Errorf("invalid pairing payload")
+	}
+	code, err := normalizePairingCode(parsed.Query().Get("code"))
+	if err != nil {
+		return "", "", err
+	}
+	return parsed.Host, code, nil
+}
+
+func normalizePairingCode(code string) (string, error) {
+	digits := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+	if len(digits) != PairingCodeDigits || strings.Trim(digits, "0123456789") != "" {
+		return "", fmt.Errorf("invalid pairing code")
+	}
+	return digits, nil
+}
+
+// StartPairingHost listens on listenAddr for the device that knows code.
+func StartPairingHost(listenAddr, code, deviceID string, grant GrantFunc) (*PairingHost, error) {
+	code, err := normalizePairingCode(code)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+	h := &PairingHost{
+		code:     code,
+		deviceID: deviceID,
+		grant:    grant,
+		listener: listener,
+		expires:  time.Now().Add(PairingTimeout),
+		done:     make(chan struct{}),
+	}
+	h.timer = time.AfterFunc(PairingTimeout, func() { h.finish(PairingJoiner{}, fmt.Errorf("pairing code expired")) })
+	h.wg.Add(1)
+	go h.accept()
+	return h, nil
+}
+
+// Addr returns an address other devices on the network can reach the host
+// on. A host listening on all interfaces reports its first private IPv4.
+func (h *PairingHost) Addr() string {
+	tcp, ok := h.listener.Addr().(*net.TCPAddr)
+	if !ok || !tcp.IP.IsUnspecified() {
+		return h.listener.Addr().String()
+	}
+	return net.JoinHostPort(lanHost(), fmt.Sprint(tcp.Port))
+}
+
+// Code returns the pairing code.
+func (h *PairingHost) Code() string { return h.code }
+
+// Expires returns when the code stops being accepted.
+func (h *PairingHost) Expires() time.Time { return h.expires }
+
+// Done is closed once pairing succeeded, failed or expired.
+func (h *PairingHost) Done() <-chan struct{} { return h.done }
+
+// Result returns the joined device, or why pairing ended without one. It is
+// only meaningful after Done is closed.
+func (h *PairingHost) Result() (PairingJoiner, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.joiner, h.err
+}
+
+// Close cancels a pending pairing and waits for a running session.
+func (h *PairingHost) Close() error {
+	h.finish(PairingJoiner{}, fmt.Errorf("pairing cancelled"))
+	h.wg.Wait()
+	return nil
+}
+
+// finish records the first outcome and stops listening.
+func (h *PairingHost) finish(joiner PairingJoiner, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	select {
+	case <-h.done:
+		return
+	default:
+	}
+	h.joiner, h.err = joiner, err
+	h.timer.Stop()
+	h.listener.Close()
+	close(h.done)
+}
+
+func (h *PairingHost) accept() {
+	defer h.wg.Done()
+	for {
+		conn, err := h.listener.Accept()
+		if err != nil {
+			return
+		}
+		_ = conn.SetDeadline(time.Now().Add(lanTimeout))
+		burned, joiner, err := h.serve(conn)
+		conn.Close()
+		if burned {
+			h.finish(joiner, err)
+			return
+		}
+	}
+}
+
+type pairHello struct {
+	Protocol string `json:"protocol"`
+	DeviceID string `json:"device_id"`
+	Message  []byte `json:"message"`
+	Proof    []byte `json:"proof,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type pairGrant struct {
+	Grant []byte `json:"grant"`
+}
+
+// serve runs one session. burned reports whether the session got as far as
+// the key exchange and so used up the code.
+func (h *PairingHost) serve(conn net.Conn) (bool, PairingJoiner, error) {
+	var hello pairHello
+	if err := readJSONFrame(conn, nil, &hello); err != nil {
+		return false, PairingJoiner{}, err
+	}
+	if hello.Protocol != pairProtocol || hello.DeviceID == "" || hello.DeviceID == h.deviceID {
+		_ = writeJSONFrame(conn, nil, pairHello{Protocol: pairProtocol, Error: "invalid hello"})
+		return false, PairingJoiner{}, fmt.Errorf("invalid hello")
+	}
+	pake, err := crypto.NewPAKE(h.code, false)
+	if err != nil {
+		return false, PairingJoiner{}, err
+	}
+	key, err := pake.SharedKey(hello.Message)
+	if err != nil {
+		_ = writeJSONFrame(conn, nil, pairHello{Protocol: pairProtocol, Error: err.Error()})
+		return false, PairingJoiner{}, err
+	}
+	transcript := [][]byte{hello.Message, pake.Message(), []byte(hello.DeviceID), []byte(h.deviceID)}
+	reply := pairHello{
+		Protocol: pairProtocol,
+		DeviceID: h.deviceID,
+		Message:  pake.Message(),
+		Proof:    pairMAC(key, "host", transcript...),
+	}
+	if err := writeJSONFrame(conn, nil, reply); err != nil {
+		return true, PairingJoiner{}, err
+	}
+	var proof lanProof
+	if err := readJSONFrame(conn, nil, &proof); err != nil {
+		return true, PairingJoiner{}, err
+	}
+	if !hmac.Equal(proof.Proof, pairMAC(key, "joiner", transcript...)) {
+		return true, PairingJoiner{}, fmt.Errorf("wrong pairing code")
+	}
+	send, recv, err := sessionCiphers(key, hello.Message, pake.Message(), false)
+	if err != nil {
+		return true, PairingJoiner{}, err
+	}
+	var joiner PairingJoiner
+	if err := readJSONFrame(conn, recv, &joiner); err != nil {
+		return true, PairingJoiner{}, err
+	}
+	joiner.DeviceID = hello.DeviceID
+	wrapKey, err := pairWrapKey(key)
+	if err != nil {
+		return true, PairingJoiner{}, err
+	}
+	grant, err := h.grant(joiner, wrapKey)
+	if err != nil {
+		return true, PairingJoiner{}, err
+	}
+	if err := writeJSONFrame(conn, send, pairGrant{Grant: grant}); err != nil {
+		return true, PairingJoiner{}, err
+	}
+	var done lanDone
+	if err := readJSONFrame(conn, recv, &done); err != nil {
+		return true, PairingJoiner{}, err
+	}
+	// Record the outcome before the last frame, so the joiner returns only
+	// once the host reports it.
+	h.finish(joiner, nil)
+	_ = writeJSONFrame(conn, send, lanDone{Received: done.Received})
+	return true, joiner, nil
+}
+
+// JoinPairing runs the joining side against the host at addr. It returns
+// the grant and the key the vault key in it is wrapped with.
+func JoinPairing(addr, code string, joiner PairingJoiner) ([]byte, []byte, error) {
+	code, err := normalizePairingCode(code)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := net.DialTimeout("tcp", addr, lanTimeout)
+	if err != nil {
+		return nil, nil, fmt.Errorf("dial host: %w", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(lanTimeout))
+
+	pake, err := crypto.NewPAKE(code, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	hello := pairHello{Protocol: pairProtocol, DeviceID: joiner.DeviceID, Message: pake.Message()}
+	if err := writeJSONFrame(conn, nil, hello); err != nil {
+		return nil, nil, err
+	}
+	var reply pairHello
+	if err := readJSONFrame(conn, nil, &reply); err != nil {
+		return nil, nil, err
+	}
+	if reply.Error != "" {
+		return nil, nil, fmt.Errorf("host refused pairing: %s", reply.Error)
+	}
+	if reply.Protocol != pairProtocol {
+		return nil, nil, fmt.Errorf("unsupported host protocol: %s", reply.Protocol)
+	}
+	key, err := pake.SharedKey(reply.Message)
+	if err != nil {
+		return nil, nil, err
+	}
+	transcript := [][]byte{pake.Message(), reply.Message, []byte(joiner.DeviceID), []byte(reply.DeviceID)}
+	if !hmac.Equal(reply.Proof, pairMAC(key, "host", transcript...)) {
+		// An empty proof tells the host the code did not match.
+		_ = writeJSONFrame(conn, nil, lanProof{})
+		return nil, nil, fmt.Errorf("wrong pairing code")
+	}
+	if err := writeJSONFrame(conn, nil, lanProof{Proof: pairMAC(key, "joiner", transcript...)}); err != nil {
+		return nil, nil, err
+	}
+	send, recv, err := sessionCiphers(key, pake.Message(), reply.Message, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := writeJSONFrame(conn, send, joiner); err != nil {
+		return nil, nil, err
+	}
+	var grant pairGrant
+	if err := readJSONFrame(conn, recv, &grant); err != nil {
+		return nil, nil, err
+	}
+	if err := writeJSONFrame(conn, send, lanDone{Received: 1}); err != nil {
+		return nil, nil, err
+	}
+	var done lanDone
+	if err := readJSONFrame(conn, recv, &done); err != nil {
+		return nil, nil, err
+	}
+	wrapKey, err := pairWrapKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return grant.Grant, wrapKey, nil
+}
+
+func pairMAC(key []byte, label string, parts ...[]byte) []byte {
+	return lanMAC(key, "pair "+label, parts...)
+}
+
+func pairWrapKey(key []byte) ([]byte, error) {
+	wrapKey, err := hkdf.Key(sha256.New, key, nil, pairProtocol+" wrap", 32)
+	if err != nil {
+		return nil, fmt.Errorf("derive wrap key: %w", err)
+	}
+	return wrapKey, nil
+}
+
+// lanHost returns the first private IPv4 address of this machine, or the
+// loopback address when there is none.
+func lanHost() string {
+	addrs, err := net.InterfaceAddrs()
+	if err == nil {
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if ok && ipNet.IP.To4() != nil && ipNet.IP.IsPrivate() {
+				return ipNet.IP.String()
+			}
+		}
+	}
+	return "127.0.0.1"
+}
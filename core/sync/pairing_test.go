@@ -0,0 +1,75 @@
+package sync
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPairingPayloadRoundTrip(t *testing.T) {
+	payload := PairingPayload("192.168.1.20:41234", "12345678")
+	addr, code, err := ParsePairingPayload(payload)
+	if err != nil || addr != "192.168.1.20:41234" || code != "12345678" {
+		t.Fatalf("unexpected parse of %s: %s %s %v", payload, addr, code, err)
+	}
+	if _, code, _ := ParsePairingPayload("taskpp-pair://host:1?code=1234-5678"); code != "12345678" {
+		t.Fatalf("expected a typed code to be normalized, got %q", code)
+	}
+	for _, bad := range []string{"", "http://host:1?code=12345678", "taskpp-pair://host:1?code=123", "taskpp-pair://host:1?code=abcdefgh"} {
+		if _, _, err := ParsePairingPayload(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestPairingHandsOverGrant(t *testing.T) {
+	var hostWrapKey []byte
+	grant := func(joiner PairingJoiner, wrapKey []byte) ([]byte, error) {
+		hostWrapKey = wrapKey
+		return []byte("grant for " + joiner.Name), nil
+	}
+	host, err := StartPairingHost("127.0.0.1:0", "1234-5678", "host", grant)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer host.Close()
+
+	joiner := PairingJoiner{DeviceID: "joiner", Name: "Tablet", Platform: "ios"}
+	got, wrapKey, err := JoinPairing(host.Addr(), host.Code(), joiner)
+	if err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	<-host.Done()
+	if string(got) != "grant for Tablet" || len(wrapKey) != 32 || !bytes.Equal(wrapKey, hostWrapKey) {
+		t.Fatalf("unexpected grant %q or wrap key", got)
+	}
+	if paired, err := host.Result(); err != nil || paired != joiner {
+		t.Fatalf("unexpected result: %+v %v", paired, err)
+	}
+	if _, _, err := JoinPairing(host.Addr(), host.Code(), joiner); err == nil {
+		t.Fatalf("expected a used code to be refused")
+	}
+}
+
+func TestPairingWrongCodeBurnsCode(t *testing.T) {
+	granted := false
+	host, err := StartPairingHost("127.0.0.1:0", "12345678", "host", func(PairingJoiner, []byte) ([]byte, error) {
+		granted = true
+		return []byte("grant"), nil
+	})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer host.Close()
+
+	joiner := PairingJoiner{DeviceID: "joiner"}
+	if _, _, err := JoinPairing(host.Addr(), "87654321", joiner); err == nil {
+		t.Fatalf("expected a wrong code to fail")
+	}
+	<-host.Done()
+	if _, err := host.Result(); err == nil || err.Error() != "wrong pairing code" || granted {
+		t.Fatalf("expected pairing to end without a grant, got %v", err)
+	}
+	if _, _, err := JoinPairing(host.Addr(), "12345678", joiner); err == nil {
+		t.Fatalf("expected the code to be used up")
+	}
+}
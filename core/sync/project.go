@@ -0,0 +1,55 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"taskpp/core/model"
+)
+
+// ApplyProjectEvent applies a project event using the same LWW rules as tasks.
+// Returns (updatedProject, changed, conflict, error).
+func ApplyProjectEvent(project model.Project, event model.Event) (model.Project, bool, bool, error) {
+	var payload ProjectDTO
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return model.Project{}, false, false, fmt.Errorf("decode project payload: %w", err)
+	}
+	evtTime, err := time.Parse(time.RFC3339Nano, payload.UpdatedAt)
+	if err != nil && payload.UpdatedAt != "" {
+		return model.Project{}, false, false, fmt.Errorf("parse updated_at: %w", err)
+	}
+	if payload.UpdatedAt == "" {
+		evtTime = event.TS
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, payload.CreatedAt)
+	if err != nil && payload.CreatedAt != "" {
+		return model.Project{}, false, false, fmt.Errorf("parse created_at: %w", err)
+	}
+
+	updated := model.Project{
+		ID:        payload.ID,
+		Name:      payload.Name,
+		Color:     payload.Color,
+		Order:     payload.Order,
+		Archived:  payload.Archived,
+		CreatedAt: createdAt,
+		UpdatedAt: evtTime,
+	}
+	changed, conflict := resolveLWW(project.ID != "", project.UpdatedAt, updated.UpdatedAt, event.Seq)
+	if changed {
+		return updated, true, false, nil
+	}
+	return project, false, conflict, nil
+}
+
+// ProjectDTO mirrors bind.ProjectDTO without imports to avoid dependency cycles.
+type ProjectDTO struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	Order     int64  `json:"order"`
+	Archived  bool   `json:"archived"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"taskpp/core/logic"
 	"taskpp/core/model"
 )
 
@@ -37,47 +38,326 @@ func ApplyEvent(task model.Task, event model.Event) (model.Task, bool, bool, err
 	if err != nil && payload.DueDate != "" {
 		return model.Task{}, false, false, fmt.Errorf("parse due_date: %w", err)
 	}
+	startDate, err := time.Parse("2006-01-02", payload.StartDate)
+	if err != nil && payload.StartDate != "" {
+		return model.Task{}, false, false, fmt.Errorf("parse start_date: %w", err)
+	}
+	reminders, err := parseReminders(payload.Reminders)
+	if err != nil {
+		return model.Task{}, false, false, err
+	}
+	checklist, err := parseChecklist(payload.Checklist)
+	if err != nil {
+		return model.Task{}, false, false, err
+	}
+	removedAt, err := parseRemovedAt(payload.ChecklistRemovedAt)
+	if err != nil {
+		return model.Task{}, false, false, err
+	}
+	attachments, err := parseAttachments(payload.Attachments)
+	if err != nil {
+		return model.Task{}, false, false, err
+	}
+	occurrenceDate, err := time.Parse("2006-01-02", payload.OccurrenceDate)
+	if err != nil && payload.OccurrenceDate != "" {
+		return model.Task{}, false, false, fmt.Errorf("parse occurrence_date: %w", err)
+	}
+	exceptions, err := parseExceptions(payload.Exceptions)
+	if err != nil {
+		return model.Task{}, false, false, err
+	}
 
 	updated := model.Task{
 		ID:          payload.ID,
 		Title:       payload.Title,
 		Description: payload.Description,
 		Status:      payload.Status,
+		State:       payload.State,
 		Priority:    payload.Priority,
 		DueDate:     dueDate,
+		StartDate:   startDate,
+		DueTime:     payload.DueTime,
+		TimeZone:    payload.TimeZone,
 		Order:       payload.Order,
 		CreatedAt:   createdAt,
 		UpdatedAt:   evtTime,
 		CompletedAt: completedAt,
 		Archived:    payload.Archived,
+		ProjectID:   payload.ProjectID,
+		ParentID:    payload.ParentID,
+		Tags:        payload.Tags,
+		Recurrence:  payload.Recurrence,
+		SeriesID:    payload.SeriesID,
+		Reminders:   reminders,
+		BlockedBy:   payload.BlockedBy,
+
+		Checklist:          checklist,
+		ChecklistRemoved:   payload.ChecklistRemoved,
+		ChecklistRemovedAt: removedAt,
+		Attachments:        attachments,
+		Links:              parseLinks(payload.Links),
+		EstimateMinutes:    payload.EstimateMinutes,
+		OccurrenceDate:     occurrenceDate,
+		Exceptions:         exceptions,
+		Rank:               payload.Rank,
 	}
 
+	changed, conflict := resolveLWW(existing.ID != "", existing.UpdatedAt, updated.UpdatedAt, event.Seq)
 	if existing.ID == "" {
-		return updated, true, false, nil
+		return updated, changed, conflict, nil
 	}
-	if updated.UpdatedAt.After(existing.UpdatedAt) {
+	// Checklists merge item by item regardless of which copy wins, so two
+	// devices ticking different items keep both edits.
+	merged, removed, removedAt := logic.MergeChecklist(existing, updated)
+	if changed {
+		updated.Checklist, updated.ChecklistRemoved, updated.ChecklistRemovedAt = merged, removed, removedAt
 		return updated, true, false, nil
 	}
-	if updated.UpdatedAt.Equal(existing.UpdatedAt) && event.Seq > 0 {
-		return updated, true, false, nil
+	if !sameChecklist(existing.Checklist, merged) || len(existing.ChecklistRemoved) != len(removed) {
+		existing.Checklist, existing.ChecklistRemoved, existing.ChecklistRemovedAt = merged, removed, removedAt
+		return existing, true, conflict, nil
+	}
+	return existing, false, conflict, nil
+}
+
+func sameChecklist(a, b []model.ChecklistItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	byID := make(map[string]model.ChecklistItem, len(a))
+	for _, item := range a {
+		byID[item.ID] = item
 	}
-	if updated.UpdatedAt.Before(existing.UpdatedAt) {
-		return existing, false, true, nil
+	for _, item := range b {
+		current, ok := byID[item.ID]
+		if !ok || !current.UpdatedAt.Equal(item.UpdatedAt) || !current.OrderedAt.Equal(item.OrderedAt) || !current.AddedAt.Equal(item.AddedAt) {
+			return false
+		}
 	}
-	return existing, false, false, nil
+	return true
+}
+
+// resolveLWW decides whether an incoming version replaces the existing one.
+// Returns (incomingWins, conflict).
+func resolveLWW(exists bool, existing, incoming time.Time, seq int64) (bool, bool) {
+	if !exists {
+		return true, false
+	}
+	if incoming.After(existing) {
+		return true, false
+	}
+	if incoming.Equal(existing) && seq > 0 {
+		return true, false
+	}
+	if incoming.Before(existing) {
+		return false, true
+	}
+	return false, false
 }
 
 // TaskDTO mirrors bind.TaskDTO without imports to avoid dependency cycles.
 type TaskDTO struct {
-	ID          string `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
-	Priority    string `json:"priority"`
-	DueDate     string `json:"due_date"`
-	Order       int64  `json:"order"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
-	CompletedAt string `json:"completed_at"`
-	Archived    bool   `json:"archived"`
+	ID          string        `json:"id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Status      string        `json:"status"`
+	State       string        `json:"state"`
+	Priority    string        `json:"priority"`
+	DueDate     string        `json:"due_date"`
+	StartDate   string        `json:"start_date"`
+	DueTime     string        `json:"due_time"`
+	TimeZone    string        `json:"time_zone"`
+	Order       int64         `json:"order"`
+	CreatedAt   string        `json:"created_at"`
+	UpdatedAt   string        `json:"updated_at"`
+	CompletedAt string        `json:"completed_at"`
+	Archived    bool          `json:"archived"`
+	ProjectID   string        `json:"project_id"`
+	ParentID    string        `json:"parent_id"`
+	Tags        []string      `json:"tags"`
+	Recurrence  string        `json:"recurrence"`
+	SeriesID    string        `json:"series_id"`
+	Reminders   []ReminderDTO `json:"reminders"`
+	BlockedBy   []string      `json:"blocked_by"`
+
+	Checklist          []ChecklistItemDTO `json:"checklist"`
+	ChecklistRemoved   []string           `json:"checklist_removed"`
+	ChecklistRemovedAt map[string]string  `json:"checklist_removed_at"`
+	Attachments        []AttachmentDTO    `json:"attachments"`
+	Links              []LinkDTO          `json:"links"`
+	EstimateMinutes    int64              `json:"estimate_minutes"`
+	OccurrenceDate     string             `json:"occurrence_date"`
+	Exceptions         []ExceptionDTO     `json:"recurrence_exceptions"`
+	Rank               string             `json:"rank"`
+}
+
+// ExceptionDTO mirrors bind.ExceptionDTO.
+type ExceptionDTO struct {
+	Date    string `json:"date"`
+	MoveTo  string `json:"move_to"`
+	DueTime string `json:"due_time"`
+}
+
+func parseExceptions(dtos []ExceptionDTO) ([]model.RecurrenceException, error) {
+	if len(dtos) == 0 {
+		return nil, nil
+	}
+	out := make([]model.RecurrenceException, 0, len(dtos))
+	for _, dto := range dtos {
+		date, err := time.Parse("2006-01-02", dto.Date)
+		if err != nil {
+			return nil, fmt.Errorf("parse exception date: %w", err)
+		}
+		moveTo, err := time.Parse("2006-01-02", dto.MoveTo)
+		if err != nil {
+			return nil, fmt.Errorf("parse exception move_to: %w", err)
+		}
+		out = append(out, model.RecurrenceException{Date: date, MoveTo: moveTo, DueTime: dto.DueTime})
+	}
+	return out, nil
+}
+
+// LinkDTO mirrors bind.LinkDTO.
+type LinkDTO struct {
+	Type  string `json:"type"`
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+func parseLinks(dtos []LinkDTO) []model.Link {
+	if len(dtos) == 0 {
+		return nil
+	}
+	out := make([]model.Link, 0, len(dtos))
+	for _, dto := range dtos {
+		out = append(out, model.Link{Type: dto.Type, Label: dto.Label, Value: dto.Value})
+	}
+	return out
+}
+
+// AttachmentDTO mirrors bind.AttachmentDTO.
+type AttachmentDTO struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+	Hash     string `json:"hash"`
+	AddedAt  string `json:"added_at"`
+}
+
+func parseAttachments(dtos []AttachmentDTO) ([]model.Attachment, error) {
+	if len(dtos) == 0 {
+		return nil, nil
+	}
+	out := make([]model.Attachment, 0, len(dtos))
+	for _, dto := range dtos {
+		addedAt, err := time.Parse(time.RFC3339Nano, dto.AddedAt)
+		if err != nil && dto.AddedAt != "" {
+			return nil, fmt.Errorf("parse attachment %s: %w", dto.ID, err)
+		}
+		out = append(out, model.Attachment{
+			ID:       dto.ID,
+			Name:     dto.Name,
+			MimeType: dto.MimeType,
+			Size:     dto.Size,
+			Hash:     dto.Hash,
+			AddedAt:  addedAt,
+		})
+	}
+	return out, nil
+}
+
+// ChecklistItemDTO mirrors bind.ChecklistItemDTO.
+type ChecklistItemDTO struct {
+	ID        string `json:"id"`
+	Text      string `json:"text"`
+	Checked   bool   `json:"checked"`
+	Order     int64  `json:"order"`
+	UpdatedAt string `json:"updated_at"`
+	OrderedAt string `json:"ordered_at"`
+	AddedAt   string `json:"added_at"`
+}
+
+func parseChecklist(dtos []ChecklistItemDTO) ([]model.ChecklistItem, error) {
+	if len(dtos) == 0 {
+		return nil, nil
+	}
+	out := make([]model.ChecklistItem, 0, len(dtos))
+	for _, dto := range dtos {
+		updatedAt, err := time.Parse(time.RFC3339Nano, dto.UpdatedAt)
+		if err != nil && dto.UpdatedAt != "" {
+			return nil, fmt.Errorf("parse checklist item %s: %w", dto.ID, err)
+		}
+		orderedAt, err := time.Parse(time.RFC3339Nano, dto.OrderedAt)
+		if err != nil && dto.OrderedAt != "" {
+			return nil, fmt.Errorf("parse checklist item %s: %w", dto.ID, err)
+		}
+		addedAt, err := time.Parse(time.RFC3339Nano, dto.AddedAt)
+		if err != nil && dto.AddedAt != "" {
+			return nil, fmt.Errorf("parse checklist item %s: %w", dto.ID, err)
+		}
+		out = append(out, model.ChecklistItem{
+			ID:        dto.ID,
+			Text:      dto.Text,
+			Checked:   dto.Checked,
+			Order:     dto.Order,
+			UpdatedAt: updatedAt,
+			OrderedAt: orderedAt,
+			AddedAt:   addedAt,
+		})
+	}
+	return out, nil
+}
+
+func parseRemovedAt(dtos map[string]string) (map[string]time.Time, error) {
+	if len(dtos) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]time.Time, len(dtos))
+	for id, value := range dtos {
+		at, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, fmt.Errorf("parse checklist removal %s: %w", id, err)
+		}
+		out[id] = at
+	}
+	return out, nil
+}
+
+// ReminderDTO mirrors bind.ReminderDTO.
+type ReminderDTO struct {
+	ID             string `json:"id"`
+	At             string `json:"at"`
+	OffsetMinutes  int64  `json:"offset_minutes"`
+	AcknowledgedAt string `json:"acknowledged_at"`
+	SnoozedUntil   string `json:"snoozed_until"`
+}
+
+func parseReminders(dtos []ReminderDTO) ([]model.Reminder, error) {
+	if len(dtos) == 0 {
+		return nil, nil
+	}
+	out := make([]model.Reminder, 0, len(dtos))
+	for _, dto := range dtos {
+		reminder := model.Reminder{ID: dto.ID, OffsetMinutes: dto.OffsetMinutes}
+		for _, field := range []struct {
+			value  string
+			target *time.Time
+		}{
+			{dto.At, &reminder.At},
+			{dto.AcknowledgedAt, &reminder.AcknowledgedAt},
+			{dto.SnoozedUntil, &reminder.SnoozedUntil},
+		} {
+			if field.value == "" {
+				continue
+			}
+			parsed, err := time.Parse(time.RFC3339Nano, field.value)
+			if err != nil {
+				return nil, fmt.Errorf("parse reminder %s: %w", dto.ID, err)
+			}
+			*field.target = parsed
+		}
+		out = append(out, reminder)
+	}
+	return out, nil
 }
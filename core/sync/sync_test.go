@@ -22,6 +22,7 @@ func TestApplyEventLWW(t *testing.T) {
 	payload := TaskDTO{
 		ID:        "t1",
 		Title:     "New",
+		StartDate: "2026-02-09",
 		UpdatedAt: time.Date(2026, 2, 5, 11, 0, 0, 0, time.UTC).Format(time.RFC3339Nano),
 	}
 	data, _ := json.Marshal(payload)
@@ -40,6 +41,9 @@ func TestApplyEventLWW(t *testing.T) {
 	if updated.Title != "New" {
 		t.Fatalf("expected title update")
 	}
+	if !updated.StartDate.Equal(time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected start date from payload, got %s", updated.StartDate)
+	}
 }
 
 func TestApplyEventConflict(t *testing.T) {
@@ -75,3 +79,82 @@ func TestApplyEventConflict(t *testing.T) {
 		t.Fatalf("expected existing task to win")
 	}
 }
+
+func TestApplyProjectEventLWW(t *testing.T) {
+	existing := model.Project{
+		ID:        "p1",
+		Name:      "Old",
+		UpdatedAt: time.Date(2026, 2, 5, 10, 0, 0, 0, time.UTC),
+	}
+	payload := ProjectDTO{
+		ID:        "p1",
+		Name:      "Renamed",
+		UpdatedAt: time.Date(2026, 2, 5, 11, 0, 0, 0, time.UTC).Format(time.RFC3339Nano),
+	}
+	data, _ := json.Marshal(payload)
+	event := model.Event{ID: "e1", Seq: 1, Type: "project_rename", Payload: data}
+
+	updated, changed, conflict, err := ApplyProjectEvent(existing, event)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if !changed || conflict {
+		t.Fatalf("expected change without conflict")
+	}
+	if updated.Name != "Renamed" {
+		t.Fatalf("expected name update, got %q", updated.Name)
+	}
+
+	stale := ProjectDTO{
+		ID:        "p1",
+		Name:      "Stale",
+		UpdatedAt: time.Date(2026, 2, 5, 9, 0, 0, 0, time.UTC).Format(time.RFC3339Nano),
+	}
+	data, _ = json.Marshal(stale)
+	event = model.Event{ID: "e2", Seq: 2, Type: "project_rename", Payload: data}
+	kept, changed, conflict, err := ApplyProjectEvent(updated, event)
+	if err != nil {
+		t.Fatalf("apply stale: %v", err)
+	}
+	if changed || !conflict || kept.Name != "Renamed" {
+		t.Fatalf("expected existing project to win, got %+v", kept)
+	}
+}
+
+func TestApplyCommentEventAuthorOnly(t *testing.T) {
+	existing := model.Comment{
+		ID:        "c1",
+		TaskID:    "t1",
+		DeviceID:  "phone",
+		Text:      "Original",
+		UpdatedAt: time.Date(2026, 2, 5, 10, 0, 0, 0, time.UTC),
+	}
+	payload := CommentDTO{
+		ID:        "c1",
+		TaskID:    "t1",
+		DeviceID:  "phone",
+		Text:      "Hijacked",
+		UpdatedAt: time.Date(2026, 2, 5, 11, 0, 0, 0, time.UTC).Format(time.RFC3339Nano),
+	}
+	data, _ := json.Marshal(payload)
+	event := model.Event{ID: "e1", DeviceID: "laptop", Seq: 1, Type: "comment_edit", Payload: data}
+	if _, changed, _, err := ApplyCommentEvent(existing, event); err != nil || changed {
+		t.Fatalf("expected edit from another device to be ignored, changed=%v err=%v", changed, err)
+	}
+
+	payload.Deleted = true
+	data, _ = json.Marshal(payload)
+	event = model.Event{ID: "e2", DeviceID: "phone", Seq: 2, Type: "comment_delete", Payload: data}
+	deleted, changed, _, err := ApplyCommentEvent(existing, event)
+	if err != nil || !changed || !deleted.Deleted {
+		t.Fatalf("expected author delete to apply, got %+v", deleted)
+	}
+
+	payload.Deleted = false
+	payload.UpdatedAt = time.Date(2026, 2, 5, 12, 0, 0, 0, time.UTC).Format(time.RFC3339Nano)
+	data, _ = json.Marshal(payload)
+	event = model.Event{ID: "e3", DeviceID: "phone", Seq: 3, Type: "comment_edit", Payload: data}
+	if kept, changed, _, _ := ApplyCommentEvent(deleted, event); changed || !kept.Deleted {
+		t.Fatalf("expected deleted comment to stay deleted, got %+v", kept)
+	}
+}
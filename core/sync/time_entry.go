@@ -0,0 +1,68 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"taskpp/core/model"
+)
+
+// ApplyTimeEntryEvent applies a time entry event with LWW on UpdatedAt. A
+// deleted entry stays deleted.
+// Returns (updatedEntry, changed, conflict, error).
+func ApplyTimeEntryEvent(entry model.TimeEntry, event model.Event) (model.TimeEntry, bool, bool, error) {
+	var payload TimeEntryDTO
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return model.TimeEntry{}, false, false, fmt.Errorf("decode time entry payload: %w", err)
+	}
+	evtTime, err := time.Parse(time.RFC3339Nano, payload.UpdatedAt)
+	if err != nil && payload.UpdatedAt != "" {
+		return model.TimeEntry{}, false, false, fmt.Errorf("parse updated_at: %w", err)
+	}
+	if payload.UpdatedAt == "" {
+		evtTime = event.TS
+	}
+	start, err := time.Parse(time.RFC3339Nano, payload.Start)
+	if err != nil && payload.Start != "" {
+		return model.TimeEntry{}, false, false, fmt.Errorf("parse start: %w", err)
+	}
+	end, err := time.Parse(time.RFC3339Nano, payload.End)
+	if err != nil && payload.End != "" {
+		return model.TimeEntry{}, false, false, fmt.Errorf("parse end: %w", err)
+	}
+
+	if entry.Deleted {
+		return entry, false, false, nil
+	}
+	updated := model.TimeEntry{
+		ID:        payload.ID,
+		TaskID:    payload.TaskID,
+		DeviceID:  payload.DeviceID,
+		Start:     start,
+		End:       end,
+		Note:      payload.Note,
+		UpdatedAt: evtTime,
+		Deleted:   payload.Deleted,
+	}
+	if updated.Deleted {
+		return updated, true, false, nil
+	}
+	changed, conflict := resolveLWW(entry.ID != "", entry.UpdatedAt, updated.UpdatedAt, event.Seq)
+	if changed {
+		return updated, true, false, nil
+	}
+	return entry, false, conflict, nil
+}
+
+// TimeEntryDTO mirrors bind.TimeEntryDTO without imports to avoid dependency cycles.
+type TimeEntryDTO struct {
+	ID        string `json:"id"`
+	TaskID    string `json:"task_id"`
+	DeviceID  string `json:"device_id"`
+	Start     string `json:"start"`
+	End       string `json:"end"`
+	Note      string `json:"note"`
+	UpdatedAt string `json:"updated_at"`
+	Deleted   bool   `json:"deleted"`
+}
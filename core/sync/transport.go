@@ -0,0 +1,56 @@
+package sync
+
+import (
+	"time"
+
+	"taskpp/core/model"
+)
+
+// Transport moves encrypted events between devices. Payloads stay
+// encrypted end to end; a transport never needs the vault key.
+type Transport interface {
+	// Push publishes this device's own events, skipping any it already
+	// published, and returns how many it sent.
+	Push(events []model.Event) (int, error)
+	// Pull returns other devices' events past cursor, in seq order per
+	// device.
+	Pull(cursor model.Cursor) ([]model.Event, error)
+}
+
+// WireEvent is the transport encoding of an event.
+type WireEvent struct {
+	ID       string `json:"id"`
+	DeviceID string `json:"device_id"`
+	Seq      int64  `json:"seq"`
+	TS       string `json:"ts"`
+	Type     string `json:"type"`
+	Payload  []byte `json:"payload"`
+}
+
+// ToWire encodes an event for a transport.
+func ToWire(event model.Event) WireEvent {
+	return WireEvent{
+		ID:       event.ID,
+		DeviceID: event.DeviceID,
+		Seq:      event.Seq,
+		TS:       event.TS.UTC().Format(time.RFC3339Nano),
+		Type:     event.Type,
+		Payload:  event.Payload,
+	}
+}
+
+// FromWire decodes an event received from a transport.
+func FromWire(wire WireEvent) (model.Event, error) {
+	ts, err := time.Parse(time.RFC3339Nano, wire.TS)
+	if err != nil {
+		return model.Event{}, err
+	}
+	return model.Event{
+		ID:       wire.ID,
+		DeviceID: wire.DeviceID,
+		Seq:      wire.Seq,
+		TS:       ts,
+		Type:     wire.Type,
+		Payload:  wire.Payload,
+	}, nil
+}
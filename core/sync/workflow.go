@@ -0,0 +1,58 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"taskpp/core/model"
+)
+
+// ApplyWorkflowEvent applies a workflow event; the newest configuration wins.
+// Returns (updatedWorkflow, changed, conflict, error).
+func ApplyWorkflowEvent(workflow model.Workflow, event model.Event) (model.Workflow, bool, bool, error) {
+	var payload WorkflowDTO
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return model.Workflow{}, false, false, fmt.Errorf("decode workflow payload: %w", err)
+	}
+	evtTime, err := time.Parse(time.RFC3339Nano, payload.UpdatedAt)
+	if err != nil && payload.UpdatedAt != "" {
+		return model.Workflow{}, false, false, fmt.Errorf("parse updated_at: %w", err)
+	}
+	if payload.UpdatedAt == "" {
+		evtTime = event.TS
+	}
+
+	updated := model.Workflow{UpdatedAt: evtTime}
+	for _, state := range payload.States {
+		updated.States = append(updated.States, model.WorkflowState{ID: state.ID, Name: state.Name, Category: state.Category})
+	}
+	for _, transition := range payload.Transitions {
+		updated.Transitions = append(updated.Transitions, model.WorkflowTransition{From: transition.From, To: transition.To})
+	}
+	changed, conflict := resolveLWW(!workflow.UpdatedAt.IsZero(), workflow.UpdatedAt, updated.UpdatedAt, event.Seq)
+	if changed {
+		return updated, true, false, nil
+	}
+	return workflow, false, conflict, nil
+}
+
+// WorkflowDTO mirrors bind.WorkflowDTO without imports to avoid dependency cycles.
+type WorkflowDTO struct {
+	States      []WorkflowStateDTO      `json:"states"`
+	Transitions []WorkflowTransitionDTO `json:"transitions"`
+	UpdatedAt   string                  `json:"updated_at"`
+}
+
+// WorkflowStateDTO mirrors bind.WorkflowStateDTO.
+type WorkflowStateDTO struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+// WorkflowTransitionDTO mirrors bind.WorkflowTransitionDTO.
+type WorkflowTransitionDTO struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
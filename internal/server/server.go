@@ -0,0 +1,456 @@
+// Package server is the sync server: accounts, device sessions and the
+// recovery-wrapped data keys. It never sees plaintext task data.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// DefaultSessionTTL is how long a login stays valid.
+const DefaultSessionTTL = 30 * 24 * time.Hour
+
+const (
+	maxBodyBytes      = 1 << 20
+	minPasswordLength = 8
+	maxPasswordLength = 1024
+	maxUsernameLength = 64
+	maxDeviceIDLength = 64
+	maxDeviceName     = 100
+)
+
+// Options configure a Server. Zero values pick the defaults.
+type Options struct {
+	SessionTTL time.Duration
+	// Now returns the current time; tests use it to expire sessions.
+	Now func() time.Time
+}
+
+// Server serves the account API over HTTP.
+type Server struct {
+	store      *Store
+	sessionTTL time.Duration
+	now        func() time.Time
+	// dummyHash is verified against for unknown users, so a failed login
+	// takes as long whether or not the username exists.
+	dummyHash string
+	// attempts counts password checks per client address, failures failed
+	// ones per username.
+	attempts *throttle
+	failures *throttle
+}
+
+// New returns a server over store.
+func New(store *Store, opts Options) (*Server, error) {
+	if opts.SessionTTL <= 0 {
+		opts.SessionTTL = DefaultSessionTTL
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	dummy, err := HashPassword("taskpp dummy password")
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		store:      store,
+		sessionTTL: opts.SessionTTL,
+		now:        opts.Now,
+		dummyHash:  dummy,
+		attempts:   newThrottle(attemptsPerAddr, attemptWindow),
+		failures:   newThrottle(failuresPerUser, failureWindow),
+	}, nil
+}
+
+// Handler returns the HTTP routes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /auth/signup", s.handleSignup)
+	mux.HandleFunc("POST /auth/login", s.handleLogin)
+	mux.HandleFunc("POST /auth/logout", s.authenticated(s.handleLogout))
+	mux.HandleFunc("POST /auth/revoke", s.authenticated(s.handleRevoke))
+	mux.HandleFunc("PUT /auth/recovery", s.authenticated(s.handlePutRecovery))
+	mux.HandleFunc("GET /auth/recovery", s.authenticated(s.handleGetRecovery))
+	mux.HandleFunc("POST /auth/reset-password", s.handleResetPassword)
+	return mux
+}
+
+type credentialsRequest struct {
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	DeviceID   string `json:"device_id"`
+	DeviceName string `json:"device_name"`
+}
+
+type sessionResponse struct {
+	UserID     string `json:"user_id"`
+	Token      string `json:"token"`
+	ExpiresAt  string `json:"expires_at"`
+	DEKWrapped []byte `json:"dek_wrapped,omitempty"`
+}
+
+type revokeRequest struct {
+	DeviceID string `json:"device_id"`
+}
+
+type recoveryRequest struct {
+	DEKWrapped []byte `json:"dek_wrapped"`
+	Verifier   []byte `json:"verifier"`
+}
+
+type recoveryResponse struct {
+	DEKWrapped []byte `json:"dek_wrapped"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type resetRequest struct {
+	Username    string `json:"username"`
+	Verifier    []byte `json:"verifier"`
+	NewPassword string `json:"new_password"`
+	DeviceID    string `json:"device_id"`
+	DeviceName  string `json:"device_name"`
+}
+
+func (s *Server) handleSignup(w http.ResponseWriter, r *http.Request) {
+	var req credentialsRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	username, msg := normalizeUsername(req.Username)
+	if msg == "" {
+		msg = validatePassword(req.Password)
+	}
+	if msg == "" {
+		msg = validateDevice(req.DeviceID, req.DeviceName)
+	}
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	hash, err := HashPassword(req.Password)
+	if err != nil {
+		internalError(w, err)
+		return
+	}
+	user := User{ID: uuid.NewString(), Username: username, PasswordHash: hash, CreatedAt: s.now().UTC()}
+	if err := s.store.CreateUser(r.Context(), user); err != nil {
+		if errors.Is(err, ErrUsernameTaken) {
+			writeError(w, http.StatusConflict, "username taken")
+			return
+		}
+		internalError(w, err)
+		return
+	}
+	s.startSession(w, r.Context(), http.StatusCreated, user.ID, req.DeviceID, req.DeviceName, nil)
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var req credentialsRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if msg := validateDevice(req.DeviceID, req.DeviceName); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	username, _ := normalizeUsername(req.Username)
+	if !s.allowAttempt(w, r, username) {
+		return
+	}
+	user, err := s.store.UserByName(r.Context(), username)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		internalError(w, err)
+		return
+	}
+	if err != nil {
+		VerifyPassword(req.Password, s.dummyHash)
+		s.failures.add(username, s.now())
+		writeError(w, http.StatusUnauthorized, "invalid username or password")
+		return
+	}
+	if !VerifyPassword(req.Password, user.PasswordHash) {
+		s.failures.add(username, s.now())
+		writeError(w, http.StatusUnauthorized, "invalid username or password")
+		return
+	}
+	s.failures.reset(username)
+	s.startSession(w, r.Context(), http.StatusOK, user.ID, req.DeviceID, req.DeviceName, nil)
+}
+
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request, session Session) {
+	if err := s.store.RevokeSession(r.Context(), session.TokenHash, s.now().UTC()); err != nil {
+		internalError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRevoke signs a device out everywhere, e.g. a lost phone.
+func (s *Server) handleRevoke(w http.ResponseWriter, r *http.Request, session Session) {
+	var req revokeRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.DeviceID == "" {
+		writeError(w, http.StatusBadRequest, "device_id is required")
+		return
+	}
+	n, err := s.store.RevokeSessions(r.Context(), session.UserID, req.DeviceID, s.now().UTC())
+	if err != nil {
+		internalError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"revoked": n})
+}
+
+func (s *Server) handlePutRecovery(w http.ResponseWriter, r *http.Request, session Session) {
+	var req recoveryRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if len(req.DEKWrapped) == 0 || len(req.Verifier) < 16 {
+		writeError(w, http.StatusBadRequest, "dek_wrapped and verifier are required")
+		return
+	}
+	verifierHash, err := HashPassword(string(req.Verifier))
+	if err != nil {
+		internalError(w, err)
+		return
+	}
+	recovery := Recovery{
+		UserID:       session.UserID,
+		DEKWrapped:   req.DEKWrapped,
+		VerifierHash: verifierHash,
+		CreatedAt:    s.now().UTC(),
+	}
+	if err := s.store.SaveRecovery(r.Context(), recovery); err != nil {
+		internalError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleGetRecovery(w http.ResponseWriter, r *http.Request, session Session) {
+	recovery, err := s.store.GetRecovery(r.Context(), session.UserID)
+	if errors.Is(err, ErrNotFound) {
+		writeError(w, http.StatusNotFound, "no recovery key")
+		return
+	}
+	if err != nil {
+		internalError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, recoveryResponse{DEKWrapped: recovery.DEKWrapped, CreatedAt: formatTime(recovery.CreatedAt)})
+}
+
+// handleResetPassword sets a new password for a user who proves the
+// recovery key, signs out every device and returns the wrapped data key.
+func (s *Server) handleResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req resetRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	msg := validatePassword(req.NewPassword)
+	if msg == "" {
+		msg = validateDevice(req.DeviceID, req.DeviceName)
+	}
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	username, _ := normalizeUsername(req.Username)
+	if !s.allowAttempt(w, r, username) {
+		return
+	}
+	user, err := s.store.UserByName(r.Context(), username)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		internalError(w, err)
+		return
+	}
+	recovery := Recovery{VerifierHash: s.dummyHash}
+	if err == nil {
+		recovery, err = s.store.GetRecovery(r.Context(), user.ID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			internalError(w, err)
+			return
+		}
+		if err != nil {
+			recovery = Recovery{VerifierHash: s.dummyHash}
+		}
+	}
+	if !VerifyPassword(string(req.Verifier), recovery.VerifierHash) || recovery.UserID == "" {
+		s.failures.add(username, s.now())
+		writeError(w, http.StatusUnauthorized, "invalid username or recovery key")
+		return
+	}
+	s.failures.reset(username)
+	hash, err := HashPassword(req.NewPassword)
+	if err != nil {
+		internalError(w, err)
+		return
+	}
+	if err := s.store.SetPassword(r.Context(), user.ID, hash); err != nil {
+		internalError(w, err)
+		return
+	}
+	if _, err := s.store.RevokeSessions(r.Context(), user.ID, "", s.now().UTC()); err != nil {
+		internalError(w, err)
+		return
+	}
+	s.startSession(w, r.Context(), http.StatusOK, user.ID, req.DeviceID, req.DeviceName, recovery.DEKWrapped)
+}
+
+// allowAttempt counts a password check from the client and answers 429
+// once the client or the username is out of attempts.
+func (s *Server) allowAttempt(w http.ResponseWriter, r *http.Request, username string) bool {
+	now := s.now()
+	if !s.attempts.add(clientAddr(r), now) || !s.failures.allowed(username, now) {
+		writeError(w, http.StatusTooManyRequests, throttledResponse)
+		return false
+	}
+	return true
+}
+
+// startSession issues a token for a device and writes it.
+func (s *Server) startSession(w http.ResponseWriter, ctx context.Context, status int, userID, deviceID, deviceName string, dekWrapped []byte) {
+	now := s.now().UTC()
+	if err := s.store.UpsertDevice(ctx, Device{ID: deviceID, UserID: userID, Name: deviceName, CreatedAt: now}); err != nil {
+		internalError(w, err)
+		return
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		internalError(w, err)
+		return
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	session := Session{
+		TokenHash: hashToken(token),
+		UserID:    userID,
+		DeviceID:  deviceID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.sessionTTL),
+	}
+	if err := s.store.CreateSession(ctx, session); err != nil {
+		internalError(w, err)
+		return
+	}
+	writeJSON(w, status, sessionResponse{
+		UserID:     userID,
+		Token:      token,
+		ExpiresAt:  formatTime(session.ExpiresAt),
+		DEKWrapped: dekWrapped,
+	})
+}
+
+// authenticated requires a live session token in the Authorization header.
+// The token is a bearer credential: X-Device-ID must name the device it was
+// issued to, which catches clients mixing up sessions, but proves nothing
+// about the caller.
+func (s *Server) authenticated(next func(http.ResponseWriter, *http.Request, Session)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			writeError(w, http.StatusUnauthorized, "missing token")
+			return
+		}
+		session, err := s.store.SessionByTokenHash(r.Context(), hashToken(token))
+		if errors.Is(err, ErrNotFound) {
+			writeError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		if err != nil {
+			internalError(w, err)
+			return
+		}
+		switch {
+		case !session.RevokedAt.IsZero():
+			writeError(w, http.StatusUnauthorized, "session revoked")
+		case !s.now().Before(session.ExpiresAt):
+			writeError(w, http.StatusUnauthorized, "session expired")
+		case r.Header.Get("X-Device-ID") != session.DeviceID:
+			writeError(w, http.StatusUnauthorized, "token issued to another device")
+		default:
+			next(w, r, session)
+		}
+	}
+}
+
+// hashToken is what the store keeps of a token, so a leaked database does
+// not hand out live sessions.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeUsername(username string) (string, string) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	if username == "" {
+		return "", "username is required"
+	}
+	if len(username) > maxUsernameLength || strings.ContainsAny(username, " \t\r\n") {
+		return "", "invalid username"
+	}
+	return username, ""
+}
+
+func validatePassword(password string) string {
+	if utf8.RuneCountInString(password) < minPasswordLength {
+		return "password must be at least 8 characters"
+	}
+	if len(password) > maxPasswordLength {
+		return "password too long"
+	}
+	return ""
+}
+
+func validateDevice(deviceID, name string) string {
+	if deviceID == "" || len(deviceID) > maxDeviceIDLength {
+		return "invalid device_id"
+	}
+	if len(name) > maxDeviceName {
+		return "device_name too long"
+	}
+	return ""
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err := decoder.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return false
+	}
+	return true
+}
+
+// internalError logs err and answers without details.
+func internalError(w http.ResponseWriter, err error) {
+	log.Printf("syncserver: %v", err)
+	writeError(w, http.StatusInternalServerError, "internal error")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
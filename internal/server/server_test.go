@@ -0,0 +1,224 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testServer struct {
+	t   *testing.T
+	url string
+	now time.Time
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	store, err := OpenStore(context.Background(), "file:"+filepath.Join(t.TempDir(), "server.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	ts := &testServer{t: t, now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	srv, err := New(store, Options{SessionTTL: time.Hour, Now: func() time.Time { return ts.now }})
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	httpServer := httptest.NewServer(srv.Handler())
+	t.Cleanup(httpServer.Close)
+	ts.url = httpServer.URL
+	return ts
+}
+
+// call sends body as JSON and decodes the reply into out, returning the
+// status code.
+func (ts *testServer) call(method, path, token, deviceID string, body, out any) int {
+	ts.t.Helper()
+	data, _ := json.Marshal(body)
+	req, err := http.NewRequest(method, ts.url+path, bytes.NewReader(data))
+	if err != nil {
+		ts.t.Fatalf("request: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("X-Device-ID", deviceID)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		ts.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		_ = json.NewDecoder(resp.Body).Decode(out)
+	}
+	return resp.StatusCode
+}
+
+func (ts *testServer) login(path, username, password, deviceID string) (sessionResponse, int) {
+	ts.t.Helper()
+	var session sessionResponse
+	status := ts.call(http.MethodPost, path, "", deviceID, credentialsRequest{
+		Username: username, Password: password, DeviceID: deviceID, DeviceName: deviceID,
+	}, &session)
+	return session, status
+}
+
+func TestSignupLoginAndSessions(t *testing.T) {
+	ts := newTestServer(t)
+	signup, status := ts.login("/auth/signup", " Alice ", "correct horse", "laptop")
+	if status != http.StatusCreated || signup.Token == "" || signup.UserID == "" {
+		t.Fatalf("signup: %d %+v", status, signup)
+	}
+	if _, status := ts.login("/auth/signup", "alice", "another password", "phone"); status != http.StatusConflict {
+		t.Fatalf("expected a taken username to conflict, got %d", status)
+	}
+	if _, status := ts.login("/auth/signup", "bob", "short", "phone"); status != http.StatusBadRequest {
+		t.Fatalf("expected a short password to fail, got %d", status)
+	}
+	if _, status := ts.login("/auth/login", "alice", "wrong password", "phone"); status != http.StatusUnauthorized {
+		t.Fatalf("expected a wrong password to fail, got %d", status)
+	}
+	if _, status := ts.login("/auth/login", "nobody", "correct horse", "phone"); status != http.StatusUnauthorized {
+		t.Fatalf("expected an unknown user to fail, got %d", status)
+	}
+	phone, status := ts.login("/auth/login", "ALICE", "correct horse", "phone")
+	if status != http.StatusOK || phone.UserID != signup.UserID {
+		t.Fatalf("login: %d %+v", status, phone)
+	}
+
+	recovery := recoveryRequest{DEKWrapped: []byte("wrapped"), Verifier: bytes.Repeat([]byte{1}, 32)}
+	if status := ts.call(http.MethodPut, "/auth/recovery", phone.Token, "laptop", recovery, nil); status != http.StatusUnauthorized {
+		t.Fatalf("expected a token used by another device to fail, got %d", status)
+	}
+	if status := ts.call(http.MethodPut, "/auth/recovery", phone.Token, "phone", recovery, nil); status != http.StatusNoContent {
+		t.Fatalf("put recovery: %d", status)
+	}
+
+	var revoked map[string]int
+	if status := ts.call(http.MethodPost, "/auth/revoke", signup.Token, "laptop", revokeRequest{DeviceID: "phone"}, &revoked); status != http.StatusOK || revoked["revoked"] != 1 {
+		t.Fatalf("revoke: %d %v", status, revoked)
+	}
+	if status := ts.call(http.MethodGet, "/auth/recovery", phone.Token, "phone", nil, nil); status != http.StatusUnauthorized {
+		t.Fatalf("expected a revoked token to fail, got %d", status)
+	}
+	if status := ts.call(http.MethodPost, "/auth/logout", signup.Token, "laptop", nil, nil); status != http.StatusNoContent {
+		t.Fatalf("logout: %d", status)
+	}
+	if status := ts.call(http.MethodGet, "/auth/recovery", signup.Token, "laptop", nil, nil); status != http.StatusUnauthorized {
+		t.Fatalf("expected a logged out token to fail, got %d", status)
+	}
+
+	fresh, _ := ts.login("/auth/login", "alice", "correct horse", "laptop")
+	ts.now = ts.now.Add(2 * time.Hour)
+	var failure map[string]string
+	if status := ts.call(http.MethodGet, "/auth/recovery", fresh.Token, "laptop", nil, &failure); status != http.StatusUnauthorized || failure["error"] != "session expired" {
+		t.Fatalf("expected an expired token to fail, got %d %v", status, failure)
+	}
+}
+
+func TestResetPasswordWithRecoveryVerifier(t *testing.T) {
+	ts := newTestServer(t)
+	signup, _ := ts.login("/auth/signup", "alice", "correct horse", "laptop")
+	verifier := bytes.Repeat([]byte{7}, 32)
+	recovery := recoveryRequest{DEKWrapped: []byte("wrapped"), Verifier: verifier}
+	if status := ts.call(http.MethodPut, "/auth/recovery", signup.Token, "laptop", recovery, nil); status != http.StatusNoContent {
+		t.Fatalf("put recovery: %d", status)
+	}
+
+	reset := resetRequest{Username: "alice", Verifier: bytes.Repeat([]byte{8}, 32), NewPassword: "battery staple", DeviceID: "phone"}
+	if status := ts.call(http.MethodPost, "/auth/reset-password", "", "phone", reset, nil); status != http.StatusUnauthorized {
+		t.Fatalf("expected a wrong verifier to fail, got %d", status)
+	}
+	reset.Verifier = verifier
+	var session sessionResponse
+	if status := ts.call(http.MethodPost, "/auth/reset-password", "", "phone", reset, &session); status != http.StatusOK {
+		t.Fatalf("reset: %d", status)
+	}
+	if string(session.DEKWrapped) != "wrapped" || session.Token == "" {
+		t.Fatalf("unexpected reset session: %+v", session)
+	}
+	if status := ts.call(http.MethodGet, "/auth/recovery", signup.Token, "laptop", nil, nil); status != http.StatusUnauthorized {
+		t.Fatalf("expected a reset to sign out other sessions, got %d", status)
+	}
+	if _, status := ts.login("/auth/login", "alice", "correct horse", "laptop"); status != http.StatusUnauthorized {
+		t.Fatalf("expected the old password to stop working, got %d", status)
+	}
+	if _, status := ts.login("/auth/login", "alice", "battery staple", "laptop"); status != http.StatusOK {
+		t.Fatalf("expected the new password to work, got %d", status)
+	}
+}
+
+func TestPasswordHashRoundTrip(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	other, _ := HashPassword("correct horse")
+	if hash == other {
+		t.Fatalf("expected salted hashes to differ")
+	}
+	if !VerifyPassword("correct horse", hash) || VerifyPassword("correct horsE", hash) || VerifyPassword("correct horse", "plain") {
+		t.Fatalf("unexpected verification result")
+	}
+}
+
+func TestConcurrentSignupsConflict(t *testing.T) {
+	store, err := OpenStore(context.Background(), "file:"+filepath.Join(t.TempDir(), "server.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer store.Close()
+	const n = 8
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			user := User{ID: fmt.Sprintf("user-%d", i), Username: "alice", PasswordHash: "hash", CreatedAt: time.Now()}
+			errs <- store.CreateUser(context.Background(), user)
+		}(i)
+	}
+	created := 0
+	for i := 0; i < n; i++ {
+		switch err := <-errs; {
+		case err == nil:
+			created++
+		case !errors.Is(err, ErrUsernameTaken):
+			t.Fatalf("expected ErrUsernameTaken, got %v", err)
+		}
+	}
+	if created != 1 {
+		t.Fatalf("expected exactly one signup to win, got %d", created)
+	}
+}
+
+func TestLoginThrottling(t *testing.T) {
+	ts := newTestServer(t)
+	ts.login("/auth/signup", "alice", "correct horse", "laptop")
+	for i := 0; i < failuresPerUser; i++ {
+		if _, status := ts.login("/auth/login", "alice", "wrong password", "phone"); status != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected a wrong password to fail, got %d", i, status)
+		}
+	}
+	if _, status := ts.login("/auth/login", "alice", "correct horse", "phone"); status != http.StatusTooManyRequests {
+		t.Fatalf("expected the user to be throttled, got %d", status)
+	}
+	ts.now = ts.now.Add(failureWindow)
+	if _, status := ts.login("/auth/login", "alice", "correct horse", "phone"); status != http.StatusOK {
+		t.Fatalf("expected the throttle to expire, got %d", status)
+	}
+
+	// One address only gets so many attempts, whatever the usernames.
+	ts.now = ts.now.Add(attemptWindow)
+	for i := 0; i < attemptsPerAddr; i++ {
+		ts.login("/auth/login", fmt.Sprintf("user%d", i), "wrong password", "phone")
+	}
+	if _, status := ts.login("/auth/login", "alice", "correct horse", "phone"); status != http.StatusTooManyRequests {
+		t.Fatalf("expected the address to be throttled, got %d", status)
+	}
+}
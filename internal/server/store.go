@@ -0,0 +1,252 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// ErrNotFound is returned when a row does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrUsernameTaken is returned when signing up with an existing username.
+var ErrUsernameTaken = errors.New("username taken")
+
+// User is an account on the sync server.
+type User struct {
+	ID           string
+	Username     string
+	PasswordHash string
+	CreatedAt    time.Time
+}
+
+// Device is a client device that logged in to an account.
+type Device struct {
+	ID        string
+	UserID    string
+	Name      string
+	CreatedAt time.Time
+}
+
+// Session is a login of one device. Only the hash of its token is stored.
+type Session struct {
+	TokenHash string
+	UserID    string
+	DeviceID  string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RevokedAt time.Time
+}
+
+// Recovery is the data key wrapped with the user's recovery key, plus the
+// hash of a verifier derived from the same key that authorizes a password
+// reset.
+type Recovery struct {
+	UserID       string
+	DEKWrapped   []byte
+	VerifierHash string
+	CreatedAt    time.Time
+}
+
+// Store keeps accounts, sessions and recovery data in SQLite.
+type Store struct {
+	db *sql.DB
+}
+
+// OpenStore opens the database at dsn and creates missing tables.
+func OpenStore(ctx context.Context, dsn string) (*Store, error) {
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	store := &Store{db: db}
+	if err := store.migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) migrate(ctx context.Context) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS users (
+			id TEXT PRIMARY KEY,
+			username TEXT NOT NULL UNIQUE,
+			password_hash TEXT NOT NULL,
+			created_at INTEGER NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS devices (
+			id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			created_at INTEGER NOT NULL,
+			PRIMARY KEY (user_id, id)
+		);`,
+		`CREATE TABLE IF NOT EXISTS sessions (
+			token_hash TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			device_id TEXT NOT NULL,
+			created_at INTEGER NOT NULL,
+			expires_at INTEGER NOT NULL,
+			revoked_at INTEGER NOT NULL DEFAULT 0
+		);`,
+		`CREATE INDEX IF NOT EXISTS sessions_user_device ON sessions (user_id, device_id);`,
+		`CREATE TABLE IF NOT EXISTS recovery_wrapped_dek (
+			user_id TEXT PRIMARY KEY,
+			dek_wrapped BLOB NOT NULL,
+			verifier_hash TEXT NOT NULL,
+			created_at INTEGER NOT NULL
+		);`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("migrate: %w", err)
+		}
+	}
+	return nil
+}
+
+// CreateUser inserts a new user, or returns ErrUsernameTaken. The username
+// check is part of the insert, so concurrent signups cannot both pass it.
+func (s *Store) CreateUser(ctx context.Context, user User) error {
+	stmt := `INSERT INTO users (id, username, password_hash, created_at) VALUES (?, ?, ?, ?)
+	ON CONFLICT(username) DO NOTHING`
+	result, err := s.db.ExecContext(ctx, stmt, user.ID, user.Username, user.PasswordHash, user.CreatedAt.UnixNano())
+	if err != nil {
+		return fmt.Errorf("insert user: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("insert user: %w", err)
+	}
+	if n == 0 {
+		return ErrUsernameTaken
+	}
+	return nil
+}
+
+// UserByName returns the user with username, or ErrNotFound.
+func (s *Store) UserByName(ctx context.Context, username string) (User, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT id, username, password_hash, created_at FROM users WHERE username = ?`, username)
+	var user User
+	var createdAt int64
+	if err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &createdAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return User{}, ErrNotFound
+		}
+		return User{}, fmt.Errorf("get user: %w", err)
+	}
+	user.CreatedAt = time.Unix(0, createdAt).UTC()
+	return user, nil
+}
+
+// SetPassword replaces a user's password hash.
+func (s *Store) SetPassword(ctx context.Context, userID, passwordHash string) error {
+	if _, err := s.db.ExecContext(ctx, `UPDATE users SET password_hash = ? WHERE id = ?`, passwordHash, userID); err != nil {
+		return fmt.Errorf("set password: %w", err)
+	}
+	return nil
+}
+
+// UpsertDevice records a device of a user; the name follows the latest login.
+func (s *Store) UpsertDevice(ctx context.Context, device Device) error {
+	stmt := `INSERT INTO devices (id, user_id, name, created_at) VALUES (?, ?, ?, ?)
+	ON CONFLICT(user_id, id) DO UPDATE SET name = excluded.name`
+	if _, err := s.db.ExecContext(ctx, stmt, device.ID, device.UserID, device.Name, device.CreatedAt.UnixNano()); err != nil {
+		return fmt.Errorf("upsert device: %w", err)
+	}
+	return nil
+}
+
+// CreateSession inserts a session.
+func (s *Store) CreateSession(ctx context.Context, session Session) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO sessions (token_hash, user_id, device_id, created_at, expires_at) VALUES (?, ?, ?, ?, ?)`,
+		session.TokenHash, session.UserID, session.DeviceID, session.CreatedAt.UnixNano(), session.ExpiresAt.UnixNano())
+	if err != nil {
+		return fmt.Errorf("insert session: %w", err)
+	}
+	return nil
+}
+
+// SessionByTokenHash returns a session, or ErrNotFound.
+func (s *Store) SessionByTokenHash(ctx context.Context, tokenHash string) (Session, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT token_hash, user_id, device_id, created_at, expires_at, revoked_at FROM sessions WHERE token_hash = ?`, tokenHash)
+	var session Session
+	var createdAt, expiresAt, revokedAt int64
+	if err := row.Scan(&session.TokenHash, &session.UserID, &session.DeviceID, &createdAt, &expiresAt, &revokedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Session{}, ErrNotFound
+		}
+		return Session{}, fmt.Errorf("get session: %w", err)
+	}
+	session.CreatedAt = time.Unix(0, createdAt).UTC()
+	session.ExpiresAt = time.Unix(0, expiresAt).UTC()
+	if revokedAt != 0 {
+		session.RevokedAt = time.Unix(0, revokedAt).UTC()
+	}
+	return session, nil
+}
+
+// RevokeSession revokes one session.
+func (s *Store) RevokeSession(ctx context.Context, tokenHash string, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE sessions SET revoked_at = ? WHERE token_hash = ? AND revoked_at = 0`, at.UnixNano(), tokenHash)
+	if err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+	return nil
+}
+
+// RevokeSessions revokes the sessions of a user, only those of deviceID
+// unless it is empty. It returns how many were revoked.
+func (s *Store) RevokeSessions(ctx context.Context, userID, deviceID string, at time.Time) (int, error) {
+	stmt := `UPDATE sessions SET revoked_at = ? WHERE user_id = ? AND revoked_at = 0`
+	args := []any{at.UnixNano(), userID}
+	if deviceID != "" {
+		stmt += ` AND device_id = ?`
+		args = append(args, deviceID)
+	}
+	result, err := s.db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return 0, fmt.Errorf("revoke sessions: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("revoke sessions: %w", err)
+	}
+	return int(n), nil
+}
+
+// SaveRecovery stores or replaces a user's recovery data.
+func (s *Store) SaveRecovery(ctx context.Context, recovery Recovery) error {
+	stmt := `INSERT INTO recovery_wrapped_dek (user_id, dek_wrapped, verifier_hash, created_at) VALUES (?, ?, ?, ?)
+	ON CONFLICT(user_id) DO UPDATE SET dek_wrapped = excluded.dek_wrapped, verifier_hash = excluded.verifier_hash, created_at = excluded.created_at`
+	if _, err := s.db.ExecContext(ctx, stmt, recovery.UserID, recovery.DEKWrapped, recovery.VerifierHash, recovery.CreatedAt.UnixNano()); err != nil {
+		return fmt.Errorf("save recovery: %w", err)
+	}
+	return nil
+}
+
+// GetRecovery returns a user's recovery data, or ErrNotFound.
+func (s *Store) GetRecovery(ctx context.Context, userID string) (Recovery, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT user_id, dek_wrapped, verifier_hash, created_at FROM recovery_wrapped_dek WHERE user_id = ?`, userID)
+	var recovery Recovery
+	var createdAt int64
+	if err := row.Scan(&recovery.UserID, &recovery.DEKWrapped, &recovery.VerifierHash, &createdAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Recovery{}, ErrNotFound
+		}
+		return Recovery{}, fmt.Errorf("get recovery: %w", err)
+	}
+	recovery.CreatedAt = time.Unix(0, createdAt).UTC()
+	return recovery, nil
+}
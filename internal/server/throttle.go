@@ -0,0 +1,87 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Password checks cost a full scrypt each, so logins and password resets
+// are throttled before hashing: every client address gets a budget of
+// attempts, and every username a budget of failures.
+const (
+	attemptsPerAddr   = 20
+	attemptWindow     = time.Minute
+	failuresPerUser   = 10
+	failureWindow     = 15 * time.Minute
+	maxThrottledKeys  = 100000
+	throttledResponse = "too many attempts, try again later"
+)
+
+// throttle counts events per key in fixed windows.
+type throttle struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	counts map[string]throttleCount
+}
+
+type throttleCount struct {
+	n     int
+	start time.Time
+}
+
+func newThrottle(limit int, window time.Duration) *throttle {
+	return &throttle{limit: limit, window: window, counts: make(map[string]throttleCount)}
+}
+
+// allowed reports whether key is still under its limit.
+func (t *throttle) allowed(key string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	count, ok := t.counts[key]
+	return !ok || now.Sub(count.start) >= t.window || count.n < t.limit
+}
+
+// add counts one event for key and reports whether it was under the limit.
+func (t *throttle) add(key string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	count, ok := t.counts[key]
+	if !ok || now.Sub(count.start) >= t.window {
+		if len(t.counts) >= maxThrottledKeys {
+			t.sweep(now)
+		}
+		count = throttleCount{start: now}
+	}
+	count.n++
+	t.counts[key] = count
+	return count.n <= t.limit
+}
+
+// reset forgets key, e.g. once its user logged in.
+func (t *throttle) reset(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.counts, key)
+}
+
+// sweep drops expired windows; callers hold t.mu.
+func (t *throttle) sweep(now time.Time) {
+	for key, count := range t.counts {
+		if now.Sub(count.start) >= t.window {
+			delete(t.counts, key)
+		}
+	}
+}
+
+// clientAddr is the host of the connection; proxies in front of the server
+// make every client share the proxy's budget.
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}